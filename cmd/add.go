@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"gee/pkg/gitdir"
 	"gee/pkg/util"
 
 	"github.com/charmbracelet/huh"
@@ -116,7 +117,7 @@ func addAllSelect(dir string, cache *util.RepoCache) error {
 				Options(options...).
 				Value(&selected),
 		),
-	)
+	).WithTheme(formTheme())
 
 	if err := form.Run(); err != nil {
 		return err
@@ -138,7 +139,7 @@ func addAllSelect(dir string, cache *util.RepoCache) error {
 	return nil
 }
 
-// discoverChildRepos finds immediate child directories that contain .git.
+// discoverChildRepos finds immediate child directories that are git repos.
 func discoverChildRepos(dir string) []string {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
@@ -157,10 +158,11 @@ func discoverChildRepos(dir string) []string {
 	return repos
 }
 
-// isGitRepo checks if a directory contains a .git subdirectory.
+// isGitRepo checks if a directory is a repo root: a .git directory, a .git
+// gitdir file (worktree or submodule), or a bare repo.
 func isGitRepo(dir string) bool {
-	info, err := os.Stat(filepath.Join(dir, ".git"))
-	return err == nil && info.IsDir()
+	_, ok := gitdir.Resolve(dir)
+	return ok
 }
 
 // pinRepo adds a repo to the cache as pinned, detecting its remote URL.
@@ -171,12 +173,14 @@ func pinRepo(repoPath string, cache *util.RepoCache) {
 		remote = strings.TrimSpace(string(out))
 	}
 
+	info, _ := gitdir.Resolve(repoPath)
 	name := filepath.Base(repoPath)
 	cache.Add(util.CachedRepo{
 		Name:         name,
 		Path:         repoPath,
 		Remote:       remote,
 		Pinned:       true,
+		Kind:         info.Kind,
 		DiscoveredAt: time.Now(),
 	})
 	cache.Pin(repoPath)
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"gee/pkg/util"
+
+	"github.com/urfave/cli/v2"
+)
+
+func CacheCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "cache",
+		Usage: "Inspect and restore cache.json backups",
+		Subcommands: []*cli.Command{
+			{
+				Name:  "backups",
+				Usage: "List the rotating cache.json.bak.N backups",
+				Action: func(c *cli.Context) error {
+					cache := util.NewRepoCache()
+					backups := cache.Backups()
+					if len(backups) == 0 {
+						fmt.Println("No backups yet. One is kept each time the cache changes.")
+						return nil
+					}
+					for _, b := range backups {
+						if b.Err != nil {
+							fmt.Printf("%d  %s  unreadable: %s\n", b.N, b.ModTime.Format("2006-01-02 15:04:05"), b.Err)
+							continue
+						}
+						fmt.Printf("%d  %s  %d repos\n", b.N, b.ModTime.Format("2006-01-02 15:04:05"), b.Repos)
+					}
+					return nil
+				},
+			},
+			{
+				Name:      "restore",
+				Usage:     "Replace cache.json with a backup (default: the newest)",
+				ArgsUsage: "[N]",
+				Action: func(c *cli.Context) error {
+					n := 1
+					if c.Args().Len() > 0 {
+						var err error
+						n, err = strconv.Atoi(c.Args().First())
+						if err != nil {
+							return util.NewWarning(fmt.Sprintf("invalid backup number %q", c.Args().First()))
+						}
+					}
+
+					cache := util.NewRepoCache()
+					if err := cache.RestoreBackup(n); err != nil {
+						return err
+					}
+					return util.NewInfo(fmt.Sprintf("restored cache.json from backup %d (%d repos)", n, len(cache.All())))
+				},
+			},
+		},
+	}
+}
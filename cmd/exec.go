@@ -27,13 +27,13 @@ func ExecCmd() *cli.Command {
 				Name:  "all",
 				Usage: "Target all cached repos, not just pinned",
 			},
+			filterFlag(),
 		},
 		Action: func(c *cli.Context) error {
 			if c.Args().Len() == 0 {
 				return util.NewWarning("no command provided. usage: gee exec <command>")
 			}
 
-			startTime := time.Now()
 			userCmd := strings.Join(c.Args().Slice(), " ")
 
 			cache := util.NewRepoCache()
@@ -41,93 +41,111 @@ func ExecCmd() *cli.Command {
 				return err
 			}
 
-			cwd, err := os.Getwd()
+			cached, err := targetRepos(c, cache, false)
 			if err != nil {
 				return err
 			}
-
-			cached := cache.LoadReposForCLI(cwd, c.Bool("all"))
 			if len(cached) == 0 {
+				if c.String("filter") != "" {
+					return util.NewInfo("no repos match the filter")
+				}
 				fmt.Println("No repos found. Run gee add in a git repo to pin it.")
 				return nil
 			}
 
-			repos := util.ToRepoSlice(cached)
-			git := command.GitRepoOperation{}
-			repoUtils := util.NewRepoUtils(git)
-
-			states := make([]*ui.SpinnerState, len(repos))
-			results := make([]*execResult, len(repos))
-
-			for i, repo := range repos {
-				states[i] = &ui.SpinnerState{
-					State: ui.StateLoading,
-					Msg:   fmt.Sprintf("Running in %s", repo.Name),
-				}
-			}
-
-			finishPrint := ui.PrintSpinnerStates(os.Stdout, states)
-
-			concurrency := len(repos)
-			pool := gogo.NewPool[struct{}](c.Context, concurrency, len(repos), func(ctx context.Context, i int) (struct{}, error) {
-				repo := repos[i]
-				fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
-
-				var stdout, stderr bytes.Buffer
-				sh := exec.Command("sh", "-c", userCmd)
-				sh.Dir = fullPath
-				sh.Stdout = &stdout
-				sh.Stderr = &stderr
-
-				err := sh.Run()
-				failed := err != nil
+			execRepos(c.Context, cached, cache.DisplayNames(), userCmd)
+			return nil
+		},
+	}
+}
 
-				results[i] = &execResult{
-					Repo:   repo.Name,
-					Stdout: stdout.String(),
-					Stderr: stderr.String(),
-					Failed: failed,
-				}
+// execRepos runs userCmd through `sh -c` in every repo concurrently, renders
+// the results and records the batch in the history log.
+func execRepos(ctx context.Context, cached []util.CachedRepo, names map[string]string, userCmd string) {
+	startTime := time.Now()
 
-				if failed {
-					states[i].State = ui.StateError
-					states[i].Msg = fmt.Sprintf("failed in %s", repo.Name)
-				} else {
-					states[i].State = ui.StateSuccess
-					states[i].Msg = fmt.Sprintf("finished in %s", repo.Name)
-				}
+	repos := util.ToRepoSlice(cached)
+	git := command.GitRepoOperation{}
+	repoUtils := util.NewRepoUtils(git)
 
-				return struct{}{}, nil
-			})
+	states := make([]*ui.SpinnerState, len(repos))
+	results := make([]*execResult, len(repos))
 
-			for res := range pool.Go() {
-				if res.Error == nil {
-					continue
-				}
-				util.Warning("%s", res.Error)
-			}
+	for i := range repos {
+		states[i] = &ui.SpinnerState{
+			State: ui.StateLoading,
+			Msg:   fmt.Sprintf("Running in %s", util.DisplayName(names, cached[i].Path)),
+		}
+	}
 
-			finishPrint()
-			fmt.Println()
+	finishPrint := ui.PrintSpinnerStates(os.Stdout, states)
+
+	concurrency := len(repos)
+	pool := gogo.NewPool[struct{}](ctx, concurrency, len(repos), func(ctx context.Context, i int) (struct{}, error) {
+		repo := repos[i]
+		fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
+		repoStart := time.Now()
+
+		var stdout, stderr bytes.Buffer
+		sh := exec.Command("sh", "-c", userCmd)
+		sh.Dir = fullPath
+		sh.Stdout = &stdout
+		sh.Stderr = &stderr
+
+		err := sh.Run()
+		failed := err != nil
+
+		results[i] = &execResult{
+			Repo:     util.DisplayName(names, cached[i].Path),
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			Failed:   failed,
+			Duration: time.Since(repoStart),
+		}
+
+		if failed {
+			states[i].State = ui.StateError
+			states[i].Msg = fmt.Sprintf("failed in %s", results[i].Repo)
+		} else {
+			states[i].State = ui.StateSuccess
+			states[i].Msg = fmt.Sprintf("finished in %s", results[i].Repo)
+		}
+
+		return struct{}{}, nil
+	})
+
+	for res := range pool.Go() {
+		if res.Error == nil {
+			continue
+		}
+		util.Warning("%s", res.Error)
+	}
 
-			repoResults := make([]ui.RepoResult, len(results))
-			for i, r := range results {
-				repoResults[i] = ui.RepoResult{
-					Name:   r.Repo,
-					Stdout: r.Stdout,
-					Stderr: r.Stderr,
-					Failed: r.Failed,
-				}
-			}
-			ui.RenderResults(fmt.Sprintf("$ %s", userCmd), repoResults, startTime)
-			return nil
-		},
+	finishPrint()
+	fmt.Println()
+
+	repoResults := make([]ui.RepoResult, len(results))
+	history := util.HistoryEntry{Command: "exec", Args: userCmd, Source: "cli", StartedAt: startTime}
+	for i, r := range results {
+		repoResults[i] = ui.RepoResult{
+			Name:   r.Repo,
+			Stdout: r.Stdout,
+			Stderr: r.Stderr,
+			Failed: r.Failed,
+		}
+		history.Results = append(history.Results, util.NewHistoryResult(
+			r.Repo, cached[i].Path, r.Failed, r.Duration, r.Stdout, r.Stderr))
 	}
+	ui.RenderResults(fmt.Sprintf("$ %s", userCmd), repoResults, startTime)
+
+	history.Duration = time.Since(startTime)
+	recordHistory(history)
 }
 
 type execResult struct {
-	Repo   string
-	Stdout string
-	Stderr string
-	Failed bool
+	Repo     string
+	Stdout   string
+	Stderr   string
+	Failed   bool
+	Duration time.Duration
 }
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"gee/pkg/gitdir"
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"github.com/stcrestrada/gogo/v3"
+	"github.com/urfave/cli/v2"
+)
+
+// filterFlag selects repos with the same expressions as the dashboard's /
+// filter.
+func filterFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:    "filter",
+		Aliases: []string{"f"},
+		Usage:   `select repos with a filter expression, e.g. "is:dirty tag:work -branch:main" (from pinned repos, or all with --all)`,
+	}
+}
+
+// targetRepos returns the repos a command runs on. Without --filter that is
+// cache.LoadReposForCLI; with it, the pinned repos (every repo not hidden
+// with --all) that match, wherever the command runs from. Status qualifiers look
+// at a fresh git status, or with useSnapshots at the last recorded one.
+func targetRepos(c *cli.Context, cache *util.RepoCache, useSnapshots bool) ([]util.CachedRepo, error) {
+	all := c.Bool("all")
+	expr := c.String("filter")
+	if expr == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return cache.LoadReposForCLI(cwd, all), nil
+	}
+
+	filter, err := util.ParseFilter(expr)
+	if err != nil {
+		return nil, util.NewWarning(fmt.Sprintf("invalid --filter: %s", err))
+	}
+	candidates := cache.Pinned()
+	if all {
+		candidates = cache.Shown()
+	}
+
+	subjects := make([]util.FilterRepo, len(candidates))
+	names := cache.DisplayNames()
+	for i, r := range candidates {
+		subjects[i] = util.FilterRepo{
+			Name:    util.DisplayName(names, r.Path),
+			Path:    r.Path,
+			Remote:  r.Remote,
+			Pinned:  r.Pinned,
+			Tags:    r.Tags,
+			Missing: r.Missing,
+		}
+	}
+	if filter.NeedsStatus() {
+		fillStatus(subjects, useSnapshots)
+	}
+
+	var matched []util.CachedRepo
+	for i, r := range candidates {
+		if _, ok := filter.Match(subjects[i]); ok {
+			matched = append(matched, r)
+		}
+	}
+	return matched, nil
+}
+
+// statusConcurrency bounds the `git status` runs of fillStatus.
+const statusConcurrency = 16
+
+// fillStatus sets the status of every subject, in parallel.
+func fillStatus(subjects []util.FilterRepo, useSnapshots bool) {
+	if useSnapshots {
+		store := util.LoadStatusStore()
+		for i := range subjects {
+			if snap, ok := store.Get(subjects[i].Path); ok {
+				subjects[i].Status, subjects[i].Known = snap.Summary, true
+			}
+		}
+		return
+	}
+	if len(subjects) == 0 {
+		return
+	}
+	pool := gogo.NewPool[struct{}](context.Background(), min(statusConcurrency, len(subjects)), len(subjects), func(ctx context.Context, i int) (struct{}, error) {
+		if !subjects[i].Missing {
+			subjects[i].Status, subjects[i].Known = currentStatus(subjects[i].Path)
+		}
+		return struct{}{}, nil
+	})
+	for range pool.Go() {
+	}
+}
+
+// currentStatus summarizes `git status` for the repo at path, as the
+// dashboard does.
+func currentStatus(path string) (ui.StatusSummary, bool) {
+	if gitdir.IsBare(path) {
+		return ui.BareSummary(path), true
+	}
+	out, err := exec.Command("git", "-C", path, "status", "--porcelain=v2", "--branch").Output()
+	if err != nil {
+		util.VerboseLog("status of %s: %s", path, err)
+		return ui.StatusSummary{}, false
+	}
+	summary := ui.ParsePorcelainV2(string(out))
+	if summary.Branch == "(detached)" {
+		summary.State, summary.Progress = ui.DetectGitState(path)
+	}
+	summary.Stale = ui.CheckStaleness(path, summary)
+	return summary, true
+}
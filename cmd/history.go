@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"github.com/urfave/cli/v2"
+)
+
+// historyListLimit is how many entries `gee history` shows by default.
+const historyListLimit = 20
+
+func HistoryCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "history",
+		Usage: "List recent pull/fetch/push/exec runs, show one, or re-run its failures",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "n",
+				Usage: "Number of entries to list",
+				Value: historyListLimit,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			entries, err := util.LoadHistory()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("No history yet. gee pull and gee exec runs, and the dashboard's pulls, fetches, pushes and execs, are recorded here.")
+				return nil
+			}
+			if n := c.Int("n"); n > 0 && len(entries) > n {
+				entries = entries[len(entries)-n:]
+			}
+			for _, e := range entries {
+				status := ui.StyleSuccess.Render("ok")
+				if failed := len(e.Failures()); failed > 0 {
+					status = ui.StyleError.Render(fmt.Sprintf("%d failed", failed))
+				}
+				fmt.Printf("%4d  %s  %-4s %-30s %3d repos  %-10s %s\n",
+					e.ID,
+					e.StartedAt.Format("2006-01-02 15:04:05"),
+					e.Source,
+					truncateLabel(e.Label(), 30),
+					len(e.Results),
+					status,
+					ui.StyleSummaryLine.Render(fmt.Sprintf("%.1fs", e.Duration.Seconds())),
+				)
+			}
+			return nil
+		},
+		Subcommands: []*cli.Command{
+			{
+				Name:      "show",
+				Usage:     "Show the per-repo results of one run",
+				ArgsUsage: "<id>",
+				Action: func(c *cli.Context) error {
+					entry, err := historyEntryArg(c)
+					if err != nil {
+						return err
+					}
+
+					fmt.Println(ui.StyleSummaryLine.Render(fmt.Sprintf("#%d  %s  (%s)",
+						entry.ID, entry.StartedAt.Format("2006-01-02 15:04:05"), entry.Source)))
+					results := make([]ui.RepoResult, len(entry.Results))
+					for i, r := range entry.Results {
+						results[i] = ui.RepoResult{
+							Name:   fmt.Sprintf("%s %s", r.Name, ui.StyleSummaryLine.Render(fmt.Sprintf("(%.1fs)", r.Duration.Seconds()))),
+							Stdout: r.StdoutTail,
+							Stderr: r.StderrTail,
+							Failed: r.Failed,
+						}
+					}
+					label := entry.Label()
+					if entry.Command == "exec" {
+						label = "$ " + entry.Args
+					}
+					ui.RenderResults(label, results, time.Now().Add(-entry.Duration))
+					return nil
+				},
+			},
+			{
+				Name:      "rerun",
+				Usage:     "Run the same command again over the same repos",
+				ArgsUsage: "<id>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "failed",
+						Usage: "Only re-run the repos that failed",
+					},
+				},
+				Action: func(c *cli.Context) error {
+					entry, err := historyEntryArg(c)
+					if err != nil {
+						return err
+					}
+
+					targets := entry.Results
+					// urfave/cli stops parsing flags at the first argument, so
+					// accept the documented `rerun <id> --failed` order too.
+					if c.Bool("failed") || slices.Contains(c.Args().Tail(), "--failed") {
+						targets = entry.Failures()
+						if len(targets) == 0 {
+							return util.NewInfo(fmt.Sprintf("nothing failed in #%d", entry.ID))
+						}
+					}
+
+					cache := util.NewRepoCache()
+					if _, err := cache.Load(); err != nil {
+						return err
+					}
+					cached := make([]util.CachedRepo, 0, len(targets))
+					for _, t := range targets {
+						if repo, ok := cache.FindByPath(t.Path); ok {
+							cached = append(cached, repo)
+							continue
+						}
+						cached = append(cached, util.CachedRepo{Name: filepath.Base(t.Path), Path: t.Path})
+					}
+
+					return rerunHistory(c.Context, entry, cached, cache.DisplayNames())
+				},
+			},
+		},
+	}
+}
+
+// rerunHistory runs entry's command again over cached, recording the run
+// as a new entry.
+func rerunHistory(ctx context.Context, entry util.HistoryEntry, cached []util.CachedRepo, names map[string]string) error {
+	switch entry.Command {
+	case "pull", "fetch", "push":
+		gitRepos(ctx, entry.Command, cached, names)
+	case "exec":
+		execRepos(ctx, cached, names, entry.Args)
+	default:
+		return util.NewWarning(fmt.Sprintf("#%d: don't know how to re-run %q", entry.ID, entry.Command))
+	}
+	return nil
+}
+
+func historyEntryArg(c *cli.Context) (util.HistoryEntry, error) {
+	if c.Args().Len() == 0 {
+		return util.HistoryEntry{}, util.NewWarning(fmt.Sprintf("no id provided. usage: gee history %s <id>", c.Command.Name))
+	}
+	id, err := strconv.Atoi(strings.TrimPrefix(c.Args().First(), "#"))
+	if err != nil {
+		return util.HistoryEntry{}, util.NewWarning(fmt.Sprintf("invalid history id %q", c.Args().First()))
+	}
+	return util.FindHistory(id)
+}
+
+func truncateLabel(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gee/pkg/util"
+)
+
+// gitIn runs git in dir, failing the test on error.
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func TestRerunHistory(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_AUTHOR_NAME", "gee")
+	t.Setenv("GIT_AUTHOR_EMAIL", "gee@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "gee")
+	t.Setenv("GIT_COMMITTER_EMAIL", "gee@example.com")
+
+	seed := filepath.Join(home, "seed")
+	origin := filepath.Join(home, "origin.git")
+	work := filepath.Join(home, "work")
+	gitIn(t, home, "init", "-q", seed)
+	gitIn(t, seed, "commit", "-q", "--allow-empty", "-m", "first")
+	gitIn(t, home, "clone", "-q", "--bare", seed, origin)
+	gitIn(t, home, "clone", "-q", origin, work)
+	gitIn(t, work, "commit", "-q", "--allow-empty", "-m", "second")
+
+	cached := []util.CachedRepo{{Name: "work", Path: work}}
+	names := map[string]string{work: "work"}
+	for _, command := range []string{"pull", "fetch", "push", "exec"} {
+		entry := util.HistoryEntry{ID: 1, Command: command}
+		if command == "exec" {
+			entry.Args = "git status"
+		}
+		if err := rerunHistory(context.Background(), entry, cached, names); err != nil {
+			t.Fatalf("%s: %v", command, err)
+		}
+
+		entries, err := util.LoadHistory()
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := entries[len(entries)-1]
+		if last.Command != command || last.Args != entry.Args {
+			t.Errorf("%s: recorded %q %q", command, last.Command, last.Args)
+		}
+		if len(last.Results) != 1 || last.Results[0].Failed {
+			t.Errorf("%s: results %+v", command, last.Results)
+		}
+	}
+
+	if err := rerunHistory(context.Background(), util.HistoryEntry{ID: 2, Command: "clone"}, cached, names); err == nil {
+		t.Error("re-ran an unknown command")
+	}
+}
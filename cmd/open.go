@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gee/pkg/gitdir"
+	"gee/pkg/util"
+
+	"github.com/urfave/cli/v2"
+)
+
+func OpenCmd() *cli.Command {
+	return &cli.Command{
+		Name:  "open",
+		Usage: "Open the current repo's page on GitHub/GitLab/Bitbucket/Gitea",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "repo",
+				Aliases: []string{"r"},
+				Usage:   "name, owner/name or path of the repository to open",
+			},
+			&cli.BoolFlag{
+				Name:  "branch",
+				Usage: "Open the current branch",
+			},
+			&cli.BoolFlag{
+				Name:  "pr",
+				Usage: "Open pull/merge requests for the current branch",
+			},
+			&cli.BoolFlag{
+				Name:  "ci",
+				Usage: "Open CI runs for the current branch",
+			},
+			&cli.BoolFlag{
+				Name:  "print",
+				Usage: "Print the URL instead of opening it",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			page := util.WebHome
+			set := 0
+			for flag, p := range map[string]util.WebPage{"branch": util.WebBranch, "pr": util.WebPullRequest, "ci": util.WebCI} {
+				if c.Bool(flag) {
+					page = p
+					set++
+				}
+			}
+			if set > 1 {
+				return util.NewWarning("use only one of --branch, --pr and --ci")
+			}
+
+			repoPath, remoteURL, err := openTarget(c.String("repo"))
+			if err != nil {
+				return err
+			}
+
+			target, err := util.RepoWebURL(repoPath, remoteURL, page)
+			if err != nil {
+				return err
+			}
+			if c.Bool("print") {
+				fmt.Println(target)
+				return nil
+			}
+			if err := util.OpenURL(target); err != nil {
+				return util.NewWarning(fmt.Sprintf("could not open a browser (%s); the URL is %s", err, target))
+			}
+			return util.NewInfo(fmt.Sprintf("opened %s", target))
+		},
+	}
+}
+
+// openTarget picks the repo for `gee open`: the --repo argument, else the
+// cached repo containing the working directory, else whatever git repo the
+// working directory is in.
+func openTarget(query string) (path, remoteURL string, err error) {
+	cache := util.NewRepoCache()
+	if _, err := cache.Load(); err != nil {
+		return "", "", err
+	}
+	if query != "" {
+		repo, err := resolveRepo(cache, query)
+		if err != nil {
+			return "", "", err
+		}
+		return repo.Path, repo.Remote, nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", "", err
+	}
+	if repo, ok := cache.FindByPath(cwd); ok {
+		return repo.Path, repo.Remote, nil
+	}
+	for dir := cwd; ; {
+		if _, ok := gitdir.Resolve(dir); ok {
+			return dir, "", nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", "", util.NewWarning("not inside a git repo; use --repo <name|path>")
+}
@@ -25,79 +25,132 @@ func PullCmd() *cli.Command {
 				Name:  "all",
 				Usage: "Target all cached repos, not just pinned",
 			},
+			filterFlag(),
 		},
 		Action: func(c *cli.Context) error {
-			startTime := time.Now()
-
 			cache := util.NewRepoCache()
 			if _, err := cache.Load(); err != nil {
 				return err
 			}
 
-			cwd, err := os.Getwd()
+			cached, err := targetRepos(c, cache, false)
 			if err != nil {
 				return err
 			}
-
-			cached := cache.LoadReposForCLI(cwd, c.Bool("all"))
 			if len(cached) == 0 {
+				if c.String("filter") != "" {
+					return util.NewInfo("no repos match the filter")
+				}
 				fmt.Println("No repos found. Run gee add in a git repo to pin it.")
 				return nil
 			}
 
-			repos := util.ToRepoSlice(cached)
-			git := command.GitRepoOperation{}
-			repoUtils := util.NewRepoUtils(git)
+			gitRepos(c.Context, "pull", cached, cache.DisplayNames())
+			return nil
+		},
+	}
+}
 
-			states := make([]*ui.SpinnerState, len(repos))
-			commandOnFinish := make([]*types.CommandOnFinish, len(repos))
+// gitVerbs are the progress labels of the git commands gitRepos runs.
+var gitVerbs = map[string]string{"pull": "Pulling", "fetch": "Fetching", "push": "Pushing"}
 
-			for i, repo := range repos {
-				states[i] = &ui.SpinnerState{
-					State: ui.StateLoading,
-					Msg:   fmt.Sprintf("Pulling %s", repo.Name),
-				}
-			}
+// gitRepos runs gitCommand ("pull", "fetch" or "push") in every repo
+// concurrently, renders the results and records the batch in the history
+// log.
+func gitRepos(ctx context.Context, gitCommand string, cached []util.CachedRepo, names map[string]string) {
+	startTime := time.Now()
 
-			finishPrint := ui.PrintSpinnerStates(os.Stdout, states)
+	repos := util.ToRepoSlice(cached)
+	git := command.GitRepoOperation{}
+	repoUtils := util.NewRepoUtils(git)
 
-			concurrency := len(repos)
-			pool := gogo.NewPool[struct{}](c.Context, concurrency, len(repos), func(ctx context.Context, i int) (struct{}, error) {
-				repo := repos[i]
-				state := states[i]
-				fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
+	states := make([]*ui.SpinnerState, len(repos))
+	commandOnFinish := make([]*types.CommandOnFinish, len(repos))
+	durations := make([]time.Duration, len(repos))
 
-				rc := &types.RunConfig{
-					StdErr: &bytes.Buffer{},
-					StdOut: &bytes.Buffer{},
-				}
-				git.Pull(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
-					repoUtils.HandlePullFinish(&repo, onFinish, state)
-					commandOnFinish[i] = onFinish
-				})
-				return struct{}{}, nil
-			})
+	for i := range repos {
+		states[i] = &ui.SpinnerState{
+			State: ui.StateLoading,
+			Msg:   fmt.Sprintf("%s %s", gitVerbs[gitCommand], util.DisplayName(names, cached[i].Path)),
+		}
+	}
 
-			for res := range pool.Go() {
-				if res.Error == nil {
-					continue
-				}
-				util.Warning("%s", res.Error)
-			}
-			finishPrint()
-			fmt.Println()
-
-			repoResults := make([]ui.RepoResult, len(repos))
-			for i, onFinish := range commandOnFinish {
-				repoResults[i] = ui.RepoResult{
-					Name:   repos[i].Name,
-					Stdout: onFinish.RunConfig.StdOut.String(),
-					Stderr: onFinish.RunConfig.StdErr.String(),
-					Failed: onFinish.Failed,
-				}
+	finishPrint := ui.PrintSpinnerStates(os.Stdout, states)
+
+	concurrency := len(repos)
+	pool := gogo.NewPool[struct{}](ctx, concurrency, len(repos), func(ctx context.Context, i int) (struct{}, error) {
+		repo := repos[i]
+		state := states[i]
+		fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
+		repoStart := time.Now()
+
+		rc := &types.RunConfig{
+			StdErr: &bytes.Buffer{},
+			StdOut: &bytes.Buffer{},
+		}
+		// Fetch and push have no fallback, unlike pull's clone of a
+		// missing repo.
+		finish := func(onFinish *types.CommandOnFinish) {
+			state.State = ui.StateSuccess
+			state.Msg = fmt.Sprintf("%s %s: done", gitCommand, onFinish.Repo)
+			if onFinish.Failed {
+				state.State = ui.StateError
+				state.Msg = fmt.Sprintf("failed to %s %s", gitCommand, repo.Name)
 			}
-			ui.RenderResults("pull", repoResults, startTime)
-			return nil
-		},
+			commandOnFinish[i] = onFinish
+		}
+		switch gitCommand {
+		case "fetch":
+			git.Fetch(repo.Name, fullPath, rc, finish)
+		case "push":
+			git.Push(repo.Name, fullPath, rc, finish)
+		default:
+			git.Pull(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
+				repoUtils.HandlePullFinish(&repo, onFinish, state)
+				commandOnFinish[i] = onFinish
+			})
+		}
+		durations[i] = time.Since(repoStart)
+		return struct{}{}, nil
+	})
+
+	for res := range pool.Go() {
+		if res.Error == nil {
+			continue
+		}
+		util.Warning("%s", res.Error)
+	}
+	finishPrint()
+	fmt.Println()
+
+	repoResults := make([]ui.RepoResult, len(repos))
+	history := util.HistoryEntry{Command: gitCommand, Source: "cli", StartedAt: startTime}
+	for i, onFinish := range commandOnFinish {
+		repoResults[i] = ui.RepoResult{
+			Name:   util.DisplayName(names, cached[i].Path),
+			Stdout: onFinish.RunConfig.StdOut.String(),
+			Stderr: onFinish.RunConfig.StdErr.String(),
+			Failed: onFinish.Failed,
+		}
+		history.Results = append(history.Results, util.NewHistoryResult(
+			repoResults[i].Name, cached[i].Path, onFinish.Failed, durations[i],
+			repoResults[i].Stdout, repoResults[i].Stderr))
+	}
+	ui.RenderResults(gitCommand, repoResults, startTime)
+
+	history.Duration = time.Since(startTime)
+	recordHistory(history)
+}
+
+// recordHistory appends a batch to the history log and tells the user how
+// to re-run its failures.
+func recordHistory(entry util.HistoryEntry) {
+	id, err := util.AppendHistory(entry)
+	if err != nil {
+		util.VerboseLog("record history: %s", err)
+		return
+	}
+	if failed := len(entry.Failures()); failed > 0 {
+		fmt.Println(ui.StyleSummaryLine.Render(fmt.Sprintf("Re-run the %d failed: gee history rerun %d --failed", failed, id)))
 	}
 }
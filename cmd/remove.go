@@ -17,7 +17,7 @@ func RemoveCmd() *cli.Command {
 			&cli.StringFlag{
 				Name:    "repo",
 				Aliases: []string{"r"},
-				Usage:   "specify the repository name to unpin",
+				Usage:   "name, owner/name or path of the repository to unpin",
 			},
 		},
 		Action: func(c *cli.Context) error {
@@ -26,53 +26,40 @@ func RemoveCmd() *cli.Command {
 				return err
 			}
 
-			repoName := c.String("repo")
-
-			// If no name given, try to detect from cwd.
-			if repoName == "" {
+			var target util.CachedRepo
+			if query := c.String("repo"); query != "" {
+				var err error
+				if target, err = resolveRepo(cache, query); err != nil {
+					return err
+				}
+			} else {
+				// If no name given, try to detect from cwd.
 				cwd, err := os.Getwd()
 				if err != nil {
 					return err
 				}
-				if found, ok := cache.FindByPath(cwd); ok {
-					repoName = found.Name
+				found, ok := cache.FindByPath(cwd)
+				if !ok {
+					return util.NewWarning("please specify --repo <name|path> or run from inside a pinned repo")
 				}
+				target = found
 			}
+			name := util.DisplayName(cache.DisplayNames(), target.Path)
 
-			if repoName == "" {
-				return util.NewWarning("please specify --repo <name> or run from inside a pinned repo")
-			}
-
-			// Find matching entry by name and unpin it.
-			all := cache.All()
-			var targetPath string
-			var wasPinned bool
-			for _, r := range all {
-				if r.Name == repoName {
-					targetPath = r.Path
-					wasPinned = r.Pinned
-					break
-				}
-			}
-
-			if targetPath == "" {
-				return util.NewWarning(fmt.Sprintf("%s not found in cache", repoName))
-			}
-
-			if wasPinned {
-				cache.Unpin(targetPath)
+			if target.Pinned {
+				cache.Unpin(target.Path)
 			} else {
-				cache.Remove(targetPath)
+				cache.Remove(target.Path)
 			}
 
 			if err := cache.Save(); err != nil {
 				return err
 			}
 
-			if wasPinned {
-				return util.NewInfo(fmt.Sprintf("unpinned %s", repoName))
+			if target.Pinned {
+				return util.NewInfo(fmt.Sprintf("unpinned %s (%s)", name, target.Path))
 			}
-			return util.NewInfo(fmt.Sprintf("removed %s from cache", repoName))
+			return util.NewInfo(fmt.Sprintf("removed %s (%s) from cache", name, target.Path))
 		},
 	}
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+)
+
+// resolveRepo turns a --repo argument into exactly one cached repo. When the
+// argument is ambiguous (two clones named "api") the user picks one
+// interactively; without a terminal the candidates are listed instead.
+func resolveRepo(cache *util.RepoCache, query string) (util.CachedRepo, error) {
+	matches := cache.Resolve(query)
+	switch len(matches) {
+	case 0:
+		return util.CachedRepo{}, util.NewWarning(fmt.Sprintf("%s not found in cache", query))
+	case 1:
+		return matches[0], nil
+	}
+
+	names := cache.DisplayNames()
+	if !stdinIsTerminal() {
+		lines := make([]string, len(matches))
+		for i, r := range matches {
+			lines[i] = fmt.Sprintf("  %s  %s", util.DisplayName(names, r.Path), r.Path)
+		}
+		return util.CachedRepo{}, util.NewWarning(fmt.Sprintf(
+			"%q matches %d repos; pass a path or one of these names:\n%s",
+			query, len(matches), strings.Join(lines, "\n")))
+	}
+
+	options := make([]huh.Option[int], len(matches))
+	for i, r := range matches {
+		options[i] = huh.NewOption(fmt.Sprintf("%s  %s", util.DisplayName(names, r.Path), r.Path), i)
+	}
+	var choice int
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewSelect[int]().
+				Title(fmt.Sprintf("%q matches %d repos", query, len(matches))).
+				Options(options...).
+				Value(&choice),
+		),
+	).WithTheme(formTheme())
+	if err := form.Run(); err != nil {
+		return util.CachedRepo{}, err
+	}
+	return matches[choice], nil
+}
+
+// formTheme styles huh prompts: huh's default colors, or its plain base
+// theme when color is off.
+func formTheme() *huh.Theme {
+	if ui.CurrentTheme().NoColor {
+		return huh.ThemeBase()
+	}
+	return huh.ThemeCharm()
+}
+
+func stdinIsTerminal() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"gee/pkg/command"
+	"gee/pkg/gitdir"
 	"gee/pkg/types"
 	"gee/pkg/ui"
 	"gee/pkg/util"
@@ -25,10 +26,15 @@ func StatusCmd() *cli.Command {
 				Name:  "all",
 				Usage: "Target all cached repos, not just pinned",
 			},
+			filterFlag(),
 			&cli.BoolFlag{
 				Name:  "verbose",
 				Usage: "Show full git status output instead of summary",
 			},
+			&cli.BoolFlag{
+				Name:  "cached",
+				Usage: "Print the last known status without running git",
+			},
 		},
 		Action: func(c *cli.Context) error {
 			startTime := time.Now()
@@ -39,28 +45,35 @@ func StatusCmd() *cli.Command {
 				return err
 			}
 
-			cwd, err := os.Getwd()
+			cached, err := targetRepos(c, cache, c.Bool("cached"))
 			if err != nil {
 				return err
 			}
-
-			cached := cache.LoadReposForCLI(cwd, c.Bool("all"))
 			if len(cached) == 0 {
+				if c.String("filter") != "" {
+					return util.NewInfo("no repos match the filter")
+				}
 				fmt.Println("No repos found. Run gee add in a git repo to pin it.")
 				return nil
 			}
 
+			if c.Bool("cached") {
+				renderCachedStatus(cached, cache.DisplayNames(), startTime)
+				return nil
+			}
+
 			repos := util.ToRepoSlice(cached)
+			names := cache.DisplayNames()
 			git := command.GitRepoOperation{}
 			repoUtils := util.NewRepoUtils(git)
 
 			states := make([]*ui.SpinnerState, len(repos))
 			commandOnFinish := make([]*types.CommandOnFinish, len(repos))
 
-			for i, repo := range repos {
+			for i := range repos {
 				states[i] = &ui.SpinnerState{
 					State: ui.StateLoading,
-					Msg:   fmt.Sprintf("Retrieve status for %s", repo.Name),
+					Msg:   fmt.Sprintf("Retrieve status for %s", util.DisplayName(names, cached[i].Path)),
 				}
 			}
 
@@ -69,6 +82,7 @@ func StatusCmd() *cli.Command {
 			concurrency := len(repos)
 			pool := gogo.NewPool[struct{}](c.Context, concurrency, len(repos), func(ctx context.Context, i int) (struct{}, error) {
 				repo := repos[i]
+				name := util.DisplayName(names, cached[i].Path)
 				fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
 
 				rc := &types.RunConfig{
@@ -76,15 +90,21 @@ func StatusCmd() *cli.Command {
 					StdOut: &bytes.Buffer{},
 				}
 
-				if verbose {
+				if gitdir.IsBare(fullPath) {
+					// No working tree to inspect; report HEAD only.
+					rc.StdOut.WriteString(fmt.Sprintf("bare repository on %s\n", gitdir.HeadBranch(fullPath)))
+					commandOnFinish[i] = &types.CommandOnFinish{Repo: repo.Name, RunConfig: rc}
+					states[i].State = ui.StateSuccess
+					states[i].Msg = fmt.Sprintf("successfully retrieved status for %s", name)
+				} else if verbose {
 					git.Status(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
 						commandOnFinish[i] = onFinish
 						if !onFinish.Failed {
 							states[i].State = ui.StateSuccess
-							states[i].Msg = fmt.Sprintf("successfully retrieved status for %s", onFinish.Repo)
+							states[i].Msg = fmt.Sprintf("successfully retrieved status for %s", name)
 						} else {
 							states[i].State = ui.StateError
-							states[i].Msg = fmt.Sprintf("failed to get status for %s", onFinish.Repo)
+							states[i].Msg = fmt.Sprintf("failed to get status for %s", name)
 						}
 					})
 				} else {
@@ -92,10 +112,10 @@ func StatusCmd() *cli.Command {
 						commandOnFinish[i] = onFinish
 						if !onFinish.Failed {
 							states[i].State = ui.StateSuccess
-							states[i].Msg = fmt.Sprintf("successfully retrieved status for %s", onFinish.Repo)
+							states[i].Msg = fmt.Sprintf("successfully retrieved status for %s", name)
 						} else {
 							states[i].State = ui.StateError
-							states[i].Msg = fmt.Sprintf("failed to get status for %s", onFinish.Repo)
+							states[i].Msg = fmt.Sprintf("failed to get status for %s", name)
 						}
 					})
 				}
@@ -116,7 +136,7 @@ func StatusCmd() *cli.Command {
 				repoResults := make([]ui.RepoResult, len(repos))
 				for i, onFinish := range commandOnFinish {
 					repoResults[i] = ui.RepoResult{
-						Name:   repos[i].Name,
+						Name:   util.DisplayName(names, cached[i].Path),
 						Stdout: onFinish.RunConfig.StdOut.String(),
 						Stderr: onFinish.RunConfig.StdErr.String(),
 						Failed: onFinish.Failed,
@@ -124,25 +144,52 @@ func StatusCmd() *cli.Command {
 				}
 				ui.RenderResults("", repoResults, startTime)
 			} else {
+				store := util.LoadStatusStore()
 				statusResults := make([]ui.RepoStatusResult, len(repos))
 				for i, onFinish := range commandOnFinish {
 					if onFinish.Failed {
-						statusResults[i] = ui.RepoStatusResult{Name: repos[i].Name, Failed: true}
+						statusResults[i] = ui.RepoStatusResult{Name: util.DisplayName(names, cached[i].Path), Failed: true}
 					} else {
+						fullPath := repoUtils.FullPathWithRepo(repos[i].Path, repos[i].Name)
 						summary := ui.ParsePorcelainV2(onFinish.RunConfig.StdOut.String())
-						if summary.Branch == "(detached)" {
-							fullPath := repoUtils.FullPathWithRepo(repos[i].Path, repos[i].Name)
+						if gitdir.IsBare(fullPath) {
+							summary = ui.BareSummary(fullPath)
+						} else if summary.Branch == "(detached)" {
 							summary.State, summary.Progress = ui.DetectGitState(fullPath)
 						}
 						statusResults[i] = ui.RepoStatusResult{
-							Name:    repos[i].Name,
+							Name:    util.DisplayName(names, cached[i].Path),
 							Summary: summary,
 						}
+						store.Set(fullPath, store.Snapshot(fullPath, summary))
 					}
 				}
+				if err := store.Save(); err != nil {
+					util.VerboseLog("save status snapshots: %s", err)
+				}
 				ui.RenderStatusTable(statusResults, startTime)
 			}
 			return nil
 		},
 	}
 }
+
+// renderCachedStatus prints the last persisted status of each repo, as
+// recorded by the dashboard or a previous `gee status`, without running git.
+func renderCachedStatus(cached []util.CachedRepo, names map[string]string, startTime time.Time) {
+	store := util.LoadStatusStore()
+	results := make([]ui.RepoStatusResult, len(cached))
+	for i, c := range cached {
+		snap, ok := store.Get(c.Path)
+		if !ok {
+			results[i] = ui.RepoStatusResult{Name: util.DisplayName(names, c.Path), Unknown: true}
+			continue
+		}
+		results[i] = ui.RepoStatusResult{
+			Name:      util.DisplayName(names, c.Path),
+			Summary:   snap.Summary,
+			CheckedAt: snap.CheckedAt,
+		}
+	}
+	ui.RenderStatusTable(results, startTime)
+}
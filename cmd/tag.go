@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gee/pkg/util"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TagCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "tag",
+		Usage:     "Add, remove or list a repo's tags",
+		ArgsUsage: "[tag...]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "repo",
+				Aliases: []string{"r"},
+				Usage:   "name, owner/name or path of the repository to tag",
+			},
+			&cli.BoolFlag{
+				Name:  "remove",
+				Usage: "Remove the given tags instead of adding them",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			cache := util.NewRepoCache()
+			if _, err := cache.Load(); err != nil {
+				return err
+			}
+
+			var target util.CachedRepo
+			if query := c.String("repo"); query != "" {
+				var err error
+				if target, err = resolveRepo(cache, query); err != nil {
+					return err
+				}
+			} else {
+				cwd, err := os.Getwd()
+				if err != nil {
+					return err
+				}
+				found, ok := cache.FindByPath(cwd)
+				if !ok {
+					return util.NewWarning("please specify --repo <name|path> or run from inside a known repo")
+				}
+				target = found
+			}
+			name := util.DisplayName(cache.DisplayNames(), target.Path)
+
+			if c.NArg() == 0 {
+				if c.Bool("remove") {
+					return util.NewWarning("name the tags to remove")
+				}
+				if len(target.Tags) == 0 {
+					return util.NewInfo(fmt.Sprintf("%s has no tags", name))
+				}
+				fmt.Println(strings.Join(target.Tags, " "))
+				return nil
+			}
+
+			tags := target.Tags
+			if c.Bool("remove") {
+				drop := make(map[string]bool)
+				for _, t := range util.NormalizeTags(c.Args().Slice()) {
+					drop[t] = true
+				}
+				var kept []string
+				for _, t := range tags {
+					if !drop[t] {
+						kept = append(kept, t)
+					}
+				}
+				tags = kept
+			} else {
+				tags = append(append([]string(nil), tags...), c.Args().Slice()...)
+			}
+			cache.SetTags(target.Path, tags)
+			if err := cache.Save(); err != nil {
+				return err
+			}
+
+			tags = util.NormalizeTags(tags)
+			if len(tags) == 0 {
+				return util.NewInfo(fmt.Sprintf("%s has no tags", name))
+			}
+			return util.NewInfo(fmt.Sprintf("%s tagged %s", name, strings.Join(tags, ", ")))
+		},
+	}
+}
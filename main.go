@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"gee/cmd"
+	"gee/pkg/gitdir"
 	"gee/pkg/tui"
+	"gee/pkg/ui"
 	"gee/pkg/util"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,6 +32,10 @@ func main() {
 			Name:  "init",
 			Usage: "Print shell integration function to stdout",
 		},
+		&cli.BoolFlag{
+			Name:  "no-color",
+			Usage: "Disable colors (also set by NO_COLOR)",
+		},
 	}
 
 	app.Before = func(c *cli.Context) error {
@@ -38,6 +44,10 @@ func main() {
 		if verbose {
 			util.VerboseLog("Verbose logging enabled")
 		}
+		// A broken config is reported by the commands that read it; until
+		// then, output uses the default theme.
+		settings, _ := util.LoadSettings()
+		applyTheme(c, settings, os.Stdout)
 		return nil
 	}
 
@@ -47,6 +57,10 @@ func main() {
 		cmd.StatusCmd(),
 		cmd.RemoveCmd(),
 		cmd.ExecCmd(),
+		cmd.CacheCmd(),
+		cmd.HistoryCmd(),
+		cmd.OpenCmd(),
+		cmd.TagCmd(),
 	}
 
 	// No subcommand → launch interactive TUI (or handle --init)
@@ -66,9 +80,19 @@ func main() {
 			migrateFromGeeToml(cache)
 		}
 
-		model := tui.NewAppModel(cache)
+		settings, err := util.LoadSettings()
+		if err != nil {
+			return err
+		}
+
+		// The TUI draws on stderr, which may be a terminal when stdout isn't.
+		applyTheme(c, settings, os.Stderr)
+		model := tui.NewAppModel(cache, settings)
 		p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(os.Stderr))
 		finalModel, err := p.Run()
+		if m, ok := finalModel.(tui.AppModel); ok {
+			m.Close()
+		}
 		if err != nil {
 			return err
 		}
@@ -101,6 +125,16 @@ func main() {
 	}
 }
 
+// applyTheme sets the configured theme up for output written to out,
+// warning about a bad one.
+func applyTheme(c *cli.Context, settings util.Settings, out *os.File) {
+	theme, err := ui.ResolveTheme(settings.Theme, c.Bool("no-color"), out)
+	ui.ApplyTheme(theme)
+	if err != nil {
+		util.Warning("%s", err)
+	}
+}
+
 func init() {
 	// Initialise a CLI app
 	app = cli.NewApp()
@@ -185,7 +219,8 @@ func migrateFromGeeToml(cache *util.RepoCache) {
 	for _, r := range cfg.Repos {
 		fullPath := filepath.Join(r.Path, r.Name)
 		// Verify the repo still exists on disk.
-		if info, err := os.Stat(filepath.Join(fullPath, ".git")); err != nil || !info.IsDir() {
+		info, ok := gitdir.Resolve(fullPath)
+		if !ok {
 			continue
 		}
 		cache.Add(util.CachedRepo{
@@ -193,6 +228,7 @@ func migrateFromGeeToml(cache *util.RepoCache) {
 			Path:         fullPath,
 			Remote:       r.Remote,
 			Pinned:       true,
+			Kind:         info.Kind,
 			DiscoveredAt: time.Now(),
 		})
 		imported++
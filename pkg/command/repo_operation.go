@@ -2,6 +2,8 @@ package command
 
 import (
 	"gee/pkg/types"
+	"io"
+	"os"
 	"os/exec"
 )
 
@@ -20,6 +22,19 @@ func (g *GitRepoOperation) Clone(repoName, remoteUrl, repoPath string, rc *types
 	runGitCommand(cmd, rc, repoName, onFinish)
 }
 
+// CloneTo clones remoteUrl into dest with extra `git clone` flags. git's
+// progress output is copied to progress as it is written, as well as to
+// rc.StdErr. Credential prompts are disabled so a clone needing a password
+// fails instead of hanging.
+func (g *GitRepoOperation) CloneTo(repoName, remoteUrl, dest string, flags []string, progress io.Writer, rc *types.RunConfig, onFinish func(onFinish *types.CommandOnFinish)) {
+	args := append([]string{"clone", "--progress"}, flags...)
+	args = append(args, "--", remoteUrl, dest)
+	cmd := exec.Command("git", args...)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Stderr = io.MultiWriter(rc.StdErr, progress)
+	runGitCommand(cmd, rc, repoName, onFinish)
+}
+
 func (g *GitRepoOperation) Status(repoName, repoPath string, rc *types.RunConfig, onFinish func(onFinish *types.CommandOnFinish)) {
 	cmd := exec.Command("git", "-c", "color.status=always", "-C", repoPath, "status")
 	runGitCommand(cmd, rc, repoName, onFinish)
@@ -30,8 +45,27 @@ func (g *GitRepoOperation) Pull(repoName, repoPath string, rc *types.RunConfig,
 	runGitCommand(cmd, rc, repoName, onFinish)
 }
 
+// Fetch fetches from every remote, pruning deleted branches. Like
+// CloneTo it disables credential prompts, which would hang the dashboard.
+func (g *GitRepoOperation) Fetch(repoName, repoPath string, rc *types.RunConfig, onFinish func(onFinish *types.CommandOnFinish)) {
+	cmd := exec.Command("git", "-C", repoPath, "fetch", "--all", "--prune")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	runGitCommand(cmd, rc, repoName, onFinish)
+}
+
+// Push pushes the current branch to its upstream, without credential
+// prompts.
+func (g *GitRepoOperation) Push(repoName, repoPath string, rc *types.RunConfig, onFinish func(onFinish *types.CommandOnFinish)) {
+	cmd := exec.Command("git", "-C", repoPath, "push")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	runGitCommand(cmd, rc, repoName, onFinish)
+}
+
 func (g *GitRepoOperation) StatusPorcelain(repoName, repoPath string, rc *types.RunConfig, onFinish func(onFinish *types.CommandOnFinish)) {
 	cmd := exec.Command("git", "-C", repoPath, "status", "--porcelain=v2", "--branch")
+	// Don't let status refresh the index: the write would wake the
+	// filesystem watcher and trigger another status.
+	cmd.Env = append(os.Environ(), "GIT_OPTIONAL_LOCKS=0")
 	runGitCommand(cmd, rc, repoName, onFinish)
 }
 
@@ -42,8 +76,15 @@ func (g *GitRepoOperation) GetRemoteURL(repoName, repoPath string, rc *types.Run
 
 // Helper for executing Git commands and handling results
 func runGitCommand(cmd *exec.Cmd, rc *types.RunConfig, repoName string, onFinish func(onFinish *types.CommandOnFinish)) {
-	cmd.Stdout = rc.StdOut
-	cmd.Stderr = rc.StdErr
+	var stdout, stderr io.Writer = rc.StdOut, rc.StdErr
+	if rc.Output != nil {
+		stdout = io.MultiWriter(rc.StdOut, rc.Output)
+		stderr = io.MultiWriter(rc.StdErr, rc.Output)
+	}
+	cmd.Stdout = stdout
+	if cmd.Stderr == nil {
+		cmd.Stderr = stderr
+	}
 
 	onFinishConfig := &types.CommandOnFinish{
 		Repo:      repoName,
@@ -0,0 +1,143 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Bitbucket lists repos through the Bitbucket Cloud REST API (2.0), with
+// either a username and app password or an access token.
+type Bitbucket struct {
+	host     string
+	protocol string
+	api      *apiClient
+	err      error // a credentials problem List and Owners report instead of calling the API
+}
+
+// NewBitbucket returns a Bitbucket Cloud provider. baseURL overrides the
+// API root (default https://api.bitbucket.org/2.0). With a username, token
+// is an app password sent as basic auth; without, a bearer access token.
+func NewBitbucket(baseURL, username, token, authSource, protocol string) *Bitbucket {
+	if baseURL == "" {
+		baseURL = "https://api.bitbucket.org/2.0"
+	}
+	return &Bitbucket{
+		host:     bitbucketHost(baseURL),
+		protocol: protocol,
+		api: newAPIClient(baseURL, authSource, func(req *http.Request) {
+			switch {
+			case username != "" && token != "":
+				req.SetBasicAuth(username, token)
+			case token != "":
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+		}),
+	}
+}
+
+func (b *Bitbucket) Name() string           { return "bitbucket" }
+func (b *Bitbucket) Host() string           { return b.host }
+func (b *Bitbucket) AuthSource() string     { return b.api.authSource }
+func (b *Bitbucket) CloneURL(r Repo) string { return PreferredCloneURL(r, b.protocol) }
+
+// bitbucketHost names the instance behind an API root: the web host, so
+// api.bitbucket.org is bitbucket.org.
+func bitbucketHost(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return baseURL
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Host), "api.")
+}
+
+// bitbucketPage is the envelope of every paginated 2.0 response.
+type bitbucketPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+type bitbucketRepo struct {
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	IsPrivate   bool   `json:"is_private"`
+	Language    string `json:"language"`
+	Parent      *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	Links struct {
+		Clone []struct {
+			Name string `json:"name"`
+			Href string `json:"href"`
+		} `json:"clone"`
+	} `json:"links"`
+}
+
+// bitbucketPages follows "next" links from endpoint, calling each for every
+// value, for up to maxPages pages.
+func bitbucketPages[T any](ctx context.Context, api *apiClient, endpoint string, each func(T)) error {
+	next := endpoint
+	for page := 0; next != "" && page < maxPages; page++ {
+		var p bitbucketPage[T]
+		if err := api.getJSON(ctx, next, &p); err != nil {
+			return err
+		}
+		for _, v := range p.Values {
+			each(v)
+		}
+		next = p.Next
+	}
+	if next != "" {
+		return ErrTruncated
+	}
+	return nil
+}
+
+// List implements Provider. Without an owner it lists every repo the user
+// is a member of; Bitbucket has no separate notion of personal repos.
+func (b *Bitbucket) List(ctx context.Context, owner string) ([]Repo, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	endpoint := "repositories?role=member&pagelen=100"
+	if owner != "" {
+		endpoint = fmt.Sprintf("repositories/%s?pagelen=100", url.PathEscape(owner))
+	}
+
+	var repos []Repo
+	err := bitbucketPages(ctx, b.api, endpoint, func(r bitbucketRepo) {
+		repo := Repo{
+			FullName:    r.FullName,
+			Description: r.Description,
+			Private:     r.IsPrivate,
+			Fork:        r.Parent != nil,
+			Language:    r.Language,
+		}
+		for _, l := range r.Links.Clone {
+			switch l.Name {
+			case "ssh":
+				repo.CloneURL = l.Href
+			case "https":
+				repo.HTTPSURL = l.Href
+			}
+		}
+		repos = append(repos, repo)
+	})
+	return repos, err
+}
+
+// Owners implements Provider, returning workspace slugs.
+func (b *Bitbucket) Owners(ctx context.Context) ([]string, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	var workspaces []string
+	err := bitbucketPages(ctx, b.api, "workspaces?role=member&pagelen=100", func(w struct {
+		Slug string `json:"slug"`
+	}) {
+		workspaces = append(workspaces, w.Slug)
+	})
+	return workspaces, err
+}
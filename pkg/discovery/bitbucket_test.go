@@ -0,0 +1,105 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestBitbucketListFollowsNext(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			t.Errorf("%s: basic auth = %q, %q, %v", r.URL, user, pass, ok)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			fmt.Fprintf(w, `{"values": [{"full_name": "acme/api", "links": {"clone": [
+				{"name": "https", "href": "https://bitbucket.org/acme/api.git"},
+				{"name": "ssh", "href": "git@bitbucket.org:acme/api.git"}]}}],
+				"next": "%s/repositories/acme?pagelen=100&page=2"}`, srv.URL)
+		case "2":
+			fmt.Fprint(w, `{"values": [{"full_name": "acme/web", "is_private": true, "parent": {"full_name": "other/web"}}]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer srv.Close()
+
+	b := NewBitbucket(srv.URL, "me", "secret", "config", "https")
+	repos, err := b.List(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 {
+		t.Fatalf("got %d repos, want 2: %+v", len(repos), repos)
+	}
+	if got := b.CloneURL(repos[0]); got != "https://bitbucket.org/acme/api.git" {
+		t.Errorf("CloneURL = %q", got)
+	}
+	if repos[0].CloneURL != "git@bitbucket.org:acme/api.git" {
+		t.Errorf("ssh URL = %q", repos[0].CloneURL)
+	}
+	if r := repos[1]; r.FullName != "acme/web" || !r.Private || !r.Fork {
+		t.Errorf("second repo = %+v", r)
+	}
+}
+
+func TestBitbucketRefusesNextOnAnotherHost(t *testing.T) {
+	var stolen atomic.Bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stolen.Store(true)
+		fmt.Fprint(w, `{"values": []}`)
+	}))
+	defer other.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"values": [{"full_name": "acme/api"}], "next": "%s/steal"}`, other.URL)
+	}))
+	defer srv.Close()
+
+	_, err := NewBitbucket(srv.URL, "me", "secret", "config", "").List(context.Background(), "")
+	if err == nil || !strings.Contains(err.Error(), "refusing to follow") {
+		t.Errorf("err = %v, want a refusal", err)
+	}
+	if stolen.Load() {
+		t.Error("request sent to the other host")
+	}
+}
+
+func TestBitbucketTruncatesAtPageCap(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"values": [{"full_name": "acme/api"}], "next": "%s/repositories?page=n"}`, srv.URL)
+	}))
+	defer srv.Close()
+
+	repos, err := NewBitbucket(srv.URL, "", "token", "config", "").List(context.Background(), "")
+	if !errors.Is(err, ErrTruncated) {
+		t.Errorf("err = %v, want ErrTruncated", err)
+	}
+	if len(repos) != maxPages {
+		t.Errorf("got %d repos, want %d", len(repos), maxPages)
+	}
+}
+
+func TestBitbucketHost(t *testing.T) {
+	tests := []struct {
+		baseURL, want string
+	}{
+		{"https://api.bitbucket.org/2.0", "bitbucket.org"},
+		{"https://API.Bitbucket.org/2.0/", "bitbucket.org"},
+		{"http://127.0.0.1:8080/2.0", "127.0.0.1:8080"},
+		{"https://bitbucket.example.com/rest/2.0", "bitbucket.example.com"},
+	}
+	for _, tt := range tests {
+		if got := NewBitbucket(tt.baseURL, "", "", "", "").Host(); got != tt.want {
+			t.Errorf("Host() for %s = %q, want %q", tt.baseURL, got, tt.want)
+		}
+	}
+}
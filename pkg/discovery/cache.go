@@ -0,0 +1,109 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gee/pkg/util"
+)
+
+// Listing is a cached result of List or Owners.
+type Listing struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Repos     []Repo    `json:"repos,omitempty"`
+	Owners    []string  `json:"owners,omitempty"`
+	Truncated bool      `json:"truncated,omitempty"` // the provider's page cap cut the listing short
+}
+
+// Cache keeps listings in ~/.config/gee/discovery.json so reopening the
+// discovery view doesn't re-page through hundreds of repos.
+// Safe for concurrent use.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Listing
+}
+
+// DefaultCachePath returns ~/.config/gee/discovery.json.
+func DefaultCachePath() string {
+	return filepath.Join(filepath.Dir(util.DefaultCachePath()), "discovery.json")
+}
+
+// LoadCache reads the cache at path. A missing or unreadable file yields an
+// empty cache.
+func LoadCache(path string) *Cache {
+	c := &Cache{path: path, entries: make(map[string]Listing)}
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &c.entries)
+	}
+	return c
+}
+
+func (c *Cache) get(key string, ttl time.Duration) (Listing, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l, ok := c.entries[key]
+	if !ok || time.Since(l.FetchedAt) > ttl {
+		return Listing{}, false
+	}
+	return l, true
+}
+
+func (c *Cache) put(key string, l Listing) {
+	c.mu.Lock()
+	c.entries[key] = l
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return
+	}
+	if err := util.WriteFileAtomic(c.path, data); err != nil {
+		util.VerboseLog("save discovery cache: %s", err)
+	}
+}
+
+// CachedList returns src's listing from the cache if it is younger than
+// ttl, otherwise fetches and caches it. force skips the cache. A listing
+// cut short by the page cap is kept, marked Truncated.
+func (c *Cache) CachedList(ctx context.Context, src Source, ttl time.Duration, force bool) (Listing, bool, error) {
+	if !force {
+		if l, ok := c.get(src.key(), ttl); ok {
+			return l, true, nil
+		}
+	}
+	repos, err := src.Provider.List(ctx, src.Owner)
+	truncated := errors.Is(err, ErrTruncated)
+	if err != nil && !truncated {
+		return Listing{}, false, err
+	}
+	l := Listing{FetchedAt: time.Now(), Repos: repos, Truncated: truncated}
+	c.put(src.key(), l)
+	return l, false, nil
+}
+
+// CachedOwners is CachedList for Owners.
+func (c *Cache) CachedOwners(ctx context.Context, p Provider, ttl time.Duration, force bool) (Listing, error) {
+	key := ownersKey(p)
+	if !force {
+		if l, ok := c.get(key, ttl); ok {
+			return l, nil
+		}
+	}
+	owners, err := p.Owners(ctx)
+	truncated := errors.Is(err, ErrTruncated)
+	if err != nil && !truncated {
+		return Listing{}, err
+	}
+	l := Listing{FetchedAt: time.Now(), Owners: owners, Truncated: truncated}
+	c.put(key, l)
+	return l, nil
+}
@@ -0,0 +1,86 @@
+package discovery
+
+import (
+	"cmp"
+	"errors"
+	"os"
+	"os/exec"
+
+	"gee/pkg/util"
+)
+
+// Configure builds the providers available to this user: gh and glab hosts
+// when those CLIs are on PATH, every configured Gitea/Forgejo instance, and
+// Bitbucket Cloud when configured or its credentials are in the environment.
+func Configure(s util.DiscoverySettings) []Provider {
+	var providers []Provider
+	if _, err := exec.LookPath("gh"); err == nil {
+		for _, host := range s.GitHubHosts {
+			providers = append(providers, NewGitHub(host, s.Protocol))
+		}
+	}
+	if _, err := exec.LookPath("glab"); err == nil {
+		for _, host := range s.GitLabHosts {
+			providers = append(providers, NewGitLab(host, s.Protocol))
+		}
+	}
+
+	for _, g := range s.Gitea {
+		if g.Host == "" {
+			util.VerboseLog("ignoring [[discovery.gitea]] without a host")
+			continue
+		}
+		token, source := g.Token, "config"
+		switch {
+		case token != "":
+		case len(s.Gitea) == 1:
+			token, source = firstEnv("GITEA_TOKEN", "FORGEJO_TOKEN")
+		default:
+			// One environment token can't tell instances apart; sending it
+			// to every host would leak it to the others.
+			source = "no token; with several instances, set token in each [[discovery.gitea]]"
+		}
+		if token == "" && source == "" {
+			source = "no token"
+		}
+		providers = append(providers, NewGitea(g.Host, g.URL, token, source, cmp.Or(g.Protocol, s.Protocol)))
+	}
+
+	bitbucket := s.Bitbucket
+	if len(bitbucket) == 0 {
+		if _, source := firstEnv("BITBUCKET_APP_PASSWORD", "BITBUCKET_TOKEN"); source != "" {
+			bitbucket = []util.BitbucketSettings{{}}
+		}
+	}
+	for _, b := range bitbucket {
+		username := b.Username
+		if username == "" {
+			username = os.Getenv("BITBUCKET_USERNAME")
+		}
+		token, source := b.Token, "config"
+		if token == "" && username != "" {
+			token, source = firstEnv("BITBUCKET_APP_PASSWORD", "BITBUCKET_TOKEN")
+		} else if token == "" {
+			// An app password only works as basic auth, with a username.
+			token, source = firstEnv("BITBUCKET_TOKEN")
+		}
+		p := NewBitbucket(b.URL, username, token, source, cmp.Or(b.Protocol, s.Protocol))
+		if token == "" && os.Getenv("BITBUCKET_APP_PASSWORD") != "" {
+			p.api.authSource = "$BITBUCKET_APP_PASSWORD"
+			p.err = errors.New("$BITBUCKET_APP_PASSWORD needs a username: set BITBUCKET_USERNAME or username in [[discovery.bitbucket]]")
+		}
+		providers = append(providers, p)
+	}
+	return providers
+}
+
+// firstEnv returns the value of the first set variable and its name as
+// "$NAME", or "" and "" if none is set.
+func firstEnv(names ...string) (value, source string) {
+	for _, name := range names {
+		if v := os.Getenv(name); v != "" {
+			return v, "$" + name
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,102 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"gee/pkg/util"
+)
+
+func TestConfigureProtocolFallback(t *testing.T) {
+	t.Setenv("PATH", "") // no gh or glab
+	s := util.DiscoverySettings{
+		Protocol: "https",
+		Gitea: []util.GiteaSettings{
+			{Host: "codeberg.org"},
+			{Host: "git.example.com", Protocol: "ssh"},
+		},
+		Bitbucket: []util.BitbucketSettings{{Token: "t"}},
+	}
+	repo := Repo{CloneURL: "git@host:acme/api.git", HTTPSURL: "https://host/acme/api.git"}
+
+	want := map[string]string{
+		"codeberg.org":    repo.HTTPSURL,
+		"git.example.com": repo.CloneURL,
+		"bitbucket.org":   repo.HTTPSURL,
+	}
+	providers := Configure(s)
+	if len(providers) != len(want) {
+		t.Fatalf("got %d providers, want %d", len(providers), len(want))
+	}
+	for _, p := range providers {
+		if got := p.CloneURL(repo); got != want[p.Host()] {
+			t.Errorf("%s: CloneURL = %q, want %q", p.Host(), got, want[p.Host()])
+		}
+	}
+}
+
+func TestConfigureGiteaEnvToken(t *testing.T) {
+	t.Setenv("PATH", "")
+	t.Setenv("GITEA_TOKEN", "secret")
+	t.Setenv("FORGEJO_TOKEN", "")
+
+	one := Configure(util.DiscoverySettings{Gitea: []util.GiteaSettings{{Host: "git.example.com"}}})
+	if len(one) != 1 || one[0].AuthSource() != "$GITEA_TOKEN" {
+		t.Fatalf("one instance: %+v", one)
+	}
+
+	several := Configure(util.DiscoverySettings{Gitea: []util.GiteaSettings{
+		{Host: "git.example.com"},
+		{Host: "codeberg.org"},
+		{Host: "forgejo.example.com", Token: "own"},
+	}})
+	want := []string{"no token", "no token", "config"}
+	for i, p := range several {
+		if src := p.AuthSource(); !strings.HasPrefix(src, want[i]) {
+			t.Errorf("%s: AuthSource = %q, want %s", p.Host(), src, want[i])
+		}
+	}
+}
+
+func TestConfigureBitbucketAppPasswordNeedsUsername(t *testing.T) {
+	t.Setenv("PATH", "")
+	t.Setenv("BITBUCKET_APP_PASSWORD", "secret")
+	t.Setenv("BITBUCKET_TOKEN", "")
+	t.Setenv("BITBUCKET_USERNAME", "")
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		fmt.Fprint(w, `{"values": []}`)
+	}))
+	defer srv.Close()
+
+	providers := Configure(util.DiscoverySettings{Bitbucket: []util.BitbucketSettings{{URL: srv.URL}}})
+	if len(providers) != 1 {
+		t.Fatalf("got %d providers, want 1", len(providers))
+	}
+	_, err := providers[0].List(context.Background(), "")
+	if err == nil || !strings.Contains(err.Error(), "BITBUCKET_USERNAME") {
+		t.Errorf("err = %v, want a request for BITBUCKET_USERNAME", err)
+	}
+	if requests.Load() != 0 {
+		t.Error("the app password was sent without a username")
+	}
+
+	// With a username it's basic auth.
+	t.Setenv("BITBUCKET_USERNAME", "me")
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me" || pass != "secret" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		fmt.Fprint(w, `{"values": []}`)
+	})
+	if _, err := Configure(util.DiscoverySettings{Bitbucket: []util.BitbucketSettings{{URL: srv.URL}}})[0].List(context.Background(), ""); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,84 @@
+// Package discovery lists remote repositories on hosting providers so they
+// can be browsed and cloned from the dashboard.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxPages bounds how many pages the native providers request for one
+// listing, in case an instance keeps paginating forever; listings that
+// reach it are cut short with ErrTruncated.
+const maxPages = 200
+
+// ErrTruncated is returned by List and Owners, along with what they listed,
+// when a listing stops at maxPages (for GitHub, at githubLimit repos).
+var ErrTruncated = errors.New("listing truncated at the page limit")
+
+// Repo is one remote repository.
+type Repo struct {
+	FullName    string `json:"full_name"` // owner/name, or group/subgroup/name on GitLab
+	Description string `json:"description,omitempty"`
+	CloneURL    string `json:"clone_url"` // ssh
+	HTTPSURL    string `json:"https_url,omitempty"`
+	Private     bool   `json:"private,omitempty"`
+	Archived    bool   `json:"archived,omitempty"`
+	Fork        bool   `json:"fork,omitempty"`
+	Language    string `json:"language,omitempty"` // primary language, where the provider reports one
+}
+
+// Provider lists repositories on one host of one hosting service.
+type Provider interface {
+	// Name identifies the implementation: "gh", "glab", "gitea" or "bitbucket".
+	Name() string
+	// Host is the instance, e.g. github.com or gitea.example.com.
+	Host() string
+	// List returns every repo owned by owner ("" for the authenticated
+	// user's own repos), following pagination. Past the page cap it
+	// returns the repos so far and ErrTruncated.
+	List(ctx context.Context, owner string) ([]Repo, error)
+	// Owners lists the orgs, groups or workspaces the user belongs to.
+	Owners(ctx context.Context) ([]string, error)
+	// CloneURL picks the URL to clone r with, honoring the provider's
+	// ssh/https preference.
+	CloneURL(r Repo) string
+	// AuthSource says where credentials come from, for error messages and
+	// the discovery header (e.g. "gh auth", "$GITEA_TOKEN", "config").
+	AuthSource() string
+}
+
+// Source identifies one listing: an owner's repos on a provider.
+type Source struct {
+	Provider Provider
+	Owner    string // org, user, group or workspace; "" means the authenticated user's own repos
+}
+
+// Label is a short description for headers, e.g. "github.com/acme".
+func (s Source) Label() string {
+	if s.Owner == "" {
+		return s.Provider.Host() + " (mine)"
+	}
+	return s.Provider.Host() + "/" + s.Owner
+}
+
+func (s Source) key() string {
+	return fmt.Sprintf("repos:%s@%s/%s", s.Provider.Name(), s.Provider.Host(), s.Owner)
+}
+
+func ownersKey(p Provider) string {
+	return fmt.Sprintf("owners:%s@%s", p.Name(), p.Host())
+}
+
+// PreferredCloneURL implements the common ssh/https preference: protocol
+// "https" picks the HTTPS URL when there is one, anything else the ssh URL.
+func PreferredCloneURL(r Repo, protocol string) string {
+	if protocol == "https" && r.HTTPSURL != "" {
+		return r.HTTPSURL
+	}
+	if r.CloneURL == "" {
+		return r.HTTPSURL
+	}
+	return r.CloneURL
+}
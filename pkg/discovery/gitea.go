@@ -0,0 +1,107 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// giteaPageSize is the default maximum page size of Gitea and Forgejo.
+const giteaPageSize = 50
+
+// Gitea lists repos through the Gitea/Forgejo REST API (/api/v1) with a
+// personal access token.
+type Gitea struct {
+	host     string
+	protocol string
+	api      *apiClient
+}
+
+// NewGitea returns a provider for the instance at host. baseURL overrides
+// the web root (default https://<host>); token may be empty for public
+// listings.
+func NewGitea(host, baseURL, token, authSource, protocol string) *Gitea {
+	if baseURL == "" {
+		baseURL = "https://" + host
+	}
+	return &Gitea{
+		host:     host,
+		protocol: protocol,
+		api: newAPIClient(baseURL+"/api/v1", authSource, func(req *http.Request) {
+			if token != "" {
+				req.Header.Set("Authorization", "token "+token)
+			}
+		}),
+	}
+}
+
+func (g *Gitea) Name() string           { return "gitea" }
+func (g *Gitea) Host() string           { return g.host }
+func (g *Gitea) AuthSource() string     { return g.api.authSource }
+func (g *Gitea) CloneURL(r Repo) string { return PreferredCloneURL(r, g.protocol) }
+
+type giteaRepo struct {
+	FullName    string `json:"full_name"`
+	Description string `json:"description"`
+	SSHURL      string `json:"ssh_url"`
+	CloneURL    string `json:"clone_url"`
+	Private     bool   `json:"private"`
+	Archived    bool   `json:"archived"`
+	Fork        bool   `json:"fork"`
+	Language    string `json:"language"`
+}
+
+// List implements Provider.
+func (g *Gitea) List(ctx context.Context, owner string) ([]Repo, error) {
+	endpoint := "user/repos"
+	if owner != "" {
+		endpoint = fmt.Sprintf("orgs/%s/repos", url.PathEscape(owner))
+	}
+
+	var repos []Repo
+	err := giteaPages(ctx, g.api, endpoint, func(raw []giteaRepo) {
+		for _, r := range raw {
+			repos = append(repos, Repo{
+				FullName:    r.FullName,
+				Description: r.Description,
+				CloneURL:    r.SSHURL,
+				HTTPSURL:    r.CloneURL,
+				Private:     r.Private,
+				Archived:    r.Archived,
+				Fork:        r.Fork,
+				Language:    r.Language,
+			})
+		}
+	})
+	return repos, err
+}
+
+// Owners implements Provider.
+func (g *Gitea) Owners(ctx context.Context) ([]string, error) {
+	var orgs []string
+	err := giteaPages(ctx, g.api, "user/orgs", func(raw []struct {
+		Username string `json:"username"`
+	}) {
+		for _, o := range raw {
+			orgs = append(orgs, o.Username)
+		}
+	})
+	return orgs, err
+}
+
+// giteaPages fetches endpoint page by page, passing each page to each, until
+// a short page signals the end or maxPages is reached.
+func giteaPages[T any](ctx context.Context, api *apiClient, endpoint string, each func([]T)) error {
+	for page := 1; page <= maxPages; page++ {
+		var raw []T
+		if err := api.getJSON(ctx, fmt.Sprintf("%s?limit=%d&page=%d", endpoint, giteaPageSize, page), &raw); err != nil {
+			return err
+		}
+		each(raw)
+		if len(raw) < giteaPageSize {
+			return nil
+		}
+	}
+	return ErrTruncated
+}
@@ -0,0 +1,71 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestGiteaListPages(t *testing.T) {
+	const total = giteaPageSize + 3
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if r.URL.Path != "/api/v1/orgs/acme/repos" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var items []string
+		for i := (page - 1) * giteaPageSize; i < min(page*giteaPageSize, total); i++ {
+			items = append(items, fmt.Sprintf(`{"full_name": "acme/r%d", "ssh_url": "git@h:acme/r%d.git", "clone_url": "https://h/acme/r%d.git"}`, i, i, i))
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(items, ","))
+	}))
+	defer srv.Close()
+
+	g := NewGitea("h", srv.URL, "secret", "config", "ssh")
+	repos, err := g.List(context.Background(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != total {
+		t.Fatalf("got %d repos, want %d", len(repos), total)
+	}
+	if last := repos[total-1]; last.FullName != fmt.Sprintf("acme/r%d", total-1) || g.CloneURL(last) != last.CloneURL {
+		t.Errorf("last repo = %+v", last)
+	}
+}
+
+func TestGiteaAuthError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "token is required"}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := NewGitea("h", srv.URL, "", "$GITEA_TOKEN", "").List(context.Background(), "")
+	if err == nil {
+		t.Fatal("no error for a 401")
+	}
+	for _, want := range []string{"401", "$GITEA_TOKEN"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("err = %q, want it to mention %s", err, want)
+		}
+	}
+}
+
+func TestGiteaServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "database is down", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := NewGitea("h", srv.URL, "", "", "").Owners(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "database is down") {
+		t.Errorf("err = %v, want the response body", err)
+	}
+}
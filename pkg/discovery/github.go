@@ -0,0 +1,118 @@
+package discovery
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// GitHub lists repos through the gh CLI, which handles authentication
+// (`gh auth login`, including GitHub Enterprise hosts).
+type GitHub struct {
+	host     string
+	protocol string
+}
+
+// NewGitHub returns a gh-backed provider for host.
+func NewGitHub(host, protocol string) *GitHub {
+	return &GitHub{host: host, protocol: protocol}
+}
+
+func (g *GitHub) Name() string           { return "gh" }
+func (g *GitHub) Host() string           { return g.host }
+func (g *GitHub) AuthSource() string     { return "gh auth" }
+func (g *GitHub) CloneURL(r Repo) string { return PreferredCloneURL(r, g.protocol) }
+
+// githubLimit is passed to `gh repo list --limit`; gh pages through the API
+// itself up to this many repos. A listing this long may be cut short.
+const githubLimit = 10000
+
+// runGH runs gh against host (GH_HOST selects GitHub Enterprise) and
+// returns stdout.
+func runGH(ctx context.Context, host string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	cmd.Env = append(os.Environ(), "GH_HOST="+host)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("gh %s: %s", args[0]+" "+args[1], msg)
+		}
+		return nil, fmt.Errorf("gh %s: %w", args[0]+" "+args[1], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// List implements Provider.
+func (g *GitHub) List(ctx context.Context, owner string) ([]Repo, error) {
+	args := []string{"repo", "list"}
+	if owner != "" {
+		args = append(args, owner)
+	}
+	args = append(args,
+		"--json", "nameWithOwner,description,sshUrl,url,isPrivate,isArchived,isFork,primaryLanguage",
+		"--limit", strconv.Itoa(githubLimit))
+	out, err := runGH(ctx, g.host, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []struct {
+		NameWithOwner string `json:"nameWithOwner"`
+		Description   string `json:"description"`
+		SSHURL        string `json:"sshUrl"`
+		URL           string `json:"url"`
+		IsPrivate     bool   `json:"isPrivate"`
+		IsArchived    bool   `json:"isArchived"`
+		IsFork        bool   `json:"isFork"`
+		Language      *struct {
+			Name string `json:"name"`
+		} `json:"primaryLanguage"`
+	}
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return nil, fmt.Errorf("parse gh output: %w", err)
+	}
+
+	repos := make([]Repo, len(raw))
+	for i, r := range raw {
+		repos[i] = Repo{
+			FullName:    r.NameWithOwner,
+			Description: r.Description,
+			CloneURL:    r.SSHURL,
+			HTTPSURL:    r.URL + ".git",
+			Private:     r.IsPrivate,
+			Archived:    r.IsArchived,
+			Fork:        r.IsFork,
+		}
+		if r.Language != nil {
+			repos[i].Language = r.Language.Name
+		}
+	}
+	if len(repos) >= githubLimit {
+		return repos, ErrTruncated
+	}
+	return repos, nil
+}
+
+// Owners implements Provider.
+func (g *GitHub) Owners(ctx context.Context) ([]string, error) {
+	out, err := runGH(ctx, g.host, "api", "--paginate", "user/orgs", "--jq", ".[].login")
+	if err != nil {
+		return nil, err
+	}
+	var orgs []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if org := strings.TrimSpace(scanner.Text()); org != "" {
+			orgs = append(orgs, org)
+		}
+	}
+	return orgs, scanner.Err()
+}
@@ -0,0 +1,124 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// gitlabPerPage is the API maximum.
+const gitlabPerPage = 100
+
+// GitLab lists projects through the glab CLI, which handles
+// authentication (`glab auth login`, including self-hosted instances).
+type GitLab struct {
+	host     string
+	protocol string
+}
+
+// NewGitLab returns a glab-backed provider for host.
+func NewGitLab(host, protocol string) *GitLab {
+	return &GitLab{host: host, protocol: protocol}
+}
+
+func (g *GitLab) Name() string           { return "glab" }
+func (g *GitLab) Host() string           { return g.host }
+func (g *GitLab) AuthSource() string     { return "glab auth" }
+func (g *GitLab) CloneURL(r Repo) string { return PreferredCloneURL(r, g.protocol) }
+
+// runGLab calls the GitLab REST API on host through `glab api`.
+func runGLab(ctx context.Context, host, endpoint string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "glab", "api", "--hostname", host, endpoint)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("glab api %s: %s", endpoint, msg)
+		}
+		return nil, fmt.Errorf("glab api %s: %w", endpoint, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// gitlabPages fetches endpoint page by page, decoding each page with decode,
+// until a short page signals the end or maxPages is reached.
+func gitlabPages(ctx context.Context, host, endpoint string, decode func([]byte) (int, error)) error {
+	sep := "?"
+	if strings.Contains(endpoint, "?") {
+		sep = "&"
+	}
+	for page := 1; page <= maxPages; page++ {
+		out, err := runGLab(ctx, host, fmt.Sprintf("%s%sper_page=%d&page=%d", endpoint, sep, gitlabPerPage, page))
+		if err != nil {
+			return err
+		}
+		n, err := decode(out)
+		if err != nil {
+			return fmt.Errorf("parse glab output: %w", err)
+		}
+		if n < gitlabPerPage {
+			return nil
+		}
+	}
+	return ErrTruncated
+}
+
+// List implements Provider.
+func (g *GitLab) List(ctx context.Context, owner string) ([]Repo, error) {
+	endpoint := "projects?owned=true&order_by=path&sort=asc"
+	if owner != "" {
+		endpoint = fmt.Sprintf("groups/%s/projects?include_subgroups=true&order_by=path&sort=asc", url.PathEscape(owner))
+	}
+
+	var repos []Repo
+	err := gitlabPages(ctx, g.host, endpoint, func(data []byte) (int, error) {
+		var raw []struct {
+			PathWithNamespace string          `json:"path_with_namespace"`
+			Description       string          `json:"description"`
+			SSHURLToRepo      string          `json:"ssh_url_to_repo"`
+			HTTPURLToRepo     string          `json:"http_url_to_repo"`
+			Visibility        string          `json:"visibility"`
+			Archived          bool            `json:"archived"`
+			ForkedFrom        json.RawMessage `json:"forked_from_project"`
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return 0, err
+		}
+		for _, r := range raw {
+			repos = append(repos, Repo{
+				FullName:    r.PathWithNamespace,
+				Description: r.Description,
+				CloneURL:    r.SSHURLToRepo,
+				HTTPSURL:    r.HTTPURLToRepo,
+				Private:     r.Visibility == "private",
+				Archived:    r.Archived,
+				Fork:        len(r.ForkedFrom) > 0 && string(r.ForkedFrom) != "null",
+			})
+		}
+		return len(raw), nil
+	})
+	return repos, err
+}
+
+// Owners implements Provider.
+func (g *GitLab) Owners(ctx context.Context) ([]string, error) {
+	var groups []string
+	err := gitlabPages(ctx, g.host, "groups?min_access_level=10&order_by=path&sort=asc", func(data []byte) (int, error) {
+		var raw []struct {
+			FullPath string `json:"full_path"`
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return 0, err
+		}
+		for _, g := range raw {
+			groups = append(groups, g.FullPath)
+		}
+		return len(raw), nil
+	})
+	return groups, err
+}
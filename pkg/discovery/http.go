@@ -0,0 +1,76 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// apiClient is the small JSON-over-HTTP client shared by the native
+// providers.
+type apiClient struct {
+	base       string // API root, no trailing slash
+	authorize  func(*http.Request)
+	authSource string
+	http       *http.Client
+}
+
+func newAPIClient(base, authSource string, authorize func(*http.Request)) *apiClient {
+	return &apiClient{
+		base:       strings.TrimRight(base, "/"),
+		authorize:  authorize,
+		authSource: authSource,
+		http:       &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// getJSON fetches endpoint (relative to base, or an absolute URL such as a
+// "next" link) and decodes the body into v. Absolute URLs must be on the
+// same scheme and host as base: credentials only go where they belong,
+// whatever a response links to.
+func (c *apiClient) getJSON(ctx context.Context, endpoint string, v any) error {
+	target := endpoint
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		target = c.base + "/" + strings.TrimLeft(endpoint, "/")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	if !c.owns(req.URL) {
+		return fmt.Errorf("refusing to follow %s away from %s", target, c.base)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.authorize != nil {
+		c.authorize(req)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("GET %s: %s (credentials from %s)", target, resp.Status, c.authSource)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GET %s: %s %s", target, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("GET %s: decode: %w", target, err)
+	}
+	return nil
+}
+
+// owns reports whether u has base's scheme and host.
+func (c *apiClient) owns(u *url.URL) bool {
+	base, err := url.Parse(c.base)
+	return err == nil && strings.EqualFold(u.Scheme, base.Scheme) && strings.EqualFold(u.Host, base.Host)
+}
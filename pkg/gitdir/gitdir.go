@@ -0,0 +1,130 @@
+// Package gitdir locates the git directory behind a repository path.
+// It understands plain `.git` directories, `.git` files that point elsewhere
+// via `gitdir:` (linked worktrees and submodules), and bare repositories.
+package gitdir
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kind classifies how a repository stores its git directory.
+type Kind string
+
+const (
+	KindNormal    Kind = "normal"    // .git is a directory
+	KindWorktree  Kind = "worktree"  // .git file pointing at <common>/worktrees/<name>
+	KindSubmodule Kind = "submodule" // .git file pointing at a standalone gitdir (e.g. .git/modules/x)
+	KindBare      Kind = "bare"      // the directory itself is the git directory
+)
+
+// Info describes a resolved repository.
+type Info struct {
+	Kind Kind
+	// GitDir holds per-worktree state: HEAD, index, MERGE_HEAD, rebase-merge/, ...
+	GitDir string
+	// CommonDir holds shared state: config, refs, objects. Equal to GitDir
+	// for everything except linked worktrees.
+	CommonDir string
+}
+
+// Resolve reports whether dir is the root of a git repository and, if so,
+// where its git directory lives.
+func Resolve(dir string) (Info, bool) {
+	dotGit := filepath.Join(dir, ".git")
+	if fi, err := os.Stat(dotGit); err == nil {
+		if fi.IsDir() {
+			return Info{Kind: KindNormal, GitDir: dotGit, CommonDir: dotGit}, true
+		}
+		gitDir, err := readGitFile(dotGit)
+		if err != nil {
+			return Info{}, false
+		}
+		if fi, err := os.Stat(gitDir); err != nil || !fi.IsDir() {
+			// Dangling pointer, e.g. a worktree whose main repo was deleted.
+			return Info{}, false
+		}
+		info := Info{Kind: KindSubmodule, GitDir: gitDir, CommonDir: gitDir}
+		if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+			common := strings.TrimSpace(string(data))
+			if !filepath.IsAbs(common) {
+				common = filepath.Join(gitDir, common)
+			}
+			info.Kind = KindWorktree
+			info.CommonDir = filepath.Clean(common)
+		}
+		return info, true
+	}
+
+	if IsBare(dir) {
+		return Info{Kind: KindBare, GitDir: dir, CommonDir: dir}, true
+	}
+	return Info{}, false
+}
+
+// IsBare applies git's own heuristic for a git directory: a HEAD file next to
+// objects/ and refs/ directories. The `.git` directory of a normal repo also
+// passes, so callers should not point it at one.
+func IsBare(dir string) bool {
+	if filepath.Base(dir) == ".git" {
+		return false
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil || fi.IsDir() {
+		return false
+	}
+	for _, sub := range []string{"objects", "refs"} {
+		if fi, err := os.Stat(filepath.Join(dir, sub)); err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+	return true
+}
+
+// HeadBranch returns the short branch name HEAD points at, or "(detached)".
+func HeadBranch(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	return "(detached)"
+}
+
+// DefaultBranch returns the branch origin/HEAD points at (as recorded by
+// clone or `git remote set-head`), or "" if unknown. Symbolic refs are never
+// packed, so reading the loose ref file is enough.
+func DefaultBranch(commonDir string) string {
+	data, err := os.ReadFile(filepath.Join(commonDir, "refs", "remotes", "origin", "HEAD"))
+	if err != nil {
+		return ""
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimPrefix(ref, "refs/remotes/origin/")
+}
+
+// readGitFile parses a `.git` file of the form "gitdir: <path>" and returns
+// the absolute path it points to.
+func readGitFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+	target, ok := strings.CutPrefix(line, "gitdir:")
+	if !ok {
+		return "", errors.New("not a gitdir file: " + path)
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return filepath.Clean(target), nil
+}
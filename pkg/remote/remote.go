@@ -0,0 +1,264 @@
+// Package remote parses git remote URLs into host/owner/name and knows how
+// each hosting provider lays out its web UI.
+//
+// All of these normalize to host "github.com", owner "acme", name "api":
+//
+//	git@github.com:acme/api.git
+//	ssh://git@github.com:22/acme/api.git
+//	https://user@github.com/acme/api
+//	git://github.com/acme/api.git
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Provider identifies the hosting software behind a remote.
+type Provider string
+
+const (
+	ProviderGitHub    Provider = "github"
+	ProviderGitLab    Provider = "gitlab"
+	ProviderBitbucket Provider = "bitbucket"
+	ProviderGitea     Provider = "gitea" // also Forgejo and Codeberg
+	ProviderGeneric   Provider = "generic"
+)
+
+// ErrUnsupported is returned when a provider has no page for a request,
+// e.g. CI for a generic host.
+var ErrUnsupported = errors.New("not supported for this host")
+
+// URL is a parsed remote.
+type URL struct {
+	Raw      string
+	Scheme   string // "ssh", "https", "http", "git" or "file"
+	Host     string // without user or port
+	Owner    string // may contain slashes for GitLab subgroups
+	Name     string // without .git
+	Provider Provider
+}
+
+// Parse normalizes a remote URL. It reports false for remotes with no
+// owner/name (local paths, bare hosts).
+func Parse(raw string) (URL, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return URL{}, false
+	}
+
+	u := URL{Raw: raw}
+	var path string
+	switch {
+	case strings.Contains(raw, "://"):
+		parsed, err := url.Parse(raw)
+		if err != nil {
+			return URL{}, false
+		}
+		u.Scheme = parsed.Scheme
+		if u.Scheme == "git+ssh" || u.Scheme == "ssh+git" {
+			u.Scheme = "ssh"
+		}
+		u.Host = parsed.Hostname()
+		path = parsed.Path
+	case isSCPLike(raw):
+		// [user@]host:owner/name.git
+		colon := strings.Index(raw, ":")
+		host := raw[:colon]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		u.Scheme = "ssh"
+		u.Host = host
+		path = raw[colon+1:]
+	default:
+		return URL{}, false
+	}
+
+	if u.Scheme == "file" || u.Host == "" {
+		return URL{}, false
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	slash := strings.LastIndex(path, "/")
+	if slash <= 0 || slash == len(path)-1 {
+		return URL{}, false
+	}
+	u.Owner = path[:slash]
+	u.Name = path[slash+1:]
+	u.Provider = DetectProvider(u.Host)
+
+	// Bitbucket Server serves clones under /scm/<project>/<repo>.
+	if u.bitbucketServer() {
+		u.Owner = strings.TrimPrefix(u.Owner, "scm/")
+	}
+	return u, true
+}
+
+// isSCPLike reports whether raw uses git's scp-like syntax. A colon before
+// the first slash distinguishes it from a local path; a single letter
+// before the colon is a Windows drive, not a host.
+func isSCPLike(raw string) bool {
+	colon := strings.Index(raw, ":")
+	if colon <= 1 {
+		return false
+	}
+	slash := strings.Index(raw, "/")
+	return slash < 0 || colon < slash
+}
+
+// hostProviders maps hosts that don't reveal their provider by name (GitHub
+// Enterprise, self-hosted GitLab, ...) to a provider. Settings fill it in
+// while discovery and commands may be reading it.
+var (
+	hostProvidersMu sync.RWMutex
+	hostProviders   = map[string]Provider{}
+)
+
+// SetHostProvider records the provider for a self-hosted host.
+func SetHostProvider(host string, p Provider) {
+	hostProvidersMu.Lock()
+	defer hostProvidersMu.Unlock()
+	hostProviders[strings.ToLower(host)] = p
+}
+
+// DetectProvider guesses the provider from the host name.
+func DetectProvider(host string) Provider {
+	host = strings.ToLower(host)
+	hostProvidersMu.RLock()
+	p, ok := hostProviders[host]
+	hostProvidersMu.RUnlock()
+	if ok {
+		return p
+	}
+	switch {
+	case host == "github.com" || host == "ssh.github.com" || strings.HasPrefix(host, "github."):
+		return ProviderGitHub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return ProviderGitLab
+	case host == "bitbucket.org" || host == "altssh.bitbucket.org" || strings.HasPrefix(host, "bitbucket."):
+		return ProviderBitbucket
+	case host == "codeberg.org" || strings.HasPrefix(host, "gitea.") || strings.HasPrefix(host, "forgejo."):
+		return ProviderGitea
+	}
+	return ProviderGeneric
+}
+
+// Slug returns "owner/name".
+func (u URL) Slug() string {
+	return u.Owner + "/" + u.Name
+}
+
+// Key identifies the repository regardless of protocol, user, port or
+// case, e.g. "github.com/acme/api" for every form of its remote URL.
+func (u URL) Key() string {
+	return strings.ToLower(u.webHost() + "/" + u.Slug())
+}
+
+// webHost is the host serving the web UI, which for GitHub's ssh-over-443
+// endpoint differs from the clone host.
+func (u URL) webHost() string {
+	if u.Host == "ssh.github.com" {
+		return "github.com"
+	}
+	if u.Provider == ProviderBitbucket && strings.HasPrefix(u.Host, "altssh.") {
+		return strings.TrimPrefix(u.Host, "altssh.")
+	}
+	return u.Host
+}
+
+// bitbucketServer reports whether the remote is on a self-hosted Bitbucket
+// (Server or Data Center), whose URLs are laid out unlike Bitbucket Cloud's.
+func (u URL) bitbucketServer() bool {
+	return u.Provider == ProviderBitbucket && u.webHost() != "bitbucket.org"
+}
+
+// WebURL returns the repository's home page.
+func (u URL) WebURL() string {
+	scheme := "https"
+	if u.Scheme == "http" {
+		scheme = "http"
+	}
+	if u.bitbucketServer() {
+		// Personal repos live under ~user, the rest under a project key.
+		if user, ok := strings.CutPrefix(u.Owner, "~"); ok {
+			return fmt.Sprintf("%s://%s/users/%s/repos/%s", scheme, u.webHost(), user, u.Name)
+		}
+		return fmt.Sprintf("%s://%s/projects/%s/repos/%s", scheme, u.webHost(), strings.ToUpper(u.Owner), u.Name)
+	}
+	return fmt.Sprintf("%s://%s/%s", scheme, u.webHost(), u.Slug())
+}
+
+// BranchURL returns the page showing branch's tree.
+func (u URL) BranchURL(branch string) string {
+	b := escapeRef(branch)
+	switch u.Provider {
+	case ProviderGitLab:
+		return u.WebURL() + "/-/tree/" + b
+	case ProviderBitbucket:
+		if u.bitbucketServer() {
+			return u.WebURL() + "/browse?at=" + url.QueryEscape("refs/heads/"+branch)
+		}
+		return u.WebURL() + "/src/" + b
+	case ProviderGitea:
+		return u.WebURL() + "/src/branch/" + b
+	default:
+		return u.WebURL() + "/tree/" + b
+	}
+}
+
+// PullRequestURL returns the page listing pull/merge requests from branch,
+// from which an existing one can be opened or a new one created; on
+// Bitbucket Server, the form creating one.
+func (u URL) PullRequestURL(branch string) (string, error) {
+	q := url.QueryEscape(branch)
+	switch u.Provider {
+	case ProviderGitHub:
+		return u.WebURL() + "/pulls?q=is%3Apr+head%3A" + q, nil
+	case ProviderGitLab:
+		return u.WebURL() + "/-/merge_requests?source_branch=" + q, nil
+	case ProviderBitbucket:
+		if u.bitbucketServer() {
+			// Server has no list filtered by source branch.
+			return u.WebURL() + "/pull-requests?create&sourceBranch=" + url.QueryEscape("refs/heads/"+branch), nil
+		}
+		return u.WebURL() + "/pull-requests?source=" + q, nil
+	case ProviderGitea:
+		return u.WebURL() + "/pulls?q=" + q, nil
+	}
+	return "", fmt.Errorf("pull requests: %w", ErrUnsupported)
+}
+
+// CIURL returns the CI runs page, filtered to branch where supported.
+func (u URL) CIURL(branch string) (string, error) {
+	q := url.QueryEscape(branch)
+	switch u.Provider {
+	case ProviderGitHub:
+		return u.WebURL() + "/actions?query=branch%3A" + q, nil
+	case ProviderGitLab:
+		return u.WebURL() + "/-/pipelines?ref=" + q, nil
+	case ProviderBitbucket:
+		if u.bitbucketServer() {
+			// Builds are reported per commit, with no page of their own.
+			break
+		}
+		return u.WebURL() + "/pipelines/results/branch/" + escapeRef(branch), nil
+	case ProviderGitea:
+		return u.WebURL() + "/actions", nil
+	}
+	return "", fmt.Errorf("CI: %w", ErrUnsupported)
+}
+
+// escapeRef escapes each segment of a ref for use in a URL path, keeping
+// the slashes of names like feature/login.
+func escapeRef(ref string) string {
+	segments := strings.Split(ref, "/")
+	for i, seg := range segments {
+		segments[i] = url.PathEscape(seg)
+	}
+	return strings.Join(segments, "/")
+}
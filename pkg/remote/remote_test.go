@@ -0,0 +1,148 @@
+package remote
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		raw      string
+		ok       bool
+		scheme   string
+		host     string
+		owner    string
+		name     string
+		provider Provider
+		key      string
+	}{
+		{"git@github.com:acme/api.git", true, "ssh", "github.com", "acme", "api", ProviderGitHub, "github.com/acme/api"},
+		{"https://github.com/Acme/API", true, "https", "github.com", "Acme", "API", ProviderGitHub, "github.com/acme/api"},
+		{"ssh://git@ssh.github.com:443/acme/api.git", true, "ssh", "ssh.github.com", "acme", "api", ProviderGitHub, "github.com/acme/api"},
+		{"git+ssh://git@github.com/acme/api", true, "ssh", "github.com", "acme", "api", ProviderGitHub, "github.com/acme/api"},
+		{"  https://user:pw@gitlab.com/group/sub/api.git/  ", true, "https", "gitlab.com", "group/sub", "api", ProviderGitLab, "gitlab.com/group/sub/api"},
+		{"git@altssh.bitbucket.org:acme/api.git", true, "ssh", "altssh.bitbucket.org", "acme", "api", ProviderBitbucket, "bitbucket.org/acme/api"},
+		{"https://bitbucket.example.com/scm/proj/api.git", true, "https", "bitbucket.example.com", "proj", "api", ProviderBitbucket, "bitbucket.example.com/proj/api"},
+		{"ssh://git@bitbucket.example.com:7999/proj/api.git", true, "ssh", "bitbucket.example.com", "proj", "api", ProviderBitbucket, "bitbucket.example.com/proj/api"},
+		{"https://bitbucket.org/scm/api.git", true, "https", "bitbucket.org", "scm", "api", ProviderBitbucket, "bitbucket.org/scm/api"},
+		{"https://gitlab.com/scm/tools/api.git", true, "https", "gitlab.com", "scm/tools", "api", ProviderGitLab, "gitlab.com/scm/tools/api"},
+		{"git@codeberg.org:scm/api/sub.git", true, "ssh", "codeberg.org", "scm/api", "sub", ProviderGitea, "codeberg.org/scm/api/sub"},
+		{"https://git.internal/scm/proj/api.git", true, "https", "git.internal", "scm/proj", "api", ProviderGeneric, "git.internal/scm/proj/api"},
+		{"https://codeberg.org/acme/api.git", true, "https", "codeberg.org", "acme", "api", ProviderGitea, "codeberg.org/acme/api"},
+		{"http://git.internal:8080/acme/api", true, "http", "git.internal", "acme", "api", ProviderGeneric, "git.internal/acme/api"},
+		{"git://example.org/acme/api.git", true, "git", "example.org", "acme", "api", ProviderGeneric, "example.org/acme/api"},
+
+		{"", false, "", "", "", "", "", ""},
+		{"/srv/git/api.git", false, "", "", "", "", "", ""},
+		{"../api", false, "", "", "", "", "", ""},
+		{`C:\repos\api`, false, "", "", "", "", "", ""},
+		{"file:///srv/git/acme/api.git", false, "", "", "", "", "", ""},
+		{"https://github.com/acme", false, "", "", "", "", "", ""},
+		{"git@github.com:api.git", false, "", "", "", "", "", ""},
+		{"https://github.com/", false, "", "", "", "", "", ""},
+	}
+	for _, tt := range tests {
+		u, ok := Parse(tt.raw)
+		if ok != tt.ok {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.raw, ok, tt.ok)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if u.Scheme != tt.scheme || u.Host != tt.host || u.Owner != tt.owner || u.Name != tt.name || u.Provider != tt.provider {
+			t.Errorf("Parse(%q) = %s %s %s/%s %s, want %s %s %s/%s %s", tt.raw,
+				u.Scheme, u.Host, u.Owner, u.Name, u.Provider,
+				tt.scheme, tt.host, tt.owner, tt.name, tt.provider)
+		}
+		if got := u.Key(); got != tt.key {
+			t.Errorf("Parse(%q).Key() = %q, want %q", tt.raw, got, tt.key)
+		}
+	}
+}
+
+func TestSetHostProvider(t *testing.T) {
+	SetHostProvider("Git.Corp.Example", ProviderGitLab)
+	defer func() {
+		hostProvidersMu.Lock()
+		delete(hostProviders, "git.corp.example")
+		hostProvidersMu.Unlock()
+	}()
+
+	u, ok := Parse("git@git.corp.example:team/api.git")
+	if !ok || u.Provider != ProviderGitLab {
+		t.Fatalf("Parse = %+v, %v; want a GitLab remote", u, ok)
+	}
+	if got, want := u.BranchURL("feature/login"), "https://git.corp.example/team/api/-/tree/feature/login"; got != want {
+		t.Errorf("BranchURL = %q, want %q", got, want)
+	}
+}
+
+func TestWebURLs(t *testing.T) {
+	tests := []struct {
+		raw             string
+		web, branch, pr string
+		ci              string // "" for ErrUnsupported
+	}{
+		{
+			raw:    "git@github.com:acme/api.git",
+			web:    "https://github.com/acme/api",
+			branch: "https://github.com/acme/api/tree/feature/login",
+			pr:     "https://github.com/acme/api/pulls?q=is%3Apr+head%3Afeature%2Flogin",
+			ci:     "https://github.com/acme/api/actions?query=branch%3Afeature%2Flogin",
+		},
+		{
+			raw:    "https://gitlab.com/scm/tools/api.git",
+			web:    "https://gitlab.com/scm/tools/api",
+			branch: "https://gitlab.com/scm/tools/api/-/tree/feature/login",
+			pr:     "https://gitlab.com/scm/tools/api/-/merge_requests?source_branch=feature%2Flogin",
+			ci:     "https://gitlab.com/scm/tools/api/-/pipelines?ref=feature%2Flogin",
+		},
+		{
+			raw:    "git@altssh.bitbucket.org:acme/api.git",
+			web:    "https://bitbucket.org/acme/api",
+			branch: "https://bitbucket.org/acme/api/src/feature/login",
+			pr:     "https://bitbucket.org/acme/api/pull-requests?source=feature%2Flogin",
+			ci:     "https://bitbucket.org/acme/api/pipelines/results/branch/feature/login",
+		},
+		{
+			raw:    "https://bitbucket.example.com/scm/proj/api.git",
+			web:    "https://bitbucket.example.com/projects/PROJ/repos/api",
+			branch: "https://bitbucket.example.com/projects/PROJ/repos/api/browse?at=refs%2Fheads%2Ffeature%2Flogin",
+			pr:     "https://bitbucket.example.com/projects/PROJ/repos/api/pull-requests?create&sourceBranch=refs%2Fheads%2Ffeature%2Flogin",
+		},
+		{
+			raw:    "ssh://git@bitbucket.example.com:7999/~me/api.git",
+			web:    "https://bitbucket.example.com/users/me/repos/api",
+			branch: "https://bitbucket.example.com/users/me/repos/api/browse?at=refs%2Fheads%2Ffeature%2Flogin",
+			pr:     "https://bitbucket.example.com/users/me/repos/api/pull-requests?create&sourceBranch=refs%2Fheads%2Ffeature%2Flogin",
+		},
+		{
+			raw:    "https://codeberg.org/acme/api.git",
+			web:    "https://codeberg.org/acme/api",
+			branch: "https://codeberg.org/acme/api/src/branch/feature/login",
+			pr:     "https://codeberg.org/acme/api/pulls?q=feature%2Flogin",
+			ci:     "https://codeberg.org/acme/api/actions",
+		},
+	}
+	const branch = "feature/login"
+	for _, tt := range tests {
+		u, ok := Parse(tt.raw)
+		if !ok {
+			t.Fatalf("Parse(%q) failed", tt.raw)
+		}
+		if got := u.WebURL(); got != tt.web {
+			t.Errorf("%s: WebURL = %q, want %q", tt.raw, got, tt.web)
+		}
+		if got := u.BranchURL(branch); got != tt.branch {
+			t.Errorf("%s: BranchURL = %q, want %q", tt.raw, got, tt.branch)
+		}
+		if got, err := u.PullRequestURL(branch); err != nil || got != tt.pr {
+			t.Errorf("%s: PullRequestURL = %q, %v; want %q", tt.raw, got, err, tt.pr)
+		}
+		got, err := u.CIURL(branch)
+		if tt.ci == "" && !errors.Is(err, ErrUnsupported) || tt.ci != "" && (err != nil || got != tt.ci) {
+			t.Errorf("%s: CIURL = %q, %v; want %q", tt.raw, got, err, tt.ci)
+		}
+	}
+}
@@ -0,0 +1,382 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gee/pkg/remote"
+	"gee/pkg/util"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// rowSort is one way to order the dashboard (S cycles through them).
+type rowSort struct {
+	ID    string // persisted in util.Prefs
+	Label string
+	// less orders two rows; ties fall back to the name.
+	less func(a, b RepoRow) bool
+}
+
+// rowSorts lists the sort orders in the order S cycles through them. The
+// first keeps the cache's order: pinned first, then by name.
+var rowSorts = []rowSort{
+	{ID: "", Label: "pinned, name"},
+	{ID: "name", Label: "name", less: func(a, b RepoRow) bool { return false }},
+	{ID: "commit", Label: "last commit", less: func(a, b RepoRow) bool { return a.LastCommit.After(b.LastCommit) }},
+	{ID: "dirty", Label: "dirtiness", less: func(a, b RepoRow) bool { return changeCount(a) > changeCount(b) }},
+	{ID: "behind", Label: "behind", less: func(a, b RepoRow) bool { return a.Status.Behind > b.Status.Behind }},
+	{ID: "ahead", Label: "ahead", less: func(a, b RepoRow) bool { return a.Status.Ahead > b.Status.Ahead }},
+	{ID: "stale", Label: "stale", less: func(a, b RepoRow) bool { return a.Status.Stale && !b.Status.Stale }},
+	{ID: "path", Label: "path", less: func(a, b RepoRow) bool { return a.Path < b.Path }},
+}
+
+// changeCount is how dirty a row is: every changed, untracked or
+// conflicted file.
+func changeCount(r RepoRow) int {
+	s := r.Status
+	return s.Staged + s.Modified + s.Untracked + s.Conflicts
+}
+
+// rowGrouping is one way to split the dashboard into sections (= cycles
+// through them).
+type rowGrouping struct {
+	ID    string // persisted in util.Prefs
+	Label string
+	// groups returns the sections a row belongs to: one, or for tags one
+	// per tag.
+	groups func(r RepoRow) []groupKey
+}
+
+// groupKey identifies a section. Sections are ordered by Rank, then Label.
+type groupKey struct {
+	Key   string
+	Label string
+	Rank  int
+}
+
+// rowGroupings lists the groupings in the order = cycles through them. The
+// first shows one ungrouped table.
+var rowGroupings = []rowGrouping{
+	{ID: "", Label: "none"},
+	{ID: "pinned", Label: "pinned", groups: groupByPinned},
+	{ID: "dir", Label: "directory", groups: groupByDir},
+	{ID: "remote", Label: "remote owner", groups: groupByRemote},
+	{ID: "tag", Label: "tag", groups: groupByTag},
+	{ID: "status", Label: "status", groups: groupByStatus},
+}
+
+func groupByPinned(r RepoRow) []groupKey {
+	if r.Pinned {
+		return []groupKey{{Key: "pinned", Label: "pinned"}}
+	}
+	return []groupKey{{Key: "discovered", Label: "discovered", Rank: 1}}
+}
+
+func groupByDir(r RepoRow) []groupKey {
+	dir := filepath.Dir(r.Path)
+	return []groupKey{{Key: dir, Label: tildePath(dir)}}
+}
+
+func groupByRemote(r RepoRow) []groupKey {
+	u, ok := remote.Parse(r.Repo.Remote)
+	if !ok {
+		return []groupKey{{Key: "", Label: "no remote", Rank: 1}}
+	}
+	owner := strings.ToLower(u.Host + "/" + u.Owner)
+	return []groupKey{{Key: owner, Label: owner}}
+}
+
+func groupByTag(r RepoRow) []groupKey {
+	if len(r.Tags) == 0 {
+		return []groupKey{{Key: "", Label: "untagged", Rank: 1}}
+	}
+	keys := make([]groupKey, len(r.Tags))
+	for i, t := range r.Tags {
+		keys[i] = groupKey{Key: t, Label: t}
+	}
+	return keys
+}
+
+// Status classes, worst first.
+var statusClasses = []string{"conflict", "dirty", "behind", "clean", "unknown"}
+
+func groupByStatus(r RepoRow) []groupKey {
+	class := statusClass(r)
+	for i, c := range statusClasses {
+		if c == class {
+			return []groupKey{{Key: c, Label: c, Rank: i}}
+		}
+	}
+	return nil
+}
+
+// statusClass sums a row up as one of statusClasses.
+func statusClass(r RepoRow) string {
+	s := r.Status
+	switch {
+	case r.Missing || r.Failed || r.CheckedAt.IsZero():
+		return "unknown"
+	case s.Bare:
+		// No working tree to be dirty or mid-merge.
+		return "clean"
+	case s.Conflicts > 0 || s.State != "":
+		return "conflict"
+	case changeCount(r) > 0:
+		return "dirty"
+	case s.Behind > 0:
+		return "behind"
+	}
+	return "clean"
+}
+
+// rowGroup is a section header in the dashboard.
+type rowGroup struct {
+	groupKey
+	Count     int // rows in the section that pass the filter
+	Collapsed bool
+}
+
+func (m *AppModel) rowSort() rowSort {
+	for _, s := range rowSorts {
+		if s.ID == m.Prefs.Sort {
+			return s
+		}
+	}
+	return rowSorts[0]
+}
+
+func (m *AppModel) rowGrouping() rowGrouping {
+	for _, g := range rowGroupings {
+		if g.ID == m.Prefs.Group {
+			return g
+		}
+	}
+	return rowGroupings[0]
+}
+
+// arrange sorts the filtered rows and, when grouping, splits them into
+// sections, each led by a header entry. Collapsed sections keep only their
+// header.
+func (m *AppModel) arrange(rows []filteredRow) []filteredRow {
+	if by := m.rowSort(); by.less != nil {
+		sort.SliceStable(rows, func(i, j int) bool {
+			a, b := rows[i].row, rows[j].row
+			if by.less(a, b) {
+				return true
+			}
+			if by.less(b, a) {
+				return false
+			}
+			return strings.ToLower(a.DisplayName) < strings.ToLower(b.DisplayName)
+		})
+	}
+
+	grouping := m.rowGrouping()
+	if grouping.groups == nil {
+		return rows
+	}
+	members := make(map[groupKey][]filteredRow)
+	var keys []groupKey
+	for _, fr := range rows {
+		for _, k := range grouping.groups(fr.row) {
+			if _, ok := members[k]; !ok {
+				keys = append(keys, k)
+			}
+			members[k] = append(members[k], fr)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].Rank != keys[j].Rank {
+			return keys[i].Rank < keys[j].Rank
+		}
+		return strings.ToLower(keys[i].Label) < strings.ToLower(keys[j].Label)
+	})
+
+	out := make([]filteredRow, 0, len(rows)+len(keys))
+	for _, k := range keys {
+		g := &rowGroup{groupKey: k, Count: len(members[k]), Collapsed: m.collapsed(k)}
+		out = append(out, filteredRow{origIndex: -1, group: g})
+		if !g.Collapsed {
+			out = append(out, members[k]...)
+		}
+	}
+	return out
+}
+
+// collapsedKey is how a folded section is remembered in util.Prefs.
+func (m *AppModel) collapsedKey(k groupKey) string {
+	return m.Prefs.Group + ":" + k.Key
+}
+
+func (m *AppModel) collapsed(k groupKey) bool {
+	key := m.collapsedKey(k)
+	for _, c := range m.Prefs.Collapsed {
+		if c == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setCollapsed folds or unfolds the section k.
+func (m *AppModel) setCollapsed(k groupKey, fold bool) {
+	key := m.collapsedKey(k)
+	var kept []string
+	for _, c := range m.Prefs.Collapsed {
+		if c != key {
+			kept = append(kept, c)
+		}
+	}
+	if fold {
+		kept = append(kept, key)
+	}
+	m.Prefs.Collapsed = kept
+}
+
+// cursorGroup returns the section under the cursor: its header, or the
+// section of the row there.
+func (m *AppModel) cursorGroup() (*rowGroup, bool) {
+	filtered := m.filteredRows()
+	for i := min(m.Cursor, len(filtered)-1); i >= 0; i-- {
+		if g := filtered[i].group; g != nil {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// toggleGroup folds or unfolds the section under the cursor, leaving the
+// cursor on its header.
+func (m *AppModel) toggleGroup() tea.Cmd {
+	g, ok := m.cursorGroup()
+	if !ok {
+		return nil
+	}
+	m.setCollapsed(g.groupKey, !g.Collapsed)
+	m.cursorToGroup(g.groupKey)
+	return savePrefsCmd(m.Prefs)
+}
+
+// toggleAllGroups folds every section, or unfolds them all when they
+// already are.
+func (m *AppModel) toggleAllGroups() tea.Cmd {
+	var groups []*rowGroup
+	fold := false
+	for _, fr := range m.filteredRows() {
+		if fr.group != nil {
+			groups = append(groups, fr.group)
+			fold = fold || !fr.group.Collapsed
+		}
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	current, _ := m.cursorGroup()
+	for _, g := range groups {
+		m.setCollapsed(g.groupKey, fold)
+	}
+	if current != nil {
+		m.cursorToGroup(current.groupKey)
+	}
+	return savePrefsCmd(m.Prefs)
+}
+
+// cursorToGroup moves the cursor to the header of section k.
+func (m *AppModel) cursorToGroup(k groupKey) {
+	for i, fr := range m.filteredRows() {
+		if fr.group != nil && fr.group.groupKey == k {
+			m.Cursor = i
+			return
+		}
+	}
+}
+
+// cycleSort switches to the next sort order.
+func (m *AppModel) cycleSort() tea.Cmd {
+	i := 0
+	for j, s := range rowSorts {
+		if s.ID == m.Prefs.Sort {
+			i = j
+		}
+	}
+	return m.setSort(rowSorts[(i+1)%len(rowSorts)].ID)
+}
+
+// setSort switches to the sort order with the given ID, keeping the cursor
+// on the same repo.
+func (m *AppModel) setSort(id string) tea.Cmd {
+	m.keepCursor(func() { m.Prefs.Sort = id })
+	m.ActionLog = append(m.ActionLog, "sort by "+m.rowSort().Label)
+	return savePrefsCmd(m.Prefs)
+}
+
+// cycleGrouping switches to the next grouping, keeping the cursor on the
+// same repo.
+func (m *AppModel) cycleGrouping() tea.Cmd {
+	m.keepCursor(func() {
+		i := 0
+		for j, g := range rowGroupings {
+			if g.ID == m.Prefs.Group {
+				i = j
+			}
+		}
+		m.Prefs.Group = rowGroupings[(i+1)%len(rowGroupings)].ID
+	})
+	m.RangeAnchor = -1
+	m.ActionLog = append(m.ActionLog, "group by "+m.rowGrouping().Label)
+	return savePrefsCmd(m.Prefs)
+}
+
+// keepCursor runs change, which may reorder the table, then puts the cursor
+// back on the repo it was on. If that repo is gone or folded away the
+// cursor stays where it was, within bounds.
+func (m *AppModel) keepCursor(change func()) {
+	row, ok := m.selectedRow()
+	change()
+	if !ok || !m.cursorToRow(row.Path) {
+		m.clampCursor()
+	}
+}
+
+// cursorToRow moves the cursor to the repo at path, reporting false when
+// the table doesn't show it.
+func (m *AppModel) cursorToRow(path string) bool {
+	for i, fr := range m.filteredRows() {
+		if fr.group == nil && fr.row.Path == path {
+			m.Cursor = i
+			return true
+		}
+	}
+	return false
+}
+
+func (m *AppModel) clampCursor() {
+	m.Cursor = max(min(m.Cursor, len(m.filteredRows())-1), 0)
+}
+
+// savePrefsCmd persists the dashboard prefs in the background.
+func savePrefsCmd(prefs util.Prefs) tea.Cmd {
+	prefs.Collapsed = append([]string(nil), prefs.Collapsed...)
+	return func() tea.Msg {
+		if err := prefs.Save(); err != nil {
+			return ActionDoneMsg{Text: fmt.Sprintf("save dashboard prefs: %s", err)}
+		}
+		return nil
+	}
+}
+
+// renderGroupHeader draws a section header row.
+func renderGroupHeader(g *rowGroup, selected bool) string {
+	cursor := " "
+	if selected {
+		cursor = styleCursor.Render("▸")
+	}
+	fold := "▾"
+	if g.Collapsed {
+		fold = "▸"
+	}
+	return cursor + " " + styleTableHead.Render(fmt.Sprintf("%s %s", fold, g.Label)) + styleDim.Render(fmt.Sprintf(" (%d)", g.Count))
+}
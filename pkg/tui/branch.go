@@ -0,0 +1,382 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// branchPickerRows is how many branches the picker lists at once.
+const branchPickerRows = 10
+
+// BranchModel holds the branch picker overlay (b).
+type BranchModel struct {
+	Active   bool
+	Input    textinput.Model
+	Targets  []RepoRow
+	Branches []branchChoice // every branch in any target, newest first
+	Cursor   int            // index into matches()
+	Loading  bool
+	Errors   []string // targets whose branches couldn't be listed
+
+	// Confirming asks whether to stash before switching dirty repos.
+	Confirming bool
+	choice     branchChoice
+}
+
+// branchChoice is one branch name across the picker's targets.
+type branchChoice struct {
+	Name    string
+	When    time.Time       // newest tip among the repos that have it
+	Repos   map[string]bool // target paths with the branch, local or remote
+	Local   map[string]bool // target paths with a local branch
+	Current map[string]bool // target paths that are on it
+}
+
+// branchMatch is a branch that matches the picker's query.
+type branchMatch struct {
+	branchChoice
+	score     int
+	positions []int
+}
+
+func newBranchInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "branch..."
+	input.CharLimit = 128
+	return input
+}
+
+// openBranches shows the picker for targets and starts listing their
+// branches.
+func (m *AppModel) openBranches(targets []RepoRow) tea.Cmd {
+	if len(targets) == 0 {
+		return nil
+	}
+	b := &m.Branches
+	b.Active = true
+	b.Targets = targets
+	b.Branches = nil
+	b.Errors = nil
+	b.Cursor = 0
+	b.Loading = true
+	b.Confirming = false
+	b.Input.SetValue("")
+	return tea.Batch(b.Input.Focus(), loadBranchesCmd(targets))
+}
+
+func (m *AppModel) closeBranches() {
+	m.Branches.Active = false
+	m.Branches.Confirming = false
+	m.Branches.Input.Blur()
+}
+
+// loadBranchesCmd lists every target's branches in parallel.
+func loadBranchesCmd(targets []RepoRow) tea.Cmd {
+	return func() tea.Msg {
+		msg := BranchesLoadedMsg{
+			Refs:   make(map[string][]util.BranchRef, len(targets)),
+			Errors: make(map[string]error),
+		}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, row := range targets {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				refs, err := util.BranchRefs(context.Background(), row.Path)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					msg.Errors[row.Path] = err
+					return
+				}
+				msg.Refs[row.Path] = refs
+			}()
+		}
+		wg.Wait()
+		return msg
+	}
+}
+
+// setBranches merges the targets' branches by name, newest first.
+func (b *BranchModel) setBranches(msg BranchesLoadedMsg) {
+	b.Loading = false
+	byName := make(map[string]*branchChoice)
+	var order []string
+	for _, row := range b.Targets {
+		if err, ok := msg.Errors[row.Path]; ok {
+			b.Errors = append(b.Errors, fmt.Sprintf("%s: %s", row.DisplayName, err))
+			continue
+		}
+		for _, ref := range msg.Refs[row.Path] {
+			c, ok := byName[ref.Name]
+			if !ok {
+				c = &branchChoice{
+					Name:    ref.Name,
+					Repos:   make(map[string]bool),
+					Local:   make(map[string]bool),
+					Current: make(map[string]bool),
+				}
+				byName[ref.Name] = c
+				order = append(order, ref.Name)
+			}
+			c.Repos[row.Path] = true
+			if ref.Remote == "" {
+				c.Local[row.Path] = true
+			}
+			if ref.Remote == "" && ref.Name == row.Status.Branch {
+				c.Current[row.Path] = true
+			}
+			if ref.When.After(c.When) {
+				c.When = ref.When
+			}
+		}
+	}
+	b.Branches = make([]branchChoice, len(order))
+	for i, name := range order {
+		b.Branches[i] = *byName[name]
+	}
+	sort.SliceStable(b.Branches, func(i, j int) bool {
+		return b.Branches[i].When.After(b.Branches[j].When)
+	})
+}
+
+// matches returns the branches matching the query, best match first; with
+// no query, newest first.
+func (b *BranchModel) matches() []branchMatch {
+	query := strings.TrimSpace(b.Input.Value())
+	var out []branchMatch
+	for _, c := range b.Branches {
+		score, positions, ok := util.FuzzyMatch(query, c.Name)
+		if ok {
+			out = append(out, branchMatch{branchChoice: c, score: score, positions: positions})
+		}
+	}
+	if query != "" {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	}
+	return out
+}
+
+// dirtyTargets returns the targets with the branch that have uncommitted
+// changes to tracked files. Untracked files alone don't count: they carry
+// over to the new branch.
+func (b *BranchModel) dirtyTargets(c branchChoice) []RepoRow {
+	var dirty []RepoRow
+	for _, row := range b.Targets {
+		s := row.Status
+		if c.Repos[row.Path] && !c.Current[row.Path] && s.Staged+s.Modified+s.Conflicts > 0 {
+			dirty = append(dirty, row)
+		}
+	}
+	return dirty
+}
+
+func (m AppModel) updateBranches(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.Branches
+	if b.Confirming {
+		switch msg.String() {
+		case "y", "enter":
+			return m, m.switchBranch(b.choice, true)
+		case "n":
+			return m, m.switchBranch(b.choice, false)
+		case "esc":
+			b.Confirming = false
+		}
+		return m, nil
+	}
+
+	matches := b.matches()
+	switch msg.String() {
+	case "esc":
+		m.closeBranches()
+		return m, nil
+	case "up", "ctrl+p", "ctrl+k":
+		if b.Cursor > 0 {
+			b.Cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if b.Cursor < len(matches)-1 {
+			b.Cursor++
+		}
+		return m, nil
+	case "enter":
+		if b.Cursor >= len(matches) {
+			return m, nil
+		}
+		choice := matches[b.Cursor].branchChoice
+		if len(b.dirtyTargets(choice)) > 0 {
+			b.Confirming = true
+			b.choice = choice
+			return m, nil
+		}
+		return m, m.switchBranch(choice, false)
+	}
+	var cmd tea.Cmd
+	b.Input, cmd = b.Input.Update(msg)
+	b.Cursor = 0
+	return m, cmd
+}
+
+// switchBranch checks out choice in every target that has it, stashing
+// uncommitted changes around the switch when stash is set.
+func (m *AppModel) switchBranch(choice branchChoice, stash bool) tea.Cmd {
+	targets := m.Branches.Targets
+	m.closeBranches()
+	var cmds []tea.Cmd
+	for _, row := range targets {
+		switch {
+		case choice.Current[row.Path]:
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("switch %s: already on %s", row.DisplayName, choice.Name))
+			continue
+		case !choice.Repos[row.Path]:
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("switch %s: no branch %s", row.DisplayName, choice.Name))
+			continue
+		}
+		if i := m.rowIndexByPath(row.Path); i >= 0 {
+			m.Rows[i].Action = "switching..."
+		}
+		cmds = append(cmds, gitActionCmd("switch", row, func(ctx context.Context) (string, error) {
+			return util.SwitchBranch(ctx, row.Path, choice.Name, stash)
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// renderBranches draws the branch picker overlay.
+func (m AppModel) renderBranches() string {
+	b := &m.Branches
+	title := "Switch branch in " + b.Targets[0].DisplayName
+	if len(b.Targets) > 1 {
+		title = fmt.Sprintf("Switch branch in %d repos", len(b.Targets))
+	}
+	lines := []string{styleHeader.Render(title), b.Input.View()}
+
+	if b.Confirming {
+		dirty := b.dirtyTargets(b.choice)
+		names := make([]string, len(dirty))
+		for i, r := range dirty {
+			names[i] = r.DisplayName
+		}
+		lines = append(lines, "",
+			ui.StyleWarning.Render(fmt.Sprintf("Uncommitted changes in %s.", truncate(strings.Join(names, ", "), 60))),
+			fmt.Sprintf("Stash them and restore them on %s?", b.choice.Name),
+			styleDim.Render("y:stash & restore  n:switch without stashing  esc:back"))
+		return renderOverlay(lines)
+	}
+
+	for _, e := range b.Errors {
+		lines = append(lines, ui.StyleError.Render(truncate(e, 80)))
+	}
+	matches := b.matches()
+	switch {
+	case b.Loading:
+		lines = append(lines, styleDim.Render("loading branches..."))
+	case len(matches) == 0:
+		lines = append(lines, styleDim.Render("no matching branches"))
+	}
+
+	start := 0
+	if b.Cursor >= branchPickerRows {
+		start = b.Cursor - branchPickerRows + 1
+	}
+	for i := start; i < len(matches) && i < start+branchPickerRows; i++ {
+		lines = append(lines, m.renderBranchChoice(matches[i], i == b.Cursor))
+	}
+	if len(matches) > branchPickerRows {
+		lines = append(lines, styleDim.Render(fmt.Sprintf("(%d/%d)", b.Cursor+1, len(matches))))
+	}
+	lines = append(lines, styleDim.Render("type to filter  ↑/↓:select  enter:switch  esc:cancel"))
+	return renderOverlay(lines)
+}
+
+// renderBranchChoice shows one branch with matched characters highlighted
+// and, for several targets, which of them have it.
+func (m AppModel) renderBranchChoice(c branchMatch, selected bool) string {
+	cursor := "  "
+	if selected {
+		cursor = styleCursor.Render("▸ ")
+	}
+	name := highlightMatches(c.Name, c.positions)
+	if pad := 32 - len([]rune(c.Name)); pad > 0 {
+		name += strings.Repeat(" ", pad)
+	}
+
+	b := &m.Branches
+	var where string
+	switch {
+	case len(b.Targets) == 1 && len(c.Current) > 0:
+		where = ui.StyleSuccess.Render("current")
+	case len(b.Targets) == 1 && len(c.Local) > 0:
+		where = styleDim.Render("local")
+	case len(b.Targets) == 1:
+		where = styleDim.Render("remote")
+	default:
+		var missing []string
+		for _, row := range b.Targets {
+			if !c.Repos[row.Path] {
+				missing = append(missing, row.DisplayName)
+			}
+		}
+		where = fmt.Sprintf("%d/%d repos", len(c.Repos), len(b.Targets))
+		if len(missing) > 0 {
+			where = ui.StyleWarning.Render(where) + styleDim.Render(" missing in "+truncate(strings.Join(missing, ", "), 40))
+		} else {
+			where = ui.StyleSuccess.Render(where)
+		}
+	}
+	age := ""
+	if !c.When.IsZero() {
+		age = styleDim.Render(fmt.Sprintf("%-8s", ui.Age(c.When)))
+	}
+	return cursor + name + " " + age + " " + where
+}
+
+// highlightMatches renders s with the runes at positions emphasized.
+func highlightMatches(s string, positions []int) string {
+	return highlightStyled(s, positions, lipgloss.NewStyle())
+}
+
+// highlightStyled renders s in base with the runes at positions
+// emphasized.
+func highlightStyled(s string, positions []int, base lipgloss.Style) string {
+	if len(positions) == 0 {
+		return base.Render(s)
+	}
+	hit := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		hit[p] = true
+	}
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && hit[j] == hit[i] {
+			j++
+		}
+		style := base
+		if hit[i] {
+			style = styleMatch
+		}
+		b.WriteString(style.Render(string(runes[i:j])))
+		i = j
+	}
+	return b.String()
+}
+
+// renderOverlay boxes an overlay's lines.
+func renderOverlay(lines []string) string {
+	return styleBox.Render(strings.Join(lines, "\n"))
+}
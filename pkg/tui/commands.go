@@ -3,14 +3,17 @@ package tui
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"gee/pkg/command"
+	"gee/pkg/discovery"
+	"gee/pkg/gitdir"
 	"gee/pkg/types"
 	"gee/pkg/ui"
 	"gee/pkg/util"
@@ -29,14 +32,13 @@ import (
 //  2. tea.Cmd blocks reading one value from that channel
 //  3. Update processes the msg and returns another tea.Cmd to drain the next value
 //  4. When channel closes -> StatusRefreshDoneMsg
-func refreshStatusCmd(repos []types.Repo, repoUtils *util.RepoUtils) (tea.Cmd, <-chan StatusResultMsg) {
+func refreshStatusCmd(repos []types.Repo, repoUtils *util.RepoUtils, store *util.StatusStore) (tea.Cmd, <-chan StatusResultMsg) {
 	if len(repos) == 0 {
 		ch := make(chan StatusResultMsg)
 		close(ch)
 		return waitForStatusResult(ch), ch
 	}
 
-	git := command.GitRepoOperation{}
 	ch := make(chan StatusResultMsg, len(repos))
 
 	pool := gogo.NewPool[struct{}](
@@ -46,27 +48,7 @@ func refreshStatusCmd(repos []types.Repo, repoUtils *util.RepoUtils) (tea.Cmd, <
 		func(ctx context.Context, i int) (struct{}, error) {
 			repo := repos[i]
 			fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
-			rc := &types.RunConfig{
-				StdOut: &bytes.Buffer{},
-				StdErr: &bytes.Buffer{},
-			}
-
-			msg := StatusResultMsg{Index: i, Name: repo.Name}
-
-			git.StatusPorcelain(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
-				if onFinish.Failed {
-					msg.Failed = true
-				} else {
-					summary := ui.ParsePorcelainV2(rc.StdOut.String())
-					if summary.Branch == "(detached)" {
-						summary.State, summary.Progress = ui.DetectGitState(fullPath)
-					}
-					summary.Stale = ui.CheckStaleness(fullPath, summary)
-					msg.Summary = summary
-				}
-			})
-
-			ch <- msg
+			ch <- repoStatus(repo, fullPath, store)
 			return struct{}{}, nil
 		},
 	)
@@ -95,39 +77,62 @@ func waitForStatusResult(ch <-chan StatusResultMsg) tea.Cmd {
 	}
 }
 
-// refreshSingleRepoStatusCmd refreshes status for a single newly-discovered repo.
-func refreshSingleRepoStatusCmd(repo types.Repo, index int, repoUtils *util.RepoUtils) tea.Cmd {
+// refreshSingleRepoStatusCmd refreshes status for a single repo.
+func refreshSingleRepoStatusCmd(repo types.Repo, repoUtils *util.RepoUtils, store *util.StatusStore) tea.Cmd {
 	return func() tea.Msg {
-		git := command.GitRepoOperation{}
 		fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
-		rc := &types.RunConfig{
-			StdOut: &bytes.Buffer{},
-			StdErr: &bytes.Buffer{},
-		}
-		msg := StatusResultMsg{Index: index, Name: repo.Name}
-		git.StatusPorcelain(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
-			if onFinish.Failed {
-				msg.Failed = true
-			} else {
-				summary := ui.ParsePorcelainV2(rc.StdOut.String())
-				if summary.Branch == "(detached)" {
-					summary.State, summary.Progress = ui.DetectGitState(fullPath)
-				}
-				summary.Stale = ui.CheckStaleness(fullPath, summary)
-				msg.Summary = summary
-			}
-		})
+		return repoStatus(repo, fullPath, store)
+	}
+}
+
+// repoStatus runs porcelain status for one repo and decorates the summary
+// with in-progress state and staleness. Bare repos have no working tree, so
+// git is skipped and only HEAD is reported. The result carries a snapshot
+// for the status store.
+func repoStatus(repo types.Repo, fullPath string, store *util.StatusStore) StatusResultMsg {
+	msg := StatusResultMsg{Path: fullPath, Name: repo.Name}
+	if gitdir.IsBare(fullPath) {
+		msg.Snapshot = store.Snapshot(fullPath, ui.BareSummary(fullPath))
+		msg.Summary = msg.Snapshot.Summary
 		return msg
 	}
+
+	git := command.GitRepoOperation{}
+	rc := &types.RunConfig{
+		StdOut: &bytes.Buffer{},
+		StdErr: &bytes.Buffer{},
+	}
+	git.StatusPorcelain(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
+		if onFinish.Failed {
+			msg.Failed = true
+			return
+		}
+		summary := ui.ParsePorcelainV2(rc.StdOut.String())
+		if summary.Branch == "(detached)" {
+			summary.State, summary.Progress = ui.DetectGitState(fullPath)
+		}
+		summary.Stale = ui.CheckStaleness(fullPath, summary)
+		msg.Summary = summary
+		msg.Snapshot = store.Snapshot(fullPath, summary)
+	})
+	return msg
+}
+
+// saveStatusCmd persists the status store in the background.
+func saveStatusCmd(store *util.StatusStore) tea.Cmd {
+	return func() tea.Msg {
+		store.Save()
+		return nil
+	}
 }
 
 // scanLocalReposCmd starts the background filesystem scanner and bridges
-// results into the bubbletea Update loop via a channel.
-func scanLocalReposCmd(cache *util.RepoCache) (tea.Cmd, <-chan RepoDiscoveredMsg) {
-	scanCh := util.ScanForRepos(context.Background(), util.ScannerConfig{
-		Root:     "", // defaults to ~
-		MaxDepth: 5,
-	})
+// results into the bubbletea Update loop via a channel. The scan is
+// incremental (see util.ScanIndex); once it completes, cache entries whose
+// paths vanished are flagged as missing.
+func scanLocalReposCmd(cache *util.RepoCache, cfg util.ScannerConfig) (tea.Cmd, <-chan RepoDiscoveredMsg) {
+	cfg.Index = util.LoadScanIndex(util.DefaultScanIndexPath(), cfg)
+	scanCh := util.ScanForRepos(context.Background(), cfg)
 
 	outCh := make(chan RepoDiscoveredMsg, 32)
 	go func() {
@@ -137,6 +142,7 @@ func scanLocalReposCmd(cache *util.RepoCache) (tea.Cmd, <-chan RepoDiscoveredMsg
 				Path:         result.Path,
 				Remote:       result.Remote,
 				Pinned:       false,
+				Kind:         result.Kind,
 				DiscoveredAt: time.Now(),
 			}
 			if isNew := cache.Add(cached); isNew {
@@ -144,9 +150,11 @@ func scanLocalReposCmd(cache *util.RepoCache) (tea.Cmd, <-chan RepoDiscoveredMsg
 					Name:   result.Name,
 					Path:   result.Path,
 					Remote: result.Remote,
+					Kind:   result.Kind,
 				}
 			}
 		}
+		cache.MarkMissing()
 		cache.Save()
 		close(outCh)
 	}()
@@ -166,187 +174,368 @@ func waitForDiscoveredRepo(ch <-chan RepoDiscoveredMsg) tea.Cmd {
 	}
 }
 
-// pullRepoCmd pulls a single repo and returns the result.
-func pullRepoCmd(repo types.Repo, index int, repoUtils *util.RepoUtils) tea.Cmd {
+// remoteRepoCmd pulls, fetches or pushes (per gitCommand) a single repo
+// and returns the result. Output is also streamed to out as it arrives.
+func remoteRepoCmd(op, run int, gitCommand string, repo types.Repo, repoUtils *util.RepoUtils, out io.Writer) tea.Cmd {
 	return func() tea.Msg {
 		git := command.GitRepoOperation{}
 		fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
 		rc := &types.RunConfig{
 			StdOut: &bytes.Buffer{},
 			StdErr: &bytes.Buffer{},
+			Output: out,
 		}
 
-		msg := PullResultMsg{Index: index, Name: repo.Name}
-		git.Pull(repo.Name, fullPath, rc, func(onFinish *types.CommandOnFinish) {
+		start := time.Now()
+		msg := PullResultMsg{Op: op, Run: run, Command: gitCommand, Name: repo.Name, Path: fullPath}
+		onFinish := func(onFinish *types.CommandOnFinish) {
 			msg.Stdout = rc.StdOut.String()
 			msg.Stderr = rc.StdErr.String()
 			msg.Failed = onFinish.Failed
-		})
+		}
+		switch gitCommand {
+		case "fetch":
+			git.Fetch(repo.Name, fullPath, rc, onFinish)
+		case "push":
+			git.Push(repo.Name, fullPath, rc, onFinish)
+		default:
+			git.Pull(repo.Name, fullPath, rc, onFinish)
+		}
+		msg.Duration = time.Since(start)
 		return msg
 	}
 }
 
-// execRepoCmd runs an arbitrary shell command in a single repo directory.
-func execRepoCmd(repo types.Repo, index int, userCmd string, repoUtils *util.RepoUtils) tea.Cmd {
+// execRepoCmd runs an arbitrary shell command in a single repo directory,
+// streaming its output to out as well.
+func execRepoCmd(op, run int, repo types.Repo, userCmd string, repoUtils *util.RepoUtils, out io.Writer) tea.Cmd {
 	return func() tea.Msg {
 		fullPath := repoUtils.FullPathWithRepo(repo.Path, repo.Name)
+		start := time.Now()
 		var stdout, stderr bytes.Buffer
 		sh := exec.Command("sh", "-c", userCmd)
 		sh.Dir = fullPath
-		sh.Stdout = &stdout
-		sh.Stderr = &stderr
+		sh.Stdout = io.MultiWriter(&stdout, out)
+		sh.Stderr = io.MultiWriter(&stderr, out)
 		err := sh.Run()
 
 		return ExecResultMsg{
-			Index:  index,
-			Name:   repo.Name,
-			Stdout: stdout.String(),
-			Stderr: stderr.String(),
-			Failed: err != nil,
+			Op:       op,
+			Run:      run,
+			Name:     repo.Name,
+			Path:     fullPath,
+			Stdout:   stdout.String(),
+			Stderr:   stderr.String(),
+			Failed:   err != nil,
+			Duration: time.Since(start),
 		}
 	}
 }
 
-// tickCmd returns a tea.Cmd that fires a TickMsg after the refresh interval.
-func tickCmd() tea.Cmd {
-	return tea.Tick(5*time.Second, func(t time.Time) tea.Msg {
-		return TickMsg{}
-	})
+// appendHistoryCmd writes a finished batch to the history log.
+func appendHistoryCmd(entry util.HistoryEntry) tea.Cmd {
+	return func() tea.Msg {
+		id, err := util.AppendHistory(entry)
+		return HistoryRecordedMsg{ID: id, Command: entry.Command, Label: entry.Label(), Failures: len(entry.Failures()), Err: err}
+	}
 }
 
-// discoverRemoteReposCmd calls gh or glab to list remote repos.
-func discoverRemoteReposCmd(provider string) tea.Cmd {
+// openWebCmd opens the repo's page on its hosting provider.
+func openWebCmd(row RepoRow) tea.Cmd {
 	return func() tea.Msg {
-		switch provider {
-		case "gh":
-			return discoverGitHub()
-		case "glab":
-			return discoverGitLab()
-		default:
-			return DiscoveryResultMsg{Error: fmt.Errorf("no discovery provider available")}
+		target, err := util.RepoWebURL(row.Path, row.Repo.Remote, util.WebHome)
+		if err == nil {
+			err = util.OpenURL(target)
+		}
+		if err != nil {
+			return ActionDoneMsg{Text: fmt.Sprintf("open %s: %s", row.DisplayName, err)}
 		}
+		return ActionDoneMsg{Text: fmt.Sprintf("opened %s", target)}
 	}
 }
 
-func discoverGitHub() tea.Msg {
-	cmd := exec.Command("gh", "repo", "list",
-		"--json", "nameWithOwner,description,sshUrl,isPrivate",
-		"--limit", "100")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &bytes.Buffer{}
-	if err := cmd.Run(); err != nil {
-		return DiscoveryResultMsg{Error: fmt.Errorf("gh repo list: %w", err)}
+// copyCmd copies one of the repo's path, remote URL or web URL (what is
+// "path", "remote" or "web") to the clipboard.
+func copyCmd(row RepoRow, what string) tea.Cmd {
+	return func() tea.Msg {
+		var text string
+		switch what {
+		case "path":
+			text = row.Path
+		case "remote":
+			text = row.Repo.Remote
+			if text == "" {
+				return ActionDoneMsg{Text: fmt.Sprintf("copy: %s has no remote", row.DisplayName)}
+			}
+		case "web":
+			target, err := util.RepoWebURL(row.Path, row.Repo.Remote, util.WebHome)
+			if err != nil {
+				return ActionDoneMsg{Text: fmt.Sprintf("copy: %s", err)}
+			}
+			text = target
+		}
+		return copyText(text, fmt.Sprintf("copied %s", text))
 	}
+}
 
-	var raw []struct {
-		NameWithOwner string `json:"nameWithOwner"`
-		Description   string `json:"description"`
-		SSHURL        string `json:"sshUrl"`
-		IsPrivate     bool   `json:"isPrivate"`
-	}
-	if err := json.Unmarshal(out.Bytes(), &raw); err != nil {
-		return DiscoveryResultMsg{Error: fmt.Errorf("parse gh output: %w", err)}
+// copyText puts text on the clipboard, reporting done when it's there.
+// Without a local clipboard it goes to the terminal as OSC52, which Update
+// has to write (ClipboardMsg).
+func copyText(text, done string) tea.Msg {
+	if util.CopyToClipboard(text) {
+		return ActionDoneMsg{Text: done}
 	}
+	return ClipboardMsg{Seq: util.ClipboardSequence(text), Done: done}
+}
 
-	repos := make([]RemoteRepo, len(raw))
-	for i, r := range raw {
-		repos[i] = RemoteRepo{
-			FullName:    r.NameWithOwner,
-			Description: r.Description,
-			CloneURL:    r.SSHURL,
-			Private:     r.IsPrivate,
+// writeTerminalCmd writes seq to the terminal with the renderer stopped,
+// so it can't land in the middle of a frame.
+func writeTerminalCmd(seq, done string) tea.Cmd {
+	return tea.Exec(&terminalWrite{seq: seq}, func(err error) tea.Msg {
+		if err != nil {
+			return ClipboardDoneMsg{Text: fmt.Sprintf("copy: %s", err)}
 		}
-	}
-	return DiscoveryResultMsg{Repos: repos}
+		return ClipboardDoneMsg{Text: done}
+	})
 }
 
-func discoverGitLab() tea.Msg {
-	cmd := exec.Command("glab", "repo", "list", "-O", "json")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = &bytes.Buffer{}
-	if err := cmd.Run(); err != nil {
-		return DiscoveryResultMsg{Error: fmt.Errorf("glab repo list: %w", err)}
-	}
+// terminalWrite is a tea.ExecCommand that writes a sequence to the
+// terminal instead of running a program.
+type terminalWrite struct {
+	seq string
+	out io.Writer
+}
+
+func (t *terminalWrite) Run() error {
+	_, err := io.WriteString(t.out, t.seq)
+	return err
+}
 
-	var raw []struct {
-		PathWithNamespace string `json:"path_with_namespace"`
-		Description       string `json:"description"`
-		SSHURLToRepo      string `json:"ssh_url_to_repo"`
-		Visibility        string `json:"visibility"`
+func (t *terminalWrite) SetStdin(io.Reader)    {}
+func (t *terminalWrite) SetStdout(w io.Writer) { t.out = w }
+func (t *terminalWrite) SetStderr(io.Writer)   {}
+
+const (
+	// pollInterval is the full-refresh period when no watcher is running.
+	pollInterval = 5 * time.Second
+	// fallbackInterval is the full-refresh period when the watcher is
+	// running. Watches aren't recursive, so this catches edits deep inside
+	// working trees that git hasn't noticed yet.
+	fallbackInterval = 60 * time.Second
+	// watchDebounce coalesces bursts of filesystem events per repo.
+	watchDebounce = 300 * time.Millisecond
+)
+
+// tickCmd returns a tea.Cmd that fires a TickMsg after the refresh interval.
+func tickCmd(interval time.Duration) tea.Cmd {
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return TickMsg{}
+	})
+}
+
+// startWatcherCmd starts the filesystem watcher on the repos at paths off
+// the UI goroutine: registering every repo's refs takes a while when there
+// are many. Without fsnotify the dashboard keeps polling.
+func startWatcherCmd(paths []string) tea.Cmd {
+	return func() tea.Msg {
+		w, err := util.NewRepoWatcher(util.DefaultCachePath(), watchDebounce)
+		if err != nil {
+			return initWatcherMsg{}
+		}
+		w.Sync(paths)
+		return initWatcherMsg{w: w}
 	}
-	if err := json.Unmarshal(out.Bytes(), &raw); err != nil {
-		return DiscoveryResultMsg{Error: fmt.Errorf("parse glab output: %w", err)}
+}
+
+// waitForWatchEvent returns a tea.Cmd that reads one batch from the watcher.
+// Update calls it again after each batch to keep listening.
+func waitForWatchEvent(ch <-chan util.WatchEvent) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-ch
+		if !ok {
+			return WatchClosedMsg{}
+		}
+		return RepoChangedMsg{Paths: ev.Repos, CacheChanged: ev.CacheChanged}
 	}
+}
 
-	repos := make([]RemoteRepo, len(raw))
-	for i, r := range raw {
-		repos[i] = RemoteRepo{
-			FullName:    r.PathWithNamespace,
-			Description: r.Description,
-			CloneURL:    r.SSHURLToRepo,
-			Private:     r.Visibility == "private",
+// discoverRemoteReposCmd lists src's repos, paging through all of them,
+// or serves the listing from the cache while it is younger than ttl.
+func discoverRemoteReposCmd(cache *discovery.Cache, src discovery.Source, ttl time.Duration, force bool) tea.Cmd {
+	return func() tea.Msg {
+		listing, cached, err := cache.CachedList(context.Background(), src, ttl, force)
+		return DiscoveryResultMsg{
+			Source:    src,
+			Repos:     listing.Repos,
+			FetchedAt: listing.FetchedAt,
+			Cached:    cached,
+			Truncated: listing.Truncated,
+			Error:     err,
 		}
 	}
-	return DiscoveryResultMsg{Repos: repos}
 }
 
-// cloneBatchCmd clones a set of remote repos using gogo, adds them to the
-// cache as pinned, and returns a CloneBatchDoneMsg.
-func cloneBatchCmd(toClone []RemoteRepo, cache *util.RepoCache, cloneDir string, repoUtils *util.RepoUtils) tea.Cmd {
+// discoverOwnersCmd lists the orgs or groups the user belongs to on a
+// provider.
+func discoverOwnersCmd(cache *discovery.Cache, provider discovery.Provider, ttl time.Duration, force bool) tea.Cmd {
 	return func() tea.Msg {
-		git := command.GitRepoOperation{}
-		succeeded := 0
-		failed := 0
-
-		pool := gogo.NewPool[struct{}](
-			context.Background(),
-			len(toClone),
-			len(toClone),
-			func(ctx context.Context, i int) (struct{}, error) {
-				remote := toClone[i]
-				rc := &types.RunConfig{
-					StdOut: &bytes.Buffer{},
-					StdErr: &bytes.Buffer{},
-				}
+		listing, err := cache.CachedOwners(context.Background(), provider, ttl, force)
+		return DiscoveryOwnersMsg{Provider: provider, Owners: listing.Owners, Truncated: listing.Truncated, Error: err}
+	}
+}
 
-				// Extract repo name from "owner/name" -> "name"
-				parts := strings.Split(remote.FullName, "/")
-				repoName := parts[len(parts)-1]
+// cloneBatchCmd clones targets into cloneDir, a few at a time, adding each
+// success to the cache as pinned. Every job reports its progress on the
+// returned channel, which is closed once the batch is done; counting
+// happens in Update, which sees every message.
+func cloneBatchCmd(targets []cloneTarget, opts util.CloneOptions, cache *util.RepoCache, cloneDir string) (tea.Cmd, <-chan CloneProgressMsg) {
+	ch := make(chan CloneProgressMsg, 64)
+	git := command.GitRepoOperation{}
+	flags := opts.Args()
+	paths := clonePaths(targets, cloneDir)
 
-				var cloneFailed bool
-				git.Clone(repoName, remote.CloneURL, cloneDir, rc, func(onFinish *types.CommandOnFinish) {
-					cloneFailed = onFinish.Failed
-					if cloneFailed && strings.Contains(rc.StdErr.String(), "already exists") {
-						cloneFailed = false
-					}
-				})
+	pool := gogo.NewPool[struct{}](
+		context.Background(),
+		opts.Workers(),
+		len(targets),
+		func(ctx context.Context, i int) (struct{}, error) {
+			target := targets[i]
+			url := target.URL
+			if opts.Protocol != "" {
+				url = discovery.PreferredCloneURL(target.Repo, opts.Protocol)
+			}
+
+			repoPath := paths[i]
+			repoName := filepath.Base(repoPath)
 
-				repoPath := filepath.Join(cloneDir, repoName)
-				if cloneFailed {
-					failed++
-				} else {
-					succeeded++
-					cache.Add(util.CachedRepo{
-						Name:         repoName,
-						Path:         repoPath,
-						Remote:       remote.CloneURL,
-						Pinned:       true,
-						DiscoveredAt: time.Now(),
-					})
-					cache.Pin(repoPath)
+			ch <- CloneProgressMsg{Index: i, State: cloneRunning, Path: repoPath}
+			rc := &types.RunConfig{
+				StdOut: &bytes.Buffer{},
+				StdErr: &bytes.Buffer{},
+			}
+			progress := &cloneProgressWriter{index: i, ch: ch}
+
+			result := CloneProgressMsg{Index: i, State: cloneDone, Path: repoPath}
+			git.CloneTo(repoName, url, repoPath, flags, progress, rc, func(onFinish *types.CommandOnFinish) {
+				stderr := rc.StdErr.String()
+				switch {
+				case onFinish.Failed && strings.Contains(stderr, "already exists"):
+					// Only a clone of the same repo counts as done.
+					if reason := existingCloneMismatch(repoPath, target.Repo); reason != "" {
+						result.State, result.Reason = cloneFailed, reason
+					} else {
+						result.State = cloneExists
+					}
+				case onFinish.Failed:
+					result.State = cloneFailed
+					result.Reason = util.CloneFailureReason(stderr)
 				}
-				return struct{}{}, nil
-			},
-		)
+			})
+
+			if result.State != cloneFailed {
+				cache.Add(util.CachedRepo{
+					Name:         repoName,
+					Path:         repoPath,
+					Remote:       url,
+					Pinned:       true,
+					DiscoveredAt: time.Now(),
+				})
+				cache.Pin(repoPath)
+			}
+			ch <- result
+			return struct{}{}, nil
+		},
+	)
 
+	go func() {
 		pool.Wait()
+		cache.Save()
+		close(ch)
+	}()
+
+	return waitForCloneProgress(ch), ch
+}
+
+// clonePaths picks where each target is cloned: cloneDir/<name>, or
+// cloneDir/<owner>/<name> when the batch has several repos of that name
+// (acme/api and globex/api), so that no two land in one directory.
+func clonePaths(targets []cloneTarget, cloneDir string) []string {
+	named := make(map[string]int)
+	for _, t := range targets {
+		named[strings.ToLower(path.Base(t.Repo.FullName))]++
+	}
+	paths := make([]string, len(targets))
+	for i, t := range targets {
+		name := path.Base(t.Repo.FullName)
+		if named[strings.ToLower(name)] > 1 {
+			name = filepath.FromSlash(t.Repo.FullName)
+		}
+		paths[i] = filepath.Join(cloneDir, name)
+	}
+	return paths
+}
 
-		if succeeded > 0 {
-			cache.Save()
+// existingCloneMismatch says why the directory a clone found in its way
+// isn't a clone of repo, or returns "" when its origin is repo.
+func existingCloneMismatch(dir string, repo discovery.Repo) string {
+	if _, ok := gitdir.Resolve(dir); !ok {
+		return fmt.Sprintf("%s already exists and isn't a git repo", tildePath(dir))
+	}
+	out, err := exec.Command("git", "-C", dir, "config", "--get", "remote.origin.url").Output()
+	origin := strings.TrimSpace(string(out))
+	if err != nil || origin == "" {
+		return fmt.Sprintf("%s already exists without an origin", tildePath(dir))
+	}
+	if key := util.RemoteKey(origin); key != "" {
+		for _, u := range []string{repo.CloneURL, repo.HTTPSURL} {
+			if util.RemoteKey(u) == key {
+				return ""
+			}
 		}
+	}
+	return fmt.Sprintf("%s already exists, cloned from %s", tildePath(dir), origin)
+}
+
+// waitForCloneProgress reads one CloneProgressMsg, returning
+// CloneBatchDoneMsg once the channel is closed.
+func waitForCloneProgress(ch <-chan CloneProgressMsg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return CloneBatchDoneMsg{}
+		}
+		return msg
+	}
+}
+
+// cloneProgressWriter turns `git clone --progress` output, whose updates
+// are separated by carriage returns, into CloneProgressMsgs. It sends one
+// only when the phase or percentage changes.
+type cloneProgressWriter struct {
+	index   int
+	ch      chan<- CloneProgressMsg
+	line    []byte
+	phase   string
+	percent int
+}
+
+func (w *cloneProgressWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if c == '\r' || c == '\n' {
+			w.flush()
+			continue
+		}
+		w.line = append(w.line, c)
+	}
+	return len(p), nil
+}
 
-		return CloneBatchDoneMsg{Succeeded: succeeded, Failed: failed}
+func (w *cloneProgressWriter) flush() {
+	phase, percent, ok := util.ParseCloneProgress(string(w.line))
+	w.line = w.line[:0]
+	if !ok || (phase == w.phase && percent == w.percent) {
+		return
 	}
+	w.phase, w.percent = phase, percent
+	w.ch <- CloneProgressMsg{Index: w.index, State: cloneRunning, Phase: phase, Percent: percent}
 }
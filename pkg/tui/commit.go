@@ -0,0 +1,168 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// CommitModel holds the commit message overlay (c).
+type CommitModel struct {
+	Active  bool
+	Input   textarea.Model
+	Amend   bool
+	Targets []RepoRow // repos the message will be committed in
+	Notice  string    // why the last submit was refused
+
+	draft string // message of the last submit, restored if it failed
+}
+
+func newCommitInput() textarea.Model {
+	input := textarea.New()
+	input.Placeholder = "commit message..."
+	input.ShowLineNumbers = false
+	input.CharLimit = 0
+	input.SetHeight(5)
+	return input
+}
+
+// openCommit shows the commit overlay for targets.
+func (m *AppModel) openCommit(targets []RepoRow) tea.Cmd {
+	if len(targets) == 0 {
+		return nil
+	}
+	m.Commit.Active = true
+	m.Commit.Amend = false
+	m.Commit.Notice = ""
+	m.Commit.Targets = targets
+	m.Commit.Input.SetWidth(min(max(m.Width-6, 20), 80))
+	m.Commit.Input.SetValue(m.Commit.draft)
+	return m.Commit.Input.Focus()
+}
+
+func (m *AppModel) closeCommit() {
+	m.Commit.Active = false
+	m.Commit.Targets = nil
+	m.Commit.Input.Blur()
+}
+
+func (m AppModel) updateCommit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.Commit
+	switch msg.String() {
+	case "esc":
+		c.draft = c.Input.Value()
+		m.closeCommit()
+		return m, nil
+	case "ctrl+r":
+		c.Amend = !c.Amend
+		c.Notice = ""
+		// Start from the old message, as git commit --amend would.
+		if c.Amend && strings.TrimSpace(c.Input.Value()) == "" {
+			return m, lastCommitMessageCmd(c.Targets[0].Path)
+		}
+		return m, nil
+	case "ctrl+s":
+		message := strings.TrimSpace(c.Input.Value())
+		if message == "" && !c.Amend {
+			c.Notice = "empty message"
+			return m, nil
+		}
+		c.draft = c.Input.Value()
+		amend := c.Amend
+		targets := c.Targets
+		m.closeCommit()
+		var cmds []tea.Cmd
+		for _, row := range targets {
+			if i := m.rowIndexByPath(row.Path); i >= 0 {
+				m.Rows[i].Action = "committing..."
+			}
+			verb := "commit"
+			if amend {
+				verb = "amend"
+			}
+			cmds = append(cmds, gitActionCmd(verb, row, func(ctx context.Context) (string, error) {
+				return util.Commit(ctx, row.Path, message, amend)
+			}))
+		}
+		return m, tea.Batch(cmds...)
+	}
+	var cmd tea.Cmd
+	c.Input, cmd = c.Input.Update(msg)
+	return m, cmd
+}
+
+// stageCmds stages (or with unstage, unstages) paths in every target repo:
+// everything when paths is empty.
+func (m *AppModel) stageCmds(targets []RepoRow, unstage bool, paths ...string) tea.Cmd {
+	verb, action := "stage", "staging..."
+	if unstage {
+		verb, action = "unstage", "unstaging..."
+	}
+	var cmds []tea.Cmd
+	for _, row := range targets {
+		if i := m.rowIndexByPath(row.Path); i >= 0 {
+			m.Rows[i].Action = action
+		}
+		cmds = append(cmds, gitActionCmd(verb, row, func(ctx context.Context) (string, error) {
+			var err error
+			if unstage {
+				err = util.UnstagePaths(ctx, row.Path, paths...)
+			} else {
+				err = util.StagePaths(ctx, row.Path, paths...)
+			}
+			what := "all changes"
+			if len(paths) > 0 {
+				what = strings.Join(paths, ", ")
+			}
+			return what, err
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// gitActionCmd runs a quick git write (stage, unstage, commit) in one repo.
+func gitActionCmd(verb string, row RepoRow, run func(ctx context.Context) (string, error)) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		out, err := run(context.Background())
+		return GitActionMsg{Verb: verb, Name: row.DisplayName, Path: row.Path, Output: out, Err: err, Duration: time.Since(start)}
+	}
+}
+
+// lastCommitMessageCmd reads HEAD's message to prefill an amend.
+func lastCommitMessageCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		message, _ := util.LastCommitMessage(context.Background(), path)
+		return CommitMessageMsg{Message: message}
+	}
+}
+
+// renderCommit draws the commit overlay.
+func (m AppModel) renderCommit() string {
+	c := &m.Commit
+	title := "Commit in " + c.Targets[0].DisplayName
+	if len(c.Targets) > 1 {
+		names := make([]string, len(c.Targets))
+		for i, r := range c.Targets {
+			names[i] = r.DisplayName
+		}
+		title = fmt.Sprintf("Commit in %d repos: %s", len(c.Targets), truncate(strings.Join(names, ", "), 60))
+	}
+	lines := []string{styleHeader.Render(title)}
+	if c.Amend {
+		lines[0] += "  " + styleAction.Render("amend")
+	}
+	lines = append(lines, c.Input.View())
+	if c.Notice != "" {
+		lines = append(lines, ui.StyleError.Render(c.Notice))
+	}
+	lines = append(lines, styleDim.Render("ctrl+s:commit  ctrl+r:amend  esc:cancel"))
+	return renderOverlay(lines)
+}
@@ -0,0 +1,164 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmation is a yes/no question asked before unpinning, forgetting or
+// hiding repos (x, X, H).
+type confirmation struct {
+	Title   string // "Forget 3 repos?"
+	Note    string // what happens, dimmed under the names
+	Targets []RepoRow
+	run     func(m *AppModel, targets []RepoRow)
+}
+
+// confirmRows lists this many of the targets; the rest are counted.
+const confirmRows = 8
+
+// ask opens a confirmation for verb ("Unpin", "Forget"...) on targets,
+// running run on them if the user says yes.
+func (m *AppModel) ask(verb, note string, targets []RepoRow, run func(m *AppModel, targets []RepoRow)) {
+	if len(targets) == 0 {
+		return
+	}
+	title := fmt.Sprintf("%s %s?", verb, targets[0].DisplayName)
+	if len(targets) > 1 {
+		title = fmt.Sprintf("%s %d repos?", verb, len(targets))
+	}
+	m.Confirm = &confirmation{Title: title, Note: note, Targets: targets, run: run}
+}
+
+func (m AppModel) updateConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.Confirm
+	switch msg.String() {
+	case "y", "Y", "enter":
+		m.Confirm = nil
+		c.run(&m, c.Targets)
+		m.clampCursor()
+		return m, m.syncDetail()
+	case "n", "N", "esc", "q":
+		m.Confirm = nil
+	}
+	return m, nil
+}
+
+// renderConfirm draws the confirmation overlay.
+func (m AppModel) renderConfirm() string {
+	c := m.Confirm
+	lines := []string{styleHeader.Render(c.Title)}
+	if len(c.Targets) > 1 {
+		for i, r := range c.Targets {
+			if i == confirmRows {
+				lines = append(lines, styleDim.Render(fmt.Sprintf("  and %d more", len(c.Targets)-confirmRows)))
+				break
+			}
+			lines = append(lines, "  "+r.DisplayName)
+		}
+	}
+	if c.Note != "" {
+		lines = append(lines, styleDim.Render(c.Note))
+	}
+	lines = append(lines, "", styleDim.Render("y:yes  n/esc:no"))
+	return renderOverlay(lines)
+}
+
+// confirmUnpin asks to unpin the pinned repos among the targets.
+func (m *AppModel) confirmUnpin() {
+	var pinned []RepoRow
+	for _, r := range m.managedRows() {
+		if r.Pinned {
+			pinned = append(pinned, r)
+		}
+	}
+	if len(pinned) == 0 {
+		m.ActionLog = append(m.ActionLog, "unpin: nothing pinned")
+		return
+	}
+	m.ask("Unpin", "Unpinned repos stay on the dashboard as discovered ones.", pinned, func(m *AppModel, targets []RepoRow) {
+		for _, r := range targets {
+			m.Cache.Unpin(r.Path)
+			if i := m.rowIndexByPath(r.Path); i >= 0 {
+				m.Rows[i].Pinned = false
+			}
+		}
+		m.logOnRepos("unpinned", targets)
+		m.Cache.Save()
+	})
+}
+
+// confirmForget asks to remove the targets from the cache.
+func (m *AppModel) confirmForget() {
+	m.ask("Forget", "Removed from the cache; a later scan finds them again unless hidden.", m.managedRows(), func(m *AppModel, targets []RepoRow) {
+		for _, r := range targets {
+			m.Cache.Remove(r.Path)
+			delete(m.Marked, r.Path)
+		}
+		m.logOnRepos("forgot", targets)
+		m.Cache.Save()
+		m.reloadCache()
+	})
+}
+
+// confirmHide asks to hide the targets or, when they all are hidden
+// already, to restore them.
+func (m *AppModel) confirmHide() {
+	targets := m.managedRows()
+	restore := len(targets) > 0
+	for _, r := range targets {
+		restore = restore && r.Hidden
+	}
+	verb, note := "Hide", "Hidden repos are unpinned and left out of the dashboard, gee --all and\nscans until restored."
+	if restore {
+		verb, note = "Restore", ""
+	}
+	m.ask(verb, note, targets, func(m *AppModel, targets []RepoRow) {
+		for _, r := range targets {
+			m.Cache.SetHidden(r.Path, !restore)
+			if i := m.rowIndexByPath(r.Path); i >= 0 {
+				m.Rows[i].Hidden = !restore
+				m.Rows[i].Pinned = m.Rows[i].Pinned && restore
+			}
+			if !restore && !m.ShowHidden {
+				delete(m.Marked, r.Path)
+			}
+		}
+		if restore {
+			m.logOnRepos("restored", targets)
+		} else {
+			m.logOnRepos("hid", targets)
+		}
+		m.Cache.Save()
+	})
+}
+
+// toggleShowHidden lists or leaves out the hidden repos.
+func (m *AppModel) toggleShowHidden() {
+	m.ShowHidden = !m.ShowHidden
+	m.RangeAnchor = -1
+	m.clampCursor()
+	if m.ShowHidden && m.hiddenCount() == 0 {
+		m.ActionLog = append(m.ActionLog, "no hidden repos")
+	}
+}
+
+func (m *AppModel) hiddenCount() int {
+	n := 0
+	for _, r := range m.Rows {
+		if r.Hidden {
+			n++
+		}
+	}
+	return n
+}
+
+// logOnRepos records "<verb> name", or "<verb> N repos", in the action log.
+func (m *AppModel) logOnRepos(verb string, targets []RepoRow) {
+	if len(targets) == 1 {
+		m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s %s", verb, targets[0].DisplayName))
+		return
+	}
+	m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s %d repos", verb, len(targets)))
+}
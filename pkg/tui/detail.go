@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"charm.land/lipgloss/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// detailCommits is how many recent commits the detail pane lists.
+	detailCommits = 10
+	// detailSplitWidth is the terminal width from which the detail pane is
+	// shown beside the table rather than below it, and open at startup.
+	detailSplitWidth = 150
+)
+
+// DetailModel holds the detail pane for the selected repo.
+type DetailModel struct {
+	Visible bool
+	Path    string // repo the pane shows or is loading
+	Detail  util.RepoDetail
+	Loading bool
+	Err     error
+	sized   bool // the first WindowSizeMsg has been seen
+}
+
+// selectedRow returns the row under the cursor; false on a section header.
+func (m *AppModel) selectedRow() (RepoRow, bool) {
+	filtered := m.filteredRows()
+	if m.Cursor < 0 || m.Cursor >= len(filtered) || filtered[m.Cursor].group != nil {
+		return RepoRow{}, false
+	}
+	return filtered[m.Cursor].row, true
+}
+
+// syncDetail starts loading the selected repo's detail when the pane is
+// visible and the selection moved to a repo it doesn't show yet.
+func (m *AppModel) syncDetail() tea.Cmd {
+	if !m.Detail.Visible {
+		return nil
+	}
+	row, ok := m.selectedRow()
+	if !ok || row.Path == m.Detail.Path {
+		return nil
+	}
+	m.Detail.Path = row.Path
+	m.Detail.Detail = util.RepoDetail{}
+	m.Detail.Err = nil
+	m.Detail.Loading = true
+	return loadDetailCmd(row.Path)
+}
+
+// reloadDetail refreshes the pane when path, the repo it shows, changed.
+// The previous data stays on screen until the new data arrives.
+func (m *AppModel) reloadDetail(path string) tea.Cmd {
+	if !m.Detail.Visible || path != m.Detail.Path || m.Detail.Loading {
+		return nil
+	}
+	m.Detail.Loading = true
+	return loadDetailCmd(path)
+}
+
+// loadDetailCmd gathers one repo's detail in the background.
+func loadDetailCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		detail, err := util.LoadRepoDetail(context.Background(), path, detailCommits)
+		return RepoDetailMsg{Path: path, Detail: detail, Err: err}
+	}
+}
+
+// renderDetail draws the detail pane in a box width columns wide and at
+// most height lines tall.
+func (m AppModel) renderDetail(width, height int) string {
+	d := m.Detail
+	inner := width - 4
+	if inner < 20 {
+		inner = 20
+	}
+
+	var lines []string
+	title := util.DisplayName(nil, d.Path)
+	if row, ok := m.selectedRow(); ok && row.Path == d.Path {
+		title = row.DisplayName
+	}
+	heading := styleHeader.Render(title)
+	if d.Loading {
+		heading += styleDim.Render("  loading...")
+	}
+	lines = append(lines, heading)
+
+	section := func(name string, count int) {
+		lines = append(lines, "", styleTableHead.Render(fmt.Sprintf("%s (%d)", name, count)))
+	}
+
+	if d.Err != nil {
+		lines = append(lines, "", ui.StyleError.Render(d.Err.Error()))
+	}
+	det := d.Detail
+	if det.Path == d.Path && d.Err == nil {
+		if det.State != "" {
+			state := det.State
+			if det.Progress != "" {
+				state += " " + det.Progress
+			}
+			lines = append(lines, ui.StyleWarning.Render(state+" in progress"))
+		}
+
+		section("Changes", len(det.Files))
+		for _, f := range det.Files {
+			lines = append(lines, "  "+renderXY(f)+" "+renderChangePath(f))
+		}
+
+		section("Commits", len(det.Commits))
+		for _, c := range det.Commits {
+			lines = append(lines, fmt.Sprintf("  %s %s %s",
+				ui.StyleCommand.Render(c.Hash), c.Subject, styleDim.Render(ui.Age(c.When))))
+		}
+
+		if len(det.Stashes) > 0 {
+			section("Stashes", len(det.Stashes))
+			for _, s := range det.Stashes {
+				lines = append(lines, fmt.Sprintf("  %s %s", styleDim.Render(s.Ref), s.Subject))
+			}
+		}
+
+		section("Branches", len(det.Branches))
+		for _, br := range det.Branches {
+			lines = append(lines, "  "+renderBranch(br))
+		}
+
+		section("Remotes", len(det.Remotes))
+		for _, r := range det.Remotes {
+			lines = append(lines, fmt.Sprintf("  %s %s", ui.StyleRepoName.Render(r.Name), styleDim.Render(r.URL)))
+		}
+	}
+
+	if height > 2 && len(lines) > height-2 {
+		more := len(lines) - (height - 3)
+		lines = append(lines[:height-3], styleDim.Render(fmt.Sprintf("… %d more lines", more)))
+	}
+	clip := lipgloss.NewStyle().MaxWidth(inner)
+	for i, line := range lines {
+		lines[i] = clip.Render(line)
+	}
+
+	return styleBox.Render(strings.Join(lines, "\n"))
+}
+
+// renderXY colors a porcelain XY code: green for staged, yellow for
+// unstaged, red for conflicts.
+func renderXY(f util.FileChange) string {
+	if f.Conflicted() {
+		return ui.StyleError.Render(f.XY)
+	}
+	if f.XY == "??" {
+		return ui.StyleCommand.Render(f.XY)
+	}
+	x, y := string(f.XY[0]), string(f.XY[1])
+	if f.Staged() {
+		x = ui.StyleSuccess.Render(x)
+	} else {
+		x = styleDim.Render(x)
+	}
+	if f.Unstaged() {
+		y = ui.StyleWarning.Render(y)
+	} else {
+		y = styleDim.Render(y)
+	}
+	return x + y
+}
+
+func renderChangePath(f util.FileChange) string {
+	if f.OrigPath != "" {
+		return f.OrigPath + " → " + f.Path
+	}
+	return f.Path
+}
+
+// renderBranch shows a branch with its upstream sync state.
+func renderBranch(b util.BranchInfo) string {
+	marker := "  "
+	name := b.Name
+	if b.Current {
+		marker = styleCursor.Render("* ")
+		name = ui.StyleCommand.Render(name)
+	}
+	var sync []string
+	switch {
+	case b.Gone:
+		sync = append(sync, ui.StyleWarning.Render("upstream gone"))
+	case b.Upstream == "":
+		sync = append(sync, styleDim.Render("no upstream"))
+	default:
+		if b.Ahead > 0 {
+			sync = append(sync, ui.StyleSuccess.Render(fmt.Sprintf("↑%d", b.Ahead)))
+		}
+		if b.Behind > 0 {
+			sync = append(sync, ui.StyleError.Render(fmt.Sprintf("↓%d", b.Behind)))
+		}
+		if len(sync) == 0 {
+			sync = append(sync, styleDim.Render("="))
+		}
+	}
+	return marker + name + " " + strings.Join(sync, " ")
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gee/pkg/ui"
+	"gee/pkg/util"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// diffSidebarWidth is the width of the file list beside the diff.
+const diffSidebarWidth = 36
+
+// Diff styles, set with the rest by setStyles.
+var (
+	styleDiffAdd  lipgloss.Style
+	styleDiffDel  lipgloss.Style
+	styleDiffHunk lipgloss.Style
+	styleDiffFile lipgloss.Style
+)
+
+// DiffModel holds the diff viewer for one repo. Every file's patch is laid
+// out in one viewport; the sidebar follows the scroll position.
+type DiffModel struct {
+	Path     string
+	Name     string
+	Files    []util.DiffFile
+	Loading  bool
+	Err      error
+	Viewport viewport.Model
+
+	fileStarts []int // line offset of each file's header in the viewport
+	hunkStarts []int // line offset of every hunk header, ascending
+	focus      int   // line the user last jumped or scrolled to
+}
+
+// openDiff switches to the diff view for row and starts loading its diff.
+func (m *AppModel) openDiff(row RepoRow) tea.Cmd {
+	m.Diff = DiffModel{
+		Path:     row.Path,
+		Name:     row.DisplayName,
+		Loading:  true,
+		Viewport: viewport.New(0, 0),
+	}
+	m.sizeDiff()
+	m.ActiveView = ViewDiff
+	return loadDiffCmd(row.Path)
+}
+
+// sizeDiff fits the viewport to the terminal, leaving room for the sidebar,
+// header and help bar.
+func (m *AppModel) sizeDiff() {
+	m.Diff.Viewport.Width = max(m.Width-diffSidebarWidth-1, 20)
+	m.Diff.Viewport.Height = max(m.Height-4, 5)
+}
+
+// loadDiffCmd reads the repo's staged, unstaged and untracked changes.
+func loadDiffCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		files, err := util.LoadDiff(context.Background(), path)
+		return DiffLoadedMsg{Path: path, Files: files, Err: err}
+	}
+}
+
+// setFiles renders files into the viewport, keeping the scroll position
+// when the same diff is reloaded.
+func (d *DiffModel) setFiles(files []util.DiffFile) {
+	d.Files = files
+	d.fileStarts = d.fileStarts[:0]
+	d.hunkStarts = d.hunkStarts[:0]
+
+	var lines []string
+	for _, f := range files {
+		d.fileStarts = append(d.fileStarts, len(lines))
+		title := fmt.Sprintf(" %s  %s ", f.Section, f.Path)
+		if f.OrigPath != "" {
+			title = fmt.Sprintf(" %s  %s → %s ", f.Section, f.OrigPath, f.Path)
+		}
+		lines = append(lines, styleDiffFile.Render(title))
+		for _, h := range f.Header[1:] {
+			lines = append(lines, styleDim.Render(h))
+		}
+		if f.Binary {
+			lines = append(lines, styleDim.Render("binary file"))
+		}
+		for _, h := range f.Hunks {
+			d.hunkStarts = append(d.hunkStarts, len(lines))
+			lines = append(lines, styleDiffHunk.Render(h.Header))
+			for _, l := range h.Lines {
+				lines = append(lines, renderDiffLine(l))
+			}
+		}
+		lines = append(lines, "")
+	}
+	if len(files) == 0 {
+		lines = append(lines, styleDim.Render("  no changes"))
+	}
+
+	offset := d.Viewport.YOffset
+	d.Viewport.SetContent(strings.Join(lines, "\n"))
+	d.Viewport.SetYOffset(offset)
+	d.focus = min(d.focus, max(len(lines)-1, 0))
+}
+
+// renderDiffLine colors one patch line by its prefix.
+func renderDiffLine(l string) string {
+	switch {
+	case strings.HasPrefix(l, "+"):
+		return styleDiffAdd.Render(l)
+	case strings.HasPrefix(l, "-"):
+		return styleDiffDel.Render(l)
+	case strings.HasPrefix(l, "\\"):
+		return styleDim.Render(l)
+	}
+	return l
+}
+
+// currentFile returns the index of the file holding the focused line.
+func (d *DiffModel) currentFile() int {
+	return max(sort.SearchInts(d.fileStarts, d.focus+1)-1, 0)
+}
+
+// currentHunk returns the 1-based number of the focused hunk, 0 if the
+// focus is above the first.
+func (d *DiffModel) currentHunk() int {
+	return sort.SearchInts(d.hunkStarts, d.focus+1)
+}
+
+// jump moves the focus to the next (dir > 0) or previous line in starts and
+// scrolls it to the top of the viewport, as far as the content allows.
+func (d *DiffModel) jump(starts []int, dir int) {
+	if dir > 0 {
+		for _, s := range starts {
+			if s > d.focus {
+				d.focus = s
+				d.Viewport.SetYOffset(s)
+				return
+			}
+		}
+		return
+	}
+	for i := len(starts) - 1; i >= 0; i-- {
+		if starts[i] < d.focus {
+			d.focus = starts[i]
+			d.Viewport.SetYOffset(starts[i])
+			return
+		}
+	}
+}
+
+func (m AppModel) updateDiff(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if c := m.Keys.lookup(&m, ViewDiff, msg.String()); c != nil {
+		return m, c.Run(&m)
+	}
+	return m, nil
+}
+
+// scroll applies move to the viewport, taking the focus along when the
+// view actually moved.
+func (d *DiffModel) scroll(move func(*viewport.Model)) {
+	before := d.Viewport.YOffset
+	move(&d.Viewport)
+	if d.Viewport.YOffset != before {
+		d.focus = d.Viewport.YOffset
+	}
+}
+
+// stageDiffFile stages or unstages the file under the focus.
+func (m *AppModel) stageDiffFile(unstage bool) tea.Cmd {
+	d := &m.Diff
+	i := d.currentFile()
+	if i >= len(d.Files) {
+		return nil
+	}
+	f := d.Files[i]
+	paths := []string{f.Path}
+	if f.OrigPath != "" {
+		paths = append(paths, f.OrigPath)
+	}
+	if row, ok := m.diffRow(); ok {
+		return m.stageCmds([]RepoRow{row}, unstage, paths...)
+	}
+	return nil
+}
+
+// diffRow returns the dashboard row of the repo the diff shows.
+func (m *AppModel) diffRow() (RepoRow, bool) {
+	if i := m.rowIndexByPath(m.Diff.Path); i >= 0 {
+		return m.Rows[i], true
+	}
+	return RepoRow{}, false
+}
+
+func (m AppModel) viewDiff() string {
+	var b strings.Builder
+	d := &m.Diff
+
+	title := fmt.Sprintf(" Diff — %s", d.Name)
+	header := styleHeader.Render(title)
+	if d.Loading {
+		header += styleDim.Render("  loading...")
+	} else {
+		staged, unstaged, untracked := 0, 0, 0
+		for _, f := range d.Files {
+			switch f.Section {
+			case util.DiffStaged:
+				staged++
+			case util.DiffUnstaged:
+				unstaged++
+			default:
+				untracked++
+			}
+		}
+		header += styleDim.Render(fmt.Sprintf("  %d staged · %d unstaged · %d untracked · hunk %d/%d",
+			staged, unstaged, untracked, d.currentHunk(), len(d.hunkStarts)))
+	}
+	b.WriteString(header + "\n\n")
+
+	if d.Err != nil {
+		b.WriteString(ui.StyleError.Render("  "+d.Err.Error()) + "\n")
+		b.WriteString(m.renderKeyOverlay() + "\n" + styleHelpBar.Render(m.keyHelp(ViewDiff, "diff.reload", "diff.back", "palette", "help")))
+		return b.String()
+	}
+
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.renderDiffSidebar(), " ", d.Viewport.View()) + "\n")
+	if m.Commit.Active {
+		b.WriteString(m.renderCommit() + "\n")
+	} else if n := len(m.ActionLog); n > 0 {
+		b.WriteString(styleDim.Render("  "+m.ActionLog[n-1]) + "\n")
+	}
+	b.WriteString(m.renderKeyOverlay() + styleHelpBar.Render(m.keyHelp(ViewDiff)))
+	return b.String()
+}
+
+// renderDiffSidebar lists the changed files, grouped by section, marking
+// the one at the top of the viewport.
+func (m AppModel) renderDiffSidebar() string {
+	d := &m.Diff
+	current := d.currentFile()
+	height := d.Viewport.Height
+	clip := lipgloss.NewStyle().Width(diffSidebarWidth).MaxWidth(diffSidebarWidth)
+
+	var lines []string
+	section := util.DiffSection(-1)
+	for i, f := range d.Files {
+		if f.Section != section {
+			section = f.Section
+			lines = append(lines, styleTableHead.Render(strings.ToUpper(section.String())))
+		}
+		name := f.Path
+		if len(name) > diffSidebarWidth-4 {
+			name = "…" + name[len(name)-(diffSidebarWidth-5):]
+		}
+		if i == current {
+			lines = append(lines, styleCursor.Render("▸ ")+name)
+		} else {
+			lines = append(lines, "  "+name)
+		}
+	}
+	// Keep the current file in view when the list is longer than the pane.
+	if len(lines) > height {
+		start := 0
+		for i, l := range lines {
+			if strings.Contains(l, "▸") {
+				start = max(i-height/2, 0)
+			}
+		}
+		lines = lines[start:min(start+height, len(lines))]
+	}
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return clip.Render(strings.Join(lines, "\n"))
+}
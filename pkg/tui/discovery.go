@@ -1,30 +1,339 @@
 package tui
 
-import "os/exec"
+import (
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
-// Capabilities records which external CLIs are available.
-type Capabilities struct {
-	HasGH   bool
-	HasGLab bool
+	"gee/pkg/discovery"
+	"gee/pkg/util"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DiscoverySources lists the authenticated user's own repos on every
+// provider. Orgs, groups and workspaces are appended once fetched.
+func DiscoverySources(providers []discovery.Provider) []discovery.Source {
+	sources := make([]discovery.Source, len(providers))
+	for i, p := range providers {
+		sources[i] = discovery.Source{Provider: p}
+	}
+	return sources
 }
 
-// DetectCapabilities checks the PATH for gh and glab.
-func DetectCapabilities() Capabilities {
-	_, ghErr := exec.LookPath("gh")
-	_, glabErr := exec.LookPath("glab")
-	return Capabilities{
-		HasGH:   ghErr == nil,
-		HasGLab: glabErr == nil,
+func newDiscoveryModel(settings util.DiscoverySettings, clone util.CloneOptions) DiscoveryModel {
+	providers := discovery.Configure(settings)
+	search := textinput.New()
+	search.Placeholder = "search name or description..."
+	search.CharLimit = 64
+	return DiscoveryModel{
+		Search:    search,
+		Providers: providers,
+		Sources:   DiscoverySources(providers),
+		ShowForks: true,
+		Selected:  make(map[string]cloneTarget),
+		Cache:     discovery.LoadCache(discovery.DefaultCachePath()),
+		TTL:       settings.TTL(),
+		owners:    make(map[discovery.Provider]bool),
+		Options:   clone,
 	}
 }
 
-// DiscoveryProvider returns "gh", "glab", or "" based on what's available.
-func DiscoveryProvider(caps Capabilities) string {
-	if caps.HasGH {
-		return "gh"
+// available reports whether any discovery provider is configured.
+func (d *DiscoveryModel) available() bool {
+	return len(d.Providers) > 0
+}
+
+// current returns the source being browsed.
+func (d *DiscoveryModel) current() (discovery.Source, bool) {
+	if d.Source < 0 || d.Source >= len(d.Sources) {
+		return discovery.Source{}, false
 	}
-	if caps.HasGLab {
-		return "glab"
+	return d.Sources[d.Source], true
+}
+
+// visible returns the current listing narrowed by the search, language and
+// visibility filters, with archived repos and forks removed unless they are
+// toggled on.
+func (d *DiscoveryModel) visible() []discovery.Repo {
+	query := strings.ToLower(strings.TrimSpace(d.Search.Value()))
+	repos := make([]discovery.Repo, 0, len(d.Repos))
+	for _, r := range d.Repos {
+		if (r.Archived && !d.ShowArchived) || (r.Fork && !d.ShowForks) {
+			continue
+		}
+		if d.Language != "" && r.Language != d.Language {
+			continue
+		}
+		if (d.Visibility == "public" && r.Private) || (d.Visibility == "private" && !r.Private) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(r.FullName), query) &&
+			!strings.Contains(strings.ToLower(r.Description), query) {
+			continue
+		}
+		repos = append(repos, r)
+	}
+	return repos
+}
+
+// languages returns the languages in the current listing, most common
+// first.
+func (d *DiscoveryModel) languages() []string {
+	counts := make(map[string]int)
+	for _, r := range d.Repos {
+		if r.Language != "" {
+			counts[r.Language]++
+		}
+	}
+	langs := make([]string, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if counts[langs[i]] != counts[langs[j]] {
+			return counts[langs[i]] > counts[langs[j]]
+		}
+		return langs[i] < langs[j]
+	})
+	return langs
+}
+
+// cycleLanguage steps the language filter through every language in the
+// listing and back to all.
+func (d *DiscoveryModel) cycleLanguage() {
+	langs := d.languages()
+	next := ""
+	if d.Language == "" {
+		if len(langs) > 0 {
+			next = langs[0]
+		}
+	} else if i := slices.Index(langs, d.Language); i >= 0 && i+1 < len(langs) {
+		next = langs[i+1]
+	}
+	d.Language = next
+	d.Cursor = 0
+}
+
+// cycleVisibility steps the visibility filter: all -> public -> private.
+func (d *DiscoveryModel) cycleVisibility() {
+	switch d.Visibility {
+	case "":
+		d.Visibility = "public"
+	case "public":
+		d.Visibility = "private"
+	default:
+		d.Visibility = ""
+	}
+	d.Cursor = 0
+}
+
+// moveCursor moves the cursor by delta repos, within bounds.
+func (d *DiscoveryModel) moveCursor(delta int) {
+	d.Cursor = max(min(d.Cursor+delta, len(d.visible())-1), 0)
+}
+
+// cycleSource switches step owners forward (or back when negative) through
+// the user's own repos and each org/group, and loads the listing.
+func (d *DiscoveryModel) cycleSource(step int) tea.Cmd {
+	n := len(d.Sources)
+	if n < 2 {
+		return nil
+	}
+	d.Source = ((d.Source+step)%n + n) % n
+	d.Repos = nil
+	d.Cursor = 0
+	d.Language = ""
+	return d.load(false)
+}
+
+// syncLocal records which remote repos are already cloned, by normalized
+// remote URL.
+func (d *DiscoveryModel) syncLocal(cache *util.RepoCache) {
+	d.local = cache.PathsByRemote()
+}
+
+// localPath returns where repo is cloned, or "" if it isn't.
+func (d *DiscoveryModel) localPath(repo discovery.Repo) string {
+	for _, u := range []string{repo.CloneURL, repo.HTTPSURL} {
+		if key := util.RemoteKey(u); key != "" {
+			if path, ok := d.local[key]; ok {
+				return path
+			}
+		}
 	}
 	return ""
 }
+
+// selectNotCloned selects every visible repo that isn't cloned yet.
+func (d *DiscoveryModel) selectNotCloned() {
+	src, ok := d.current()
+	if !ok {
+		return
+	}
+	for _, repo := range d.visible() {
+		if d.localPath(repo) == "" {
+			d.selectFrom(src, repo)
+		}
+	}
+}
+
+// load fetches the current source's listing (from the on-disk cache when
+// fresh, unless force) and, once per host, its orgs or groups.
+func (d *DiscoveryModel) load(force bool) tea.Cmd {
+	src, ok := d.current()
+	if !ok {
+		return nil
+	}
+	d.Loading = true
+	d.Error = nil
+	cmds := []tea.Cmd{discoverRemoteReposCmd(d.Cache, src, d.TTL, force)}
+	if force || !d.owners[src.Provider] {
+		d.owners[src.Provider] = true
+		cmds = append(cmds, discoverOwnersCmd(d.Cache, src.Provider, d.TTL, force))
+	}
+	return tea.Batch(cmds...)
+}
+
+// addOwners inserts a provider's orgs/groups after that provider's existing
+// sources, skipping ones already present.
+func (d *DiscoveryModel) addOwners(provider discovery.Provider, owners []string) {
+	known := make(map[string]bool)
+	insertAt := len(d.Sources)
+	for i, s := range d.Sources {
+		if s.Provider == provider {
+			known[s.Owner] = true
+			insertAt = i + 1
+		}
+	}
+	var added []discovery.Source
+	for _, o := range owners {
+		if !known[o] {
+			added = append(added, discovery.Source{Provider: provider, Owner: o})
+		}
+	}
+	if len(added) == 0 {
+		return
+	}
+	sources := make([]discovery.Source, 0, len(d.Sources)+len(added))
+	sources = append(sources, d.Sources[:insertAt]...)
+	sources = append(sources, added...)
+	sources = append(sources, d.Sources[insertAt:]...)
+	if d.Source >= insertAt {
+		d.Source += len(added)
+	}
+	d.Sources = sources
+}
+
+// toggle selects or deselects repo from the current source, remembering
+// the URL to clone it with.
+func (d *DiscoveryModel) toggle(repo discovery.Repo) {
+	src, ok := d.current()
+	if !ok {
+		return
+	}
+	if key := selectionKey(src, repo); d.isSelected(src, repo) {
+		delete(d.Selected, key)
+		return
+	}
+	d.selectFrom(src, repo)
+}
+
+func (d *DiscoveryModel) selectFrom(src discovery.Source, repo discovery.Repo) {
+	key := selectionKey(src, repo)
+	d.Selected[key] = cloneTarget{Key: key, Repo: repo, URL: src.Provider.CloneURL(repo)}
+}
+
+// isSelected reports whether repo, listed by src, is selected.
+func (d *DiscoveryModel) isSelected(src discovery.Source, repo discovery.Repo) bool {
+	_, ok := d.Selected[selectionKey(src, repo)]
+	return ok
+}
+
+// selectionKey identifies repo across sources and listings. Clone URLs
+// won't do: providers may not report an ssh one.
+func selectionKey(src discovery.Source, repo discovery.Repo) string {
+	return src.Provider.Host() + "/" + repo.FullName
+}
+
+// startClone queues every selected repo, sorted by name, and starts
+// cloning them into cloneDir.
+func (d *DiscoveryModel) startClone(cache *util.RepoCache, cloneDir string) tea.Cmd {
+	targets := make([]cloneTarget, 0, len(d.Selected))
+	for _, target := range d.Selected {
+		targets = append(targets, target)
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Repo.FullName < targets[j].Repo.FullName })
+
+	d.Clones = make([]cloneJob, len(targets))
+	for i, target := range targets {
+		d.Clones[i] = cloneJob{Target: target}
+	}
+	d.Cloning = true
+	cmd, ch := cloneBatchCmd(targets, d.Options, cache, cloneDir)
+	d.cloneCh = ch
+	return cmd
+}
+
+// finishClone ends a batch: successes are deselected, failures stay
+// selected so enter retries them. It returns the counts.
+func (d *DiscoveryModel) finishClone() (succeeded, failed int) {
+	d.Cloning = false
+	d.cloneCh = nil
+	for _, job := range d.Clones {
+		if job.State == cloneFailed {
+			failed++
+			continue
+		}
+		succeeded++
+		delete(d.Selected, job.Target.Key)
+	}
+	return succeeded, failed
+}
+
+// toggleOption changes one clone option ("depth", "blobless",
+// "single-branch", "submodules" or "protocol"); defaults are the [clone]
+// settings, so toggling a setting off and on restores its configured value.
+func (d *DiscoveryModel) toggleOption(option string, defaults util.CloneOptions) {
+	o := &d.Options
+	switch option {
+	case "depth":
+		if o.Depth > 0 {
+			o.Depth = 0
+		} else {
+			o.Depth = max(defaults.Depth, 1)
+		}
+	case "blobless":
+		if o.Filter != "" {
+			o.Filter = ""
+		} else if defaults.Filter != "" {
+			o.Filter = defaults.Filter
+		} else {
+			o.Filter = "blob:none"
+		}
+	case "single-branch":
+		o.SingleBranch = !o.SingleBranch
+	case "submodules":
+		o.RecurseSubmodules = !o.RecurseSubmodules
+	case "protocol":
+		// Provider default -> ssh -> https -> provider default.
+		switch o.Protocol {
+		case "":
+			o.Protocol = "ssh"
+		case "ssh":
+			o.Protocol = "https"
+		default:
+			o.Protocol = ""
+		}
+	}
+}
+
+// age formats how long ago a listing was fetched.
+func (d *DiscoveryModel) age() string {
+	if d.FetchedAt.IsZero() {
+		return ""
+	}
+	return time.Since(d.FetchedAt).Round(time.Second).String()
+}
@@ -0,0 +1,643 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// action is a named command of a view or of a mode within one. Every key
+// the views handle outside of text input goes through one, so bindings
+// can be overridden in config.toml and the help bar, the ? overlay and the
+// ctrl+p palette are generated from the same list.
+type action struct {
+	ID    string   // name in config.toml's [keys], e.g. "pull" or "discovery.clone"
+	Views []View   // where the command applies
+	Keys  []string // default bindings, as tea.KeyMsg.String() spells them
+	Help  string   // help bar label; commands sharing one are shown together, "" leaves it out
+	Title string   // what the palette and the ? overlay call it
+	Run   func(m *AppModel) tea.Cmd
+
+	// Enabled, when set, hides the command while it can't run.
+	Enabled func(m *AppModel) bool
+}
+
+func (c *action) in(v View) bool {
+	for _, cv := range c.Views {
+		if cv == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *action) enabled(m *AppModel) bool {
+	return c.Enabled == nil || c.Enabled(m)
+}
+
+var (
+	onDashboard = []View{ViewDashboard}
+	onDiscovery = []View{ViewDiscovery}
+	onDiff      = []View{ViewDiff}
+	onOutput    = []View{ViewOutput}
+	everywhere  = []View{ViewDashboard, ViewDiscovery, ViewDiff, ViewOutput}
+)
+
+// commands lists every built-in command in help bar order; withTools adds
+// the external tools.
+var commands = []*action{
+	// --- Dashboard ---
+	{ID: "down", Views: onDashboard, Keys: []string{"j", "down"}, Help: "nav", Title: "Move down",
+		Run: func(m *AppModel) tea.Cmd { m.moveCursor(1); return nil }},
+	{ID: "up", Views: onDashboard, Keys: []string{"k", "up"}, Help: "nav", Title: "Move up",
+		Run: func(m *AppModel) tea.Cmd { m.moveCursor(-1); return nil }},
+	{ID: "top", Views: onDashboard, Keys: []string{"g", "home"}, Title: "Jump to the first repo",
+		Run: func(m *AppModel) tea.Cmd { m.Cursor = 0; return nil }},
+	{ID: "bottom", Views: onDashboard, Keys: []string{"G", "end"}, Title: "Jump to the last repo",
+		Run: func(m *AppModel) tea.Cmd { m.Cursor = max(len(m.filteredRows())-1, 0); return nil }},
+	{ID: "mark", Views: onDashboard, Keys: []string{" "}, Help: "mark", Title: "Mark or unmark the selected repo",
+		Run: func(m *AppModel) tea.Cmd { m.toggleMark(); m.moveCursor(1); return nil }},
+	{ID: "mark-range", Views: onDashboard, Keys: []string{"V"}, Help: "mark", Title: "Start a range; again, mark every row up to the cursor",
+		Run: func(m *AppModel) tea.Cmd { m.toggleRange(); return nil }},
+	{ID: "invert-marks", Views: onDashboard, Keys: []string{"*"}, Help: "mark", Title: "Invert the marks",
+		Run: func(m *AppModel) tea.Cmd { m.invertMarks(); return nil }},
+	{ID: "mark-all", Views: onDashboard, Keys: []string{"A"}, Help: "mark", Title: "Mark every repo the filter shows",
+		Run: func(m *AppModel) tea.Cmd { m.markFiltered(); return nil }},
+	{ID: "clear-marks", Views: onDashboard, Keys: []string{"esc"}, Title: "Cancel a range, else clear all marks",
+		Run: func(m *AppModel) tea.Cmd {
+			if m.RangeAnchor >= 0 {
+				m.RangeAnchor = -1
+			} else {
+				m.clearMarks()
+			}
+			return nil
+		}},
+	{ID: "pin", Views: onDashboard, Keys: []string{"a"}, Help: "pin", Title: "Pin or unpin the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { m.togglePins(m.targetRows()); return nil }},
+	{ID: "unpin", Views: onDashboard, Keys: []string{"x"}, Help: "unpin/forget/hide", Title: "Unpin the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { m.confirmUnpin(); return nil }},
+	{ID: "forget", Views: onDashboard, Keys: []string{"X"}, Help: "unpin/forget/hide", Title: "Forget the marked repos, or the selected one: remove them from the cache",
+		Run: func(m *AppModel) tea.Cmd { m.confirmForget(); return nil }},
+	{ID: "hide", Views: onDashboard, Keys: []string{"H"}, Help: "unpin/forget/hide", Title: "Hide the marked repos, or the selected one, for good; on hidden ones, restore them",
+		Run: func(m *AppModel) tea.Cmd { m.confirmHide(); return nil }},
+	{ID: "show-hidden", Views: onDashboard, Keys: []string{"."}, Title: "Show or hide the hidden repos",
+		Run: func(m *AppModel) tea.Cmd { m.toggleShowHidden(); return nil }},
+	{ID: "pull", Views: onDashboard, Keys: []string{"p"}, Help: "pull", Title: "Pull the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.runOnTargets("pull", "") }},
+	{ID: "pull-all", Views: onDashboard, Keys: []string{"P"}, Help: "pull all", Title: "Pull every repo the filter shows",
+		Run: func(m *AppModel) tea.Cmd { return m.runOn("pull", "", m.shownRows()) }},
+	{ID: "fetch", Views: onDashboard, Keys: []string{"f"}, Help: "fetch", Title: "Fetch the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.runOnTargets("fetch", "") }},
+	{ID: "push", Views: onDashboard, Keys: []string{"U"}, Help: "push", Title: "Push the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.runOnTargets("push", "") }},
+	{ID: "exec", Views: onDashboard, Keys: []string{"e"}, Help: "exec", Title: "Run a shell command in the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd {
+			m.ExecActive = true
+			m.ExecInput.Focus()
+			return textinput.Blink
+		}},
+	{ID: "stage", Views: onDashboard, Keys: []string{"s"}, Help: "stage/unstage", Title: "Stage all changes in the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.stageCmds(m.targetRows(), false) }},
+	{ID: "unstage", Views: onDashboard, Keys: []string{"u"}, Help: "stage/unstage", Title: "Unstage all changes in the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.stageCmds(m.targetRows(), true) }},
+	{ID: "commit", Views: onDashboard, Keys: []string{"c"}, Help: "commit", Title: "Commit the staged changes in the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.openCommit(m.targetRows()) }},
+	{ID: "branch", Views: onDashboard, Keys: []string{"b"}, Help: "branch", Title: "Switch branch in the marked repos, or the selected one",
+		Run: func(m *AppModel) tea.Cmd { return m.openBranches(m.targetRows()) }},
+	{ID: "teleport", Views: onDashboard, Keys: []string{"enter"}, Help: "cd", Title: "Quit and cd into the selected repo (on a section header, fold it)",
+		Run: func(m *AppModel) tea.Cmd {
+			if row, ok := m.selectedRow(); ok {
+				m.SelectedPath = row.Path
+				return tea.Quit
+			}
+			return m.toggleGroup()
+		}},
+	{ID: "open", Views: onDashboard, Keys: []string{"o"}, Help: "open", Title: "Open the selected repo's web page",
+		Run: func(m *AppModel) tea.Cmd {
+			if row, ok := m.selectedRow(); ok {
+				return openWebCmd(row)
+			}
+			return nil
+		}},
+	{ID: "copy", Views: onDashboard, Keys: []string{"y"}, Help: "copy", Title: "Copy the selected repo's path, remote URL or web URL",
+		Run: func(m *AppModel) tea.Cmd {
+			_, m.Yanking = m.selectedRow()
+			return nil
+		}},
+	{ID: "detail", Views: onDashboard, Keys: []string{"tab"}, Help: "detail", Title: "Show or hide the detail pane",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Detail.Visible = !m.Detail.Visible
+			if !m.Detail.Visible {
+				// Reload on reopen; the repo may have changed meanwhile.
+				m.Detail.Path = ""
+			}
+			return nil
+		}},
+	{ID: "diff", Views: onDashboard, Keys: []string{"D"}, Help: "diff", Title: "Open the diff viewer for the selected repo",
+		Run: func(m *AppModel) tea.Cmd {
+			if row, ok := m.selectedRow(); ok && !row.Missing {
+				return m.openDiff(row)
+			}
+			return nil
+		}},
+	{ID: "output", Views: onDashboard, Keys: []string{"O"}, Help: "output", Title: "Open the output of recent operations",
+		Run: func(m *AppModel) tea.Cmd {
+			m.ActiveView = ViewOutput
+			m.Output.syncOutput()
+			return nil
+		}},
+	{ID: "sort", Views: onDashboard, Keys: []string{"S"}, Help: "sort", Title: "Cycle the sort order",
+		Run: func(m *AppModel) tea.Cmd { return m.cycleSort() }},
+	{ID: "group", Views: onDashboard, Keys: []string{"="}, Help: "group", Title: "Cycle the grouping",
+		Run: func(m *AppModel) tea.Cmd { return m.cycleGrouping() }},
+	{ID: "fold", Views: onDashboard, Keys: []string{"z"}, Help: "fold", Title: "Fold or unfold the section under the cursor",
+		Run: func(m *AppModel) tea.Cmd { return m.toggleGroup() }},
+	{ID: "fold-all", Views: onDashboard, Keys: []string{"Z"}, Help: "fold", Title: "Fold or unfold every section",
+		Run: func(m *AppModel) tea.Cmd { return m.toggleAllGroups() }},
+	{ID: "refresh", Views: onDashboard, Keys: []string{"r"}, Help: "refresh", Title: "Refresh every repo's status",
+		Run: func(m *AppModel) tea.Cmd {
+			if m.Refreshing {
+				return nil
+			}
+			return m.startRefresh()
+		}},
+	{ID: "filter", Views: onDashboard, Keys: []string{"/"}, Help: "filter", Title: "Filter repos",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Filtering = true
+			m.FilterInput.Focus()
+			return textinput.Blink
+		}},
+	{ID: "discover", Views: onDashboard, Keys: []string{"d"}, Help: "discover", Title: "Open Discovery to browse and clone remote repos",
+		Enabled: func(m *AppModel) bool { return m.Discovery.available() },
+		Run: func(m *AppModel) tea.Cmd {
+			m.ActiveView = ViewDiscovery
+			m.Discovery.syncLocal(m.Cache)
+			if len(m.Discovery.Repos) == 0 && !m.Discovery.Loading {
+				return m.Discovery.load(false)
+			}
+			return nil
+		}},
+
+	// --- Dashboard, after y ---
+	{ID: "copy.path", Views: []View{ViewCopy}, Keys: []string{"p"}, Help: "path", Title: "Copy the repo's path",
+		Run: func(m *AppModel) tea.Cmd { return m.copySelected("path") }},
+	{ID: "copy.remote", Views: []View{ViewCopy}, Keys: []string{"r"}, Help: "remote url", Title: "Copy the repo's remote URL",
+		Run: func(m *AppModel) tea.Cmd { return m.copySelected("remote") }},
+	{ID: "copy.web", Views: []View{ViewCopy}, Keys: []string{"w"}, Help: "web url", Title: "Copy the repo's web URL",
+		Run: func(m *AppModel) tea.Cmd { return m.copySelected("web") }},
+	{ID: "copy.cancel", Views: []View{ViewCopy}, Keys: []string{"esc"}, Help: "cancel", Title: "Copy nothing",
+		Run: func(m *AppModel) tea.Cmd { return nil }},
+
+	// --- Discovery ---
+	{ID: "discovery.down", Views: onDiscovery, Keys: []string{"j", "down"}, Help: "nav", Title: "Move down",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.moveCursor(1); return nil }},
+	{ID: "discovery.up", Views: onDiscovery, Keys: []string{"k", "up"}, Help: "nav", Title: "Move up",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.moveCursor(-1); return nil }},
+	{ID: "discovery.top", Views: onDiscovery, Keys: []string{"g", "home"}, Title: "Jump to the first repo",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.Cursor = 0; return nil }},
+	{ID: "discovery.bottom", Views: onDiscovery, Keys: []string{"G", "end"}, Title: "Jump to the last repo",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.Cursor = max(len(m.Discovery.visible())-1, 0); return nil }},
+	{ID: "discovery.select", Views: onDiscovery, Keys: []string{" "}, Help: "select", Title: "Select or deselect the repo for cloning",
+		Run: func(m *AppModel) tea.Cmd {
+			visible := m.Discovery.visible()
+			if len(visible) > 0 {
+				m.Discovery.toggle(visible[m.Discovery.Cursor])
+				m.Discovery.moveCursor(1)
+			}
+			return nil
+		}},
+	{ID: "discovery.select-new", Views: onDiscovery, Keys: []string{"n"}, Help: "select not cloned", Title: "Select every shown repo that isn't cloned yet",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.selectNotCloned(); return nil }},
+	{ID: "discovery.clone", Views: onDiscovery, Keys: []string{"enter"}, Help: "clone selected", Title: "Clone the selected repos and pin them",
+		Run: func(m *AppModel) tea.Cmd {
+			if len(m.Discovery.Selected) == 0 {
+				return nil
+			}
+			cloneDir, _ := os.UserHomeDir()
+			return m.Discovery.startClone(m.Cache, cloneDir)
+		}},
+	{ID: "discovery.search", Views: onDiscovery, Keys: []string{"/"}, Help: "search", Title: "Search names and descriptions",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Discovery.Searching = true
+			m.Discovery.Search.Focus()
+			return textinput.Blink
+		}},
+	{ID: "discovery.language", Views: onDiscovery, Keys: []string{"l"}, Help: "language", Title: "Cycle the language filter",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.cycleLanguage(); return nil }},
+	{ID: "discovery.visibility", Views: onDiscovery, Keys: []string{"v"}, Help: "visibility", Title: "Cycle the visibility filter: all, public, private",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.cycleVisibility(); return nil }},
+	{ID: "discovery.options", Views: onDiscovery, Keys: []string{"c"}, Help: "clone options", Title: "Change clone options",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.EditingOptions = true; return nil }},
+	{ID: "discovery.next-owner", Views: onDiscovery, Keys: []string{"o"}, Help: "owner", Title: "Switch to the next owner: your repos, then each org or group",
+		Run: func(m *AppModel) tea.Cmd { return m.Discovery.cycleSource(1) }},
+	{ID: "discovery.prev-owner", Views: onDiscovery, Keys: []string{"O"}, Help: "owner", Title: "Switch to the previous owner",
+		Run: func(m *AppModel) tea.Cmd { return m.Discovery.cycleSource(-1) }},
+	{ID: "discovery.archived", Views: onDiscovery, Keys: []string{"a"}, Help: "archived", Title: "Show or hide archived repos",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Discovery.ShowArchived = !m.Discovery.ShowArchived
+			m.Discovery.Cursor = 0
+			return nil
+		}},
+	{ID: "discovery.forks", Views: onDiscovery, Keys: []string{"f"}, Help: "forks", Title: "Show or hide forks",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Discovery.ShowForks = !m.Discovery.ShowForks
+			m.Discovery.Cursor = 0
+			return nil
+		}},
+	{ID: "discovery.reload", Views: onDiscovery, Keys: []string{"R"}, Help: "reload", Title: "Reload the listing, bypassing the cache",
+		Run: func(m *AppModel) tea.Cmd {
+			if m.Discovery.Loading {
+				return nil
+			}
+			return m.Discovery.load(true)
+		}},
+	{ID: "discovery.back", Views: onDiscovery, Keys: []string{"esc", "d"}, Help: "back", Title: "Clear the search, else go back to the dashboard",
+		Run: func(m *AppModel) tea.Cmd {
+			if m.Discovery.Search.Value() != "" {
+				m.Discovery.Search.SetValue("")
+				m.Discovery.Cursor = 0
+				return nil
+			}
+			m.ActiveView = ViewDashboard
+			return nil
+		}},
+
+	// --- Discovery, after c ---
+	{ID: "options.depth", Views: []View{ViewCloneOptions}, Keys: []string{"d"}, Help: "depth", Title: "Toggle a shallow clone",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.toggleOption("depth", m.Settings.Clone); return nil }},
+	{ID: "options.blobless", Views: []View{ViewCloneOptions}, Keys: []string{"b"}, Help: "blobless", Title: "Toggle a partial (blobless) clone",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.toggleOption("blobless", m.Settings.Clone); return nil }},
+	{ID: "options.single-branch", Views: []View{ViewCloneOptions}, Keys: []string{"s"}, Help: "single-branch", Title: "Toggle cloning only the default branch",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.toggleOption("single-branch", m.Settings.Clone); return nil }},
+	{ID: "options.submodules", Views: []View{ViewCloneOptions}, Keys: []string{"m"}, Help: "submodules", Title: "Toggle cloning submodules",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.toggleOption("submodules", m.Settings.Clone); return nil }},
+	{ID: "options.protocol", Views: []View{ViewCloneOptions}, Keys: []string{"p"}, Help: "ssh/https", Title: "Cycle the clone protocol: provider default, ssh, https",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.toggleOption("protocol", m.Settings.Clone); return nil }},
+	{ID: "options.done", Views: []View{ViewCloneOptions}, Keys: []string{"esc", "c", "enter"}, Help: "done", Title: "Keep the clone options",
+		Run: func(m *AppModel) tea.Cmd { m.Discovery.EditingOptions = false; return nil }},
+
+	// --- Diff ---
+	{ID: "diff.down", Views: onDiff, Keys: []string{"j", "down"}, Help: "scroll", Title: "Scroll down",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.scroll(func(v *viewport.Model) { v.ScrollDown(1) }); return nil }},
+	{ID: "diff.up", Views: onDiff, Keys: []string{"k", "up"}, Help: "scroll", Title: "Scroll up",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.scroll(func(v *viewport.Model) { v.ScrollUp(1) }); return nil }},
+	{ID: "diff.page-down", Views: onDiff, Keys: []string{"pgdown"}, Title: "Scroll down a page",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.scroll(func(v *viewport.Model) { v.PageDown() }); return nil }},
+	{ID: "diff.page-up", Views: onDiff, Keys: []string{"pgup"}, Title: "Scroll up a page",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.scroll(func(v *viewport.Model) { v.PageUp() }); return nil }},
+	{ID: "diff.next-hunk", Views: onDiff, Keys: []string{"n"}, Help: "next/prev hunk", Title: "Jump to the next hunk",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.jump(m.Diff.hunkStarts, 1); return nil }},
+	{ID: "diff.prev-hunk", Views: onDiff, Keys: []string{"N"}, Help: "next/prev hunk", Title: "Jump to the previous hunk",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.jump(m.Diff.hunkStarts, -1); return nil }},
+	{ID: "diff.next-file", Views: onDiff, Keys: []string{"]", "J"}, Help: "next/prev file", Title: "Jump to the next file",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.jump(m.Diff.fileStarts, 1); return nil }},
+	{ID: "diff.prev-file", Views: onDiff, Keys: []string{"[", "K"}, Help: "next/prev file", Title: "Jump to the previous file",
+		Run: func(m *AppModel) tea.Cmd { m.Diff.jump(m.Diff.fileStarts, -1); return nil }},
+	{ID: "diff.stage", Views: onDiff, Keys: []string{"s"}, Help: "stage/unstage file", Title: "Stage the file under the cursor",
+		Run: func(m *AppModel) tea.Cmd { return m.stageDiffFile(false) }},
+	{ID: "diff.unstage", Views: onDiff, Keys: []string{"u"}, Help: "stage/unstage file", Title: "Unstage the file under the cursor",
+		Run: func(m *AppModel) tea.Cmd { return m.stageDiffFile(true) }},
+	{ID: "diff.commit", Views: onDiff, Keys: []string{"c"}, Help: "commit", Title: "Commit the staged changes",
+		Run: func(m *AppModel) tea.Cmd {
+			if row, ok := m.diffRow(); ok {
+				return m.openCommit([]RepoRow{row})
+			}
+			return nil
+		}},
+	{ID: "diff.top", Views: onDiff, Keys: []string{"g", "home"}, Help: "top/bottom", Title: "Jump to the top of the diff",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Diff.Viewport.GotoTop()
+			m.Diff.focus = 0
+			return nil
+		}},
+	{ID: "diff.bottom", Views: onDiff, Keys: []string{"G", "end"}, Help: "top/bottom", Title: "Jump to the bottom of the diff",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Diff.Viewport.GotoBottom()
+			m.Diff.focus = m.Diff.Viewport.YOffset
+			return nil
+		}},
+	{ID: "diff.reload", Views: onDiff, Keys: []string{"r"}, Help: "reload", Title: "Reload the diff",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Diff.Loading = true
+			return loadDiffCmd(m.Diff.Path)
+		}},
+	{ID: "diff.back", Views: onDiff, Keys: []string{"esc", "q"}, Help: "back", Title: "Go back to the dashboard",
+		Run: func(m *AppModel) tea.Cmd { m.ActiveView = ViewDashboard; return nil }},
+
+	// --- Output ---
+	{ID: "output.next-run", Views: onOutput, Keys: []string{"]", "tab"}, Help: "next/prev operation", Title: "Show the next (older) operation",
+		Run: func(m *AppModel) tea.Cmd { m.Output.selectRun(1); m.Output.syncOutput(); return nil }},
+	{ID: "output.prev-run", Views: onOutput, Keys: []string{"[", "shift+tab"}, Help: "next/prev operation", Title: "Show the previous (newer) operation",
+		Run: func(m *AppModel) tea.Cmd { m.Output.selectRun(-1); m.Output.syncOutput(); return nil }},
+	{ID: "output.down", Views: onOutput, Keys: []string{"j", "down"}, Help: "scroll", Title: "Scroll down",
+		Run: func(m *AppModel) tea.Cmd { m.Output.Viewport.ScrollDown(1); return nil }},
+	{ID: "output.up", Views: onOutput, Keys: []string{"k", "up"}, Help: "scroll", Title: "Scroll up",
+		Run: func(m *AppModel) tea.Cmd { m.Output.Viewport.ScrollUp(1); return nil }},
+	{ID: "output.page-down", Views: onOutput, Keys: []string{"pgdown"}, Title: "Scroll down a page",
+		Run: func(m *AppModel) tea.Cmd { m.Output.Viewport.PageDown(); return nil }},
+	{ID: "output.page-up", Views: onOutput, Keys: []string{"pgup"}, Title: "Scroll up a page",
+		Run: func(m *AppModel) tea.Cmd { m.Output.Viewport.PageUp(); return nil }},
+	{ID: "output.top", Views: onOutput, Keys: []string{"g", "home"}, Help: "top/bottom", Title: "Jump to the top of the output",
+		Run: func(m *AppModel) tea.Cmd { m.Output.Viewport.GotoTop(); return nil }},
+	{ID: "output.bottom", Views: onOutput, Keys: []string{"G", "end"}, Help: "top/bottom", Title: "Jump to the bottom of the output",
+		Run: func(m *AppModel) tea.Cmd { m.Output.Viewport.GotoBottom(); return nil }},
+	{ID: "output.search", Views: onOutput, Keys: []string{"/"}, Help: "search", Title: "Search the output",
+		Run: func(m *AppModel) tea.Cmd {
+			m.Output.Searching = true
+			return m.Output.Search.Focus()
+		}},
+	{ID: "output.next-match", Views: onOutput, Keys: []string{"n"}, Help: "next/prev match", Title: "Jump to the next match",
+		Run: func(m *AppModel) tea.Cmd { m.Output.jumpMatch(1); return nil }},
+	{ID: "output.prev-match", Views: onOutput, Keys: []string{"N"}, Help: "next/prev match", Title: "Jump to the previous match",
+		Run: func(m *AppModel) tea.Cmd { m.Output.jumpMatch(-1); return nil }},
+	{ID: "output.copy", Views: onOutput, Keys: []string{"y"}, Help: "copy", Title: "Copy the operation's output",
+		Run: func(m *AppModel) tea.Cmd {
+			if run, _ := m.Output.selected(); run != nil {
+				return copyOutputCmd(run)
+			}
+			return nil
+		}},
+	{ID: "output.back", Views: onOutput, Keys: []string{"esc", "q"}, Help: "back", Title: "Clear the search, else go back to the dashboard",
+		Run: func(m *AppModel) tea.Cmd {
+			o := &m.Output
+			if o.Search.Value() != "" {
+				o.Search.SetValue("")
+				o.shown = 0
+				o.syncOutput()
+				return nil
+			}
+			m.ActiveView = ViewDashboard
+			return nil
+		}},
+
+	// --- Everywhere ---
+	{ID: "palette", Views: everywhere, Keys: []string{"ctrl+p"}, Help: "commands", Title: "Search commands",
+		Run: func(m *AppModel) tea.Cmd { return m.openPalette() }},
+	{ID: "help", Views: everywhere, Keys: []string{"?"}, Help: "help", Title: "Show every key",
+		Run: func(m *AppModel) tea.Cmd { m.HelpVisible = true; return nil }},
+	// q goes back from the diff and output views.
+	{ID: "quit", Views: []View{ViewDashboard, ViewDiscovery, ViewCloneOptions}, Keys: []string{"q"}, Help: "quit", Title: "Quit",
+		Run: func(m *AppModel) tea.Cmd { return tea.Quit }},
+}
+
+// moveCursor moves the dashboard cursor by delta rows, within bounds.
+func (m *AppModel) moveCursor(delta int) {
+	m.Cursor = max(min(m.Cursor+delta, len(m.filteredRows())-1), 0)
+}
+
+// copySelected copies what ("path", "remote" or "web") of the selected
+// repo.
+func (m *AppModel) copySelected(what string) tea.Cmd {
+	if row, ok := m.selectedRow(); ok {
+		return copyCmd(row, what)
+	}
+	return nil
+}
+
+// shownRows returns the repos the table shows, without section headers.
+func (m *AppModel) shownRows() []RepoRow {
+	var rows []RepoRow
+	for _, fr := range m.filteredRows() {
+		if fr.group == nil {
+			rows = append(rows, fr.row)
+		}
+	}
+	return rows
+}
+
+// Keymap is the commands with their bindings resolved against the
+// user's overrides.
+type Keymap struct {
+	commands []*action                   // in help bar order
+	bound    map[string][]string         // command ID -> keys in effect
+	byKey    map[View]map[string]*action // key -> command, per view
+	// Problems are conflicts and mistakes in the [keys] config, for the
+	// action log.
+	Problems []string
+}
+
+// newKeymap binds every command of cmds to its default keys, then applies
+// the overrides (command ID -> keys). An override takes its keys from any
+// command holding them by default; when two overrides claim the same key
+// in a view, the first by name keeps it.
+func newKeymap(cmds []*action, overrides map[string][]string) *Keymap {
+	km := &Keymap{
+		commands: cmds,
+		bound:    make(map[string][]string, len(cmds)),
+		byKey:    make(map[View]map[string]*action),
+	}
+	byID := make(map[string]*action, len(cmds))
+	for _, c := range cmds {
+		byID[c.ID] = c
+		km.bound[c.ID] = c.Keys
+	}
+
+	ids := make([]string, 0, len(overrides))
+	for id := range overrides {
+		if _, ok := byID[id]; !ok {
+			km.Problems = append(km.Problems, fmt.Sprintf("keys: unknown command %q", id))
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		keys := make([]string, len(overrides[id]))
+		for i, k := range overrides[id] {
+			keys[i] = normalizeKey(k)
+		}
+		km.bound[id] = keys
+	}
+
+	// Overrides claim their keys first, then the defaults fill in.
+	claim := func(c *action, key string, overridden bool) {
+		for _, v := range c.Views {
+			if km.byKey[v] == nil {
+				km.byKey[v] = make(map[string]*action)
+			}
+			holder, taken := km.byKey[v][key]
+			if !taken {
+				km.byKey[v][key] = c
+				continue
+			}
+			if holder == c {
+				continue
+			}
+			if overridden {
+				km.Problems = append(km.Problems, fmt.Sprintf("keys: %s is bound to both %s and %s; %s keeps it",
+					keyLabel(key), holder.ID, c.ID, holder.ID))
+			} else {
+				km.Problems = append(km.Problems, fmt.Sprintf("keys: %s now runs %s instead of %s",
+					keyLabel(key), holder.ID, c.ID))
+			}
+			km.bound[c.ID] = without(km.bound[c.ID], key)
+		}
+	}
+	for _, id := range ids {
+		for _, key := range km.bound[id] {
+			claim(byID[id], key, true)
+		}
+	}
+	for _, c := range cmds {
+		if _, ok := overrides[c.ID]; ok {
+			continue
+		}
+		for _, key := range km.bound[c.ID] {
+			claim(c, key, false)
+		}
+	}
+	return km
+}
+
+func without(keys []string, key string) []string {
+	var out []string
+	for _, k := range keys {
+		if k != key {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// normalizeKey spells a configured key the way tea.KeyMsg.String() does.
+func normalizeKey(key string) string {
+	switch strings.ToLower(key) {
+	case "space":
+		return " "
+	case "esc", "escape":
+		return "esc"
+	case "return":
+		return "enter"
+	}
+	return key
+}
+
+// keyLabel spells a key for the help bar.
+func keyLabel(key string) string {
+	switch key {
+	case " ":
+		return "space"
+	case "enter":
+		return "↵"
+	}
+	return key
+}
+
+// lookup returns the command bound to key in view v, if any and enabled.
+func (km *Keymap) lookup(m *AppModel, v View, key string) *action {
+	c := km.byKey[v][key]
+	if c == nil || !c.enabled(m) {
+		return nil
+	}
+	return c
+}
+
+// command returns the command with the given ID, or nil.
+func (km *Keymap) command(id string) *action {
+	for _, c := range km.commands {
+		if c.ID == id {
+			return c
+		}
+	}
+	return nil
+}
+
+// Keys returns the keys bound to a command.
+func (km *Keymap) Keys(id string) []string {
+	return km.bound[id]
+}
+
+// commandsFor returns the enabled commands of view v in help bar order.
+func (m *AppModel) commandsFor(v View) []*action {
+	var out []*action
+	for _, c := range m.Keys.commands {
+		if c.in(v) && c.enabled(m) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// keyHelp renders help bar entries for the commands of view v, each help
+// label once with the first key of every command sharing it ("j/k:nav").
+// With ids, only those commands are included.
+func (m *AppModel) keyHelp(v View, ids ...string) string {
+	only := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		only[id] = true
+	}
+	var labels []string
+	keys := make(map[string][]string)
+	for _, c := range m.commandsFor(v) {
+		bound := m.Keys.Keys(c.ID)
+		if c.Help == "" || len(bound) == 0 || len(ids) > 0 && !only[c.ID] {
+			continue
+		}
+		if _, ok := keys[c.Help]; !ok {
+			labels = append(labels, c.Help)
+		}
+		keys[c.Help] = append(keys[c.Help], keyLabel(bound[0]))
+	}
+	entries := make([]string, len(labels))
+	for i, l := range labels {
+		entries[i] = strings.Join(keys[l], "/") + ":" + l
+	}
+	return "  " + strings.Join(entries, "  ")
+}
+
+// renderHelp draws the ? overlay: every command of the active view with
+// its keys, side by side in as many columns as the height needs.
+func (m AppModel) renderHelp() string {
+	view := m.ActiveView
+	var entries []string
+	width := 0
+	for _, c := range m.commandsFor(view) {
+		labels := make([]string, len(m.Keys.Keys(c.ID)))
+		for i, k := range m.Keys.Keys(c.ID) {
+			labels[i] = keyLabel(k)
+		}
+		key := strings.Join(labels, " ")
+		if key == "" {
+			key = "—"
+		}
+		width = max(width, len([]rune(key)))
+		entries = append(entries, key+"\x00"+c.Title)
+	}
+
+	perColumn := max(m.Height-8, 8)
+	nColumns := max((len(entries)+perColumn-1)/perColumn, 1)
+	perColumn = (len(entries) + nColumns - 1) / nColumns
+	// Titles share what's left of the width once the keys are laid out.
+	titleWidth := min(max((m.Width-8)/max(nColumns, 1)-width-6, 24), 60)
+	var columns []string
+	for start := 0; start < len(entries); start += perColumn {
+		var lines []string
+		for _, e := range entries[start:min(start+perColumn, len(entries))] {
+			key, title, _ := strings.Cut(e, "\x00")
+			lines = append(lines, styleCursor.Render(fmt.Sprintf("%-*s", width, key))+"  "+truncate(title, titleWidth))
+		}
+		if len(columns) > 0 {
+			columns = append(columns, "    ")
+		}
+		columns = append(columns, strings.Join(lines, "\n"))
+	}
+	title := "Dashboard keys"
+	switch view {
+	case ViewDiscovery:
+		title = "Discovery keys"
+	case ViewDiff:
+		title = "Diff keys"
+	case ViewOutput:
+		title = "Output keys"
+	}
+	body := lipgloss.JoinHorizontal(lipgloss.Top, columns...)
+	lines := []string{styleHeader.Render(title), "", body, "",
+		styleDim.Render("remap in ~/.config/gee/config.toml under [keys]  ctrl+p:search commands  any key:close")}
+	return renderOverlay(lines)
+}
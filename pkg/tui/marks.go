@@ -0,0 +1,129 @@
+package tui
+
+// targetRows returns the repos an action applies to: the marked ones, in
+// table order, or the one under the cursor when nothing is marked. Missing
+// repos are skipped.
+func (m *AppModel) targetRows() []RepoRow {
+	var rows []RepoRow
+	for _, r := range m.managedRows() {
+		if !r.Missing {
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// managedRows is targetRows with missing repos kept, for actions on the
+// cache entries rather than the repos (unpin, forget, hide).
+func (m *AppModel) managedRows() []RepoRow {
+	var rows []RepoRow
+	if len(m.Marked) > 0 {
+		for _, r := range m.Rows {
+			if m.Marked[r.Path] {
+				rows = append(rows, r)
+			}
+		}
+		return rows
+	}
+	if row, ok := m.selectedRow(); ok {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// toggleMark marks or unmarks the repo under the cursor.
+func (m *AppModel) toggleMark() {
+	row, ok := m.selectedRow()
+	if !ok {
+		return
+	}
+	if m.Marked[row.Path] {
+		delete(m.Marked, row.Path)
+	} else {
+		m.Marked[row.Path] = true
+	}
+}
+
+// inRange reports whether filtered row i lies between the range anchor (V)
+// and the cursor.
+func (m *AppModel) inRange(i int) bool {
+	if m.RangeAnchor < 0 {
+		return false
+	}
+	lo, hi := min(m.RangeAnchor, m.Cursor), max(m.RangeAnchor, m.Cursor)
+	return i >= lo && i <= hi
+}
+
+// toggleRange starts a range at the cursor or, when one is open, marks
+// every row between its anchor and the cursor.
+func (m *AppModel) toggleRange() {
+	if m.RangeAnchor < 0 {
+		m.RangeAnchor = m.Cursor
+		return
+	}
+	for i, fr := range m.filteredRows() {
+		if fr.group == nil && m.inRange(i) {
+			m.Marked[fr.row.Path] = true
+		}
+	}
+	m.RangeAnchor = -1
+}
+
+// invertMarks flips the mark on every row the filter shows.
+func (m *AppModel) invertMarks() {
+	for _, fr := range m.filteredRows() {
+		if fr.group != nil {
+			continue
+		}
+		if m.Marked[fr.row.Path] {
+			delete(m.Marked, fr.row.Path)
+		} else {
+			m.Marked[fr.row.Path] = true
+		}
+	}
+}
+
+// markFiltered marks every row the filter shows.
+func (m *AppModel) markFiltered() {
+	for _, fr := range m.filteredRows() {
+		if fr.group == nil {
+			m.Marked[fr.row.Path] = true
+		}
+	}
+}
+
+func (m *AppModel) clearMarks() {
+	for path := range m.Marked {
+		delete(m.Marked, path)
+	}
+}
+
+// togglePins pins every target, or unpins them all when they already are.
+func (m *AppModel) togglePins(targets []RepoRow) {
+	if len(targets) == 0 {
+		return
+	}
+	unpin := true
+	for _, r := range targets {
+		unpin = unpin && r.Pinned
+	}
+	for _, r := range targets {
+		i := m.rowIndexByPath(r.Path)
+		if i < 0 {
+			continue
+		}
+		if unpin {
+			m.Cache.Unpin(r.Path)
+		} else {
+			m.Cache.Pin(r.Path)
+		}
+		m.Rows[i].Pinned = !unpin
+		m.Rows[i].Hidden = m.Rows[i].Hidden && unpin // pinning restores
+	}
+	if unpin {
+		m.logOnRepos("unpinned", targets)
+	} else {
+		m.logOnRepos("pinned", targets)
+	}
+	m.Cache.Save()
+}
@@ -1,64 +1,167 @@
 package tui
 
-import "gee/pkg/ui"
+import (
+	"time"
+
+	"gee/pkg/discovery"
+	"gee/pkg/gitdir"
+	"gee/pkg/ui"
+	"gee/pkg/util"
+)
 
 // StatusResultMsg delivers one repo's porcelain status result into the
 // bubbletea Update loop. Sent once per repo during a status refresh.
 type StatusResultMsg struct {
-	Index   int
-	Name    string
-	Summary ui.StatusSummary
-	Failed  bool
+	Path     string // repo identity; rows are matched by path, never by index
+	Name     string
+	Summary  ui.StatusSummary
+	Snapshot util.StatusSnapshot // Summary plus last commit time etc., for the status store
+	Failed   bool
 }
 
 // StatusRefreshDoneMsg signals that every repo has reported status and
 // the refresh channel is closed.
 type StatusRefreshDoneMsg struct{}
 
-// PullResultMsg delivers the result of a pull on a single repo.
+// PullResultMsg delivers the result of a pull, fetch or push on a single
+// repo.
 type PullResultMsg struct {
-	Index  int
-	Name   string
-	Stdout string
-	Stderr string
-	Failed bool
+	Op       int    // batch id from startOp
+	Run      int    // output view entry
+	Command  string // "pull", "fetch" or "push"
+	Name     string
+	Path     string
+	Stdout   string
+	Stderr   string
+	Failed   bool
+	Duration time.Duration
 }
 
 // ExecResultMsg delivers the result of an exec on a single repo.
 type ExecResultMsg struct {
-	Index  int
-	Name   string
-	Stdout string
-	Stderr string
-	Failed bool
+	Op       int // batch id from startOp
+	Run      int // output view entry
+	Name     string
+	Path     string
+	Stdout   string
+	Stderr   string
+	Failed   bool
+	Duration time.Duration
+}
+
+// GitActionMsg delivers the result of a stage, unstage or commit in one
+// repo. Output is what was done, e.g. git's commit summary.
+type GitActionMsg struct {
+	Verb     string // "stage", "unstage", "commit" or "amend"
+	Name     string
+	Path     string
+	Output   string
+	Err      error
+	Duration time.Duration
+}
+
+// OutputMsg carries a chunk of a running operation's output.
+type OutputMsg struct {
+	Run  int
+	Data []byte
+}
+
+// BranchesLoadedMsg delivers the branch picker's targets' branches, by
+// repo path.
+type BranchesLoadedMsg struct {
+	Refs   map[string][]util.BranchRef
+	Errors map[string]error
+}
+
+// CommitMessageMsg delivers HEAD's message to prefill an amend.
+type CommitMessageMsg struct {
+	Message string
+}
+
+// HistoryRecordedMsg reports that a finished batch was appended to the
+// history log.
+type HistoryRecordedMsg struct {
+	ID       int
+	Command  string
+	Label    string
+	Failures int
+	Err      error
+}
+
+// ActionDoneMsg reports the outcome of a quick one-off action (open in
+// browser, copy to clipboard) for the action log.
+type ActionDoneMsg struct {
+	Text string
+}
+
+// ClipboardMsg asks Update to copy through the terminal: Seq is the OSC52
+// sequence to write, Done the action log entry once it is written.
+type ClipboardMsg struct {
+	Seq  string
+	Done string
 }
 
-// RemoteRepo represents a repository discovered from gh or glab.
-type RemoteRepo struct {
-	FullName    string
-	Description string
-	CloneURL    string
-	Private     bool
+// ClipboardDoneMsg reports that a ClipboardMsg's sequence was written.
+type ClipboardDoneMsg struct {
+	Text string
 }
 
-// DiscoveryResultMsg delivers the list of remote repos from gh/glab.
+// ToolDoneMsg reports that an external tool opened on a repo (editor,
+// shell, lazygit...) exited and the TUI has the terminal back.
+type ToolDoneMsg struct {
+	Tool string
+	Name string // the repo's display name
+	Path string
+	Err  error
+}
+
+// DiscoveryResultMsg delivers one source's remote repo listing.
 type DiscoveryResultMsg struct {
-	Repos []RemoteRepo
-	Error error
+	Source    discovery.Source
+	Repos     []discovery.Repo
+	FetchedAt time.Time
+	Cached    bool // served from the on-disk listing cache
+	Truncated bool // the provider's page cap cut the listing short
+	Error     error
 }
 
-// CloneBatchResultMsg reports one cloned repo's outcome.
-type CloneBatchResultMsg struct {
-	Name   string
-	Failed bool
+// DiscoveryOwnersMsg delivers the orgs or groups the user belongs to on a
+// provider.
+type DiscoveryOwnersMsg struct {
+	Provider  discovery.Provider
+	Owners    []string
+	Truncated bool
+	Error     error
 }
 
-// CloneBatchDoneMsg signals batch clone is complete.
-type CloneBatchDoneMsg struct {
-	Succeeded int
-	Failed    int
+// RepoDetailMsg delivers the detail pane's data for one repo.
+type RepoDetailMsg struct {
+	Path   string
+	Detail util.RepoDetail
+	Err    error
+}
+
+// DiffLoadedMsg delivers a repo's diff to the diff viewer.
+type DiffLoadedMsg struct {
+	Path  string
+	Files []util.DiffFile
+	Err   error
 }
 
+// CloneProgressMsg reports a change in one clone of a batch: it started,
+// git reported progress, or it finished.
+type CloneProgressMsg struct {
+	Index   int // into DiscoveryModel.Clones
+	State   cloneState
+	Phase   string // git's progress phase, e.g. "Receiving objects"
+	Percent int
+	Path    string // set when the clone starts and finishes
+	Reason  string // why a failed clone failed, from git's stderr
+}
+
+// CloneBatchDoneMsg signals batch clone is complete.
+type CloneBatchDoneMsg struct{}
+
 // TeleportMsg signals the user pressed Enter on a repo to teleport there.
 // The TUI quits and main prints the path to stdout for the shell wrapper.
 type TeleportMsg struct {
@@ -68,12 +171,30 @@ type TeleportMsg struct {
 // TickMsg triggers periodic status refresh.
 type TickMsg struct{}
 
-// RepoDiscoveredMsg is sent when the background scanner finds a new .git
-// directory that wasn't already in the cache.
+// RepoChangedMsg delivers a debounced batch of filesystem changes from the
+// watcher: repos whose status may have changed, and whether cache.json was
+// rewritten (e.g. by `gee add` in another shell).
+type RepoChangedMsg struct {
+	Paths        []string
+	CacheChanged bool
+}
+
+// initWatcherMsg bootstraps the watcher started by startWatcherCmd into the
+// model; w is nil when none could be started.
+type initWatcherMsg struct {
+	w *util.RepoWatcher
+}
+
+// WatchClosedMsg signals the watcher channel closed.
+type WatchClosedMsg struct{}
+
+// RepoDiscoveredMsg is sent when the background scanner finds a new repo
+// that wasn't already in the cache.
 type RepoDiscoveredMsg struct {
 	Name   string
 	Path   string
 	Remote string
+	Kind   gitdir.Kind
 }
 
 // ScanDoneMsg signals the filesystem scan is complete.
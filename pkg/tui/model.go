@@ -1,9 +1,12 @@
 package tui
 
 import (
+	"fmt"
 	"path/filepath"
+	"time"
 
 	"gee/pkg/command"
+	"gee/pkg/discovery"
 	"gee/pkg/types"
 	"gee/pkg/ui"
 	"gee/pkg/util"
@@ -18,62 +21,176 @@ type View int
 const (
 	ViewDashboard View = iota
 	ViewDiscovery
+	ViewDiff
+	ViewOutput
+
+	// Modes that take over a view's keys. They scope key bindings and are
+	// never the ActiveView.
+	ViewCopy         // the dashboard after y: what to copy
+	ViewCloneOptions // discovery after c: which clone option to toggle
 )
 
 // RepoRow holds display state for one repo in the dashboard table.
 type RepoRow struct {
-	Repo    types.Repo
-	Status  ui.StatusSummary
-	Pinned  bool
-	Failed  bool
-	Loading bool
-	Action  string // "pulling...", "exec...", or ""
+	Repo        types.Repo
+	Path        string // absolute repo root: the row's identity and cache key
+	DisplayName string // Repo.Name, or owner/name or a path suffix when names collide
+	Status      ui.StatusSummary
+	Pinned      bool
+	Missing     bool     // path no longer holds a repo
+	Hidden      bool     // hidden with H; listed only while ShowHidden
+	Tags        []string // from the cache, set with gee tag
+	Failed      bool
+	Loading     bool
+	Action      string // "pulling...", "exec...", or ""
+
+	// From the last status check (possibly a persisted snapshot).
+	CheckedAt     time.Time
+	LastCommit    time.Time
+	DefaultBranch string
 }
 
 // DiscoveryModel holds state for the remote discovery view.
 type DiscoveryModel struct {
-	Provider    string
-	RemoteRepos []RemoteRepo
-	Selected    map[int]bool
-	Cursor      int
-	Loading     bool
-	Error       error
+	Providers []discovery.Provider // empty when discovery is unavailable
+	Sources   []discovery.Source   // each provider's own repos, then its orgs/groups
+	Source    int                  // index into Sources
+
+	Repos     []discovery.Repo // full listing for the current source
+	FetchedAt time.Time
+	FromCache bool
+	Truncated bool // the listing stopped at the provider's page limit
+
+	ShowArchived bool
+	ShowForks    bool
+	Search       textinput.Model // "/" narrows the listing by name and description
+	Searching    bool
+	Language     string // "" shows every language
+	Visibility   string // "", "public" or "private"
+
+	local map[string]string // normalized remote -> path of repos already cloned
+
+	Selected map[string]cloneTarget // by host/full name, so selections survive filters and source switches
+	Cursor   int                    // index into visible()
+	Scroll   int                    // first repo of visible() shown
+	Loading  bool
+	Error    error
+
+	Cache  *discovery.Cache
+	TTL    time.Duration
+	owners map[discovery.Provider]bool // providers whose orgs/groups were requested
+
+	Options        util.CloneOptions // flags for the next clone batch
+	EditingOptions bool              // after c, keys toggle clone options until esc
+	Clones         []cloneJob        // current or last batch; nil shows the listing
+	Cloning        bool
+	cloneCh        <-chan CloneProgressMsg
+}
+
+// cloneState is where one clone of a batch is.
+type cloneState int
+
+const (
+	cloneQueued cloneState = iota
+	cloneRunning
+	cloneDone
+	cloneExists // the target directory already existed; treated as success
+	cloneFailed
+)
+
+// cloneJob is one repo of a clone batch.
+type cloneJob struct {
+	Target  cloneTarget
+	State   cloneState
+	Phase   string
+	Percent int
+	Path    string
+	Reason  string
+}
+
+// cloneTarget is a selected remote repo and the URL its provider chose to
+// clone it with.
+type cloneTarget struct {
+	Key  string // in DiscoveryModel.Selected
+	Repo discovery.Repo
+	URL  string
 }
 
 // AppModel is the root bubbletea model.
 type AppModel struct {
 	// Core — cache is the single source of truth
 	Cache     *util.RepoCache
+	Status    *util.StatusStore // last known status per repo, persisted between launches
+	Settings  util.Settings
 	RepoUtils *util.RepoUtils
 	Git       command.GitRepoOperation
-	Caps      Capabilities
 
 	// View routing
 	ActiveView View
 
+	// Key bindings, and the overlays generated from them
+	Keys        *Keymap
+	Palette     PaletteModel // ctrl+p
+	HelpVisible bool         // ?
+
 	// Dashboard
 	Rows        []RepoRow
 	Cursor      int
+	Scroll      int // first table row shown, kept while the cursor stays in view
 	StatusCh    <-chan StatusResultMsg
 	ScanCh      <-chan RepoDiscoveredMsg
+	Watcher     *util.RepoWatcher // nil until started, or if fsnotify is unavailable; falls back to polling
 	Filter      string
 	Filtering   bool
 	FilterInput textinput.Model
+	Marked      map[string]bool // paths marked with space; actions apply to these
+	Prefs       util.Prefs      // sort order, grouping and folded sections (S, =, z)
+	RangeAnchor int             // filtered index where V started a range, or -1
+	ShowHidden  bool            // list hidden repos too (.), to restore them
+
+	// Confirmation prompt for unpin, forget and hide (x, X, H)
+	Confirm *confirmation
+
+	// Commit overlay (c)
+	Commit CommitModel
+
+	// Branch picker (b)
+	Branches BranchModel
 
 	// Exec overlay
 	ExecInput  textinput.Model
 	ExecActive bool
 
+	// Copy prefix: after y, the next key picks what to copy
+	Yanking bool
+
+	// Detail pane for the selected repo (Tab)
+	Detail DetailModel
+
+	// Diff viewer (D)
+	Diff DiffModel
+
 	// Action log (recent results shown at bottom)
 	ActionLog []string
 
-	// Discovery (remote — gh/glab)
+	// Output view (O): recent operations with their full output
+	Output OutputModel
+
+	// In-flight pull/exec batches, keyed by op id, recorded in the history
+	// log once every repo has reported.
+	Ops    map[int]*opBatch
+	nextOp int
+
+	// Discovery (remote — gh, glab, Gitea, Bitbucket)
 	Discovery DiscoveryModel
 
 	// Terminal dimensions
 	Width  int
 	Height int
 
+	// Last left click, to tell a double-click (mouse.go)
+	lastClick mouseClick
+
 	// State
 	Refreshing bool
 	Scanning   bool
@@ -82,64 +199,164 @@ type AppModel struct {
 	SelectedPath string
 }
 
+// opBatch accumulates the per-repo results of one pull/exec batch.
+type opBatch struct {
+	Entry   util.HistoryEntry
+	Pending int
+}
+
 // NewAppModel creates a ready-to-use AppModel from the cache.
-func NewAppModel(cache *util.RepoCache) AppModel {
+func NewAppModel(cache *util.RepoCache, settings util.Settings) AppModel {
+	// The caller applies the theme for the TUI's output before this.
+	setStyles(ui.CurrentTheme())
+
 	git := command.GitRepoOperation{}
 	repoUtils := util.NewRepoUtils(git)
 
-	// Seed rows from cache for instant startup.
+	// Seed rows from cache and the last status snapshots for instant startup;
+	// the initial refresh updates them in the background.
+	status := util.LoadStatusStore()
 	cached := cache.All()
 	rows := make([]RepoRow, len(cached))
 	for i, c := range cached {
-		rows[i] = RepoRow{
-			Repo: types.Repo{
-				Name:   c.Name,
-				Path:   filepath.Dir(c.Path),
-				Remote: c.Remote,
-			},
-			Pinned:  c.Pinned,
-			Loading: true,
+		rows[i] = newRepoRow(c)
+		if snap, ok := status.Get(c.Path); ok {
+			rows[i].applySnapshot(snap)
 		}
 	}
-
-	caps := DetectCapabilities()
+	labelRows(rows)
 
 	filterInput := textinput.New()
 	filterInput.Placeholder = "filter repos..."
-	filterInput.CharLimit = 64
+	filterInput.CharLimit = 256
 
 	execInput := textinput.New()
 	execInput.Placeholder = "command to run..."
 	execInput.CharLimit = 256
 
+	overrides, invalid := settings.KeyBindings()
+	keys := newKeymap(withTools(toolActions(settings.ExternalTools())), overrides)
+	var actionLog []string
+	for _, name := range invalid {
+		actionLog = append(actionLog, fmt.Sprintf("keys: %s must be a key or a list of keys", name))
+	}
+	actionLog = append(actionLog, keys.Problems...)
+
 	return AppModel{
 		Cache:       cache,
+		Keys:        keys,
+		Palette:     PaletteModel{Input: newPaletteInput()},
+		ActionLog:   actionLog,
+		Status:      status,
+		Settings:    settings,
 		RepoUtils:   repoUtils,
 		Git:         git,
-		Caps:        caps,
 		Rows:        rows,
 		FilterInput: filterInput,
 		ExecInput:   execInput,
-		Discovery: DiscoveryModel{
-			Provider: DiscoveryProvider(caps),
-			Selected: make(map[int]bool),
-		},
+		Marked:      make(map[string]bool),
+		Prefs:       util.LoadPrefs(),
+		RangeAnchor: -1,
+		Commit:      CommitModel{Input: newCommitInput()},
+		Branches:    BranchModel{Input: newBranchInput()},
+		Output:      newOutputModel(),
+		Ops:         make(map[int]*opBatch),
+		Discovery:   newDiscoveryModel(settings.Discovery, settings.Clone),
 	}
 }
 
 // Init kicks off the initial status refresh, background scanner, and periodic tick.
 func (m AppModel) Init() tea.Cmd {
 	repos := m.repoSlice()
-	statusCmd, statusCh := refreshStatusCmd(repos, m.RepoUtils)
-	scanCmd, scanCh := scanLocalReposCmd(m.Cache)
+	statusCmd, statusCh := refreshStatusCmd(repos, m.RepoUtils, m.Status)
+	scanCmd, scanCh := scanLocalReposCmd(m.Cache, m.Settings.ScannerConfig())
 
-	return tea.Batch(
+	paths := make([]string, len(m.Rows))
+	for i, r := range m.Rows {
+		paths[i] = r.Path
+	}
+
+	cmds := []tea.Cmd{
 		statusCmd,
 		scanCmd,
-		tickCmd(),
+		startWatcherCmd(paths),
+		tickCmd(m.refreshInterval()),
 		func() tea.Msg { return initStatusChanMsg{ch: statusCh} },
 		func() tea.Msg { return initScanChanMsg{ch: scanCh} },
-	)
+	}
+	cmds = append(cmds, m.mouseCmd())
+	return tea.Batch(cmds...)
+}
+
+// Close releases the filesystem watcher. Call it once the program has
+// exited.
+func (m AppModel) Close() {
+	if m.Watcher != nil {
+		m.Watcher.Close()
+	}
+}
+
+// refreshInterval is the full-refresh period: slow when the watcher is
+// delivering per-repo changes, fast when polling is the only signal.
+func (m *AppModel) refreshInterval() time.Duration {
+	if m.Watcher != nil {
+		return fallbackInterval
+	}
+	return pollInterval
+}
+
+// newRepoRow builds a row for a cached repo whose status is not yet known.
+func newRepoRow(c util.CachedRepo) RepoRow {
+	return RepoRow{
+		Repo: types.Repo{
+			Name:   c.Name,
+			Path:   filepath.Dir(c.Path),
+			Remote: c.Remote,
+		},
+		Path:        c.Path,
+		DisplayName: c.Name,
+		Pinned:      c.Pinned,
+		Missing:     c.Missing,
+		Hidden:      c.Hidden,
+		Tags:        c.Tags,
+		Loading:     true,
+	}
+}
+
+// labelRows recomputes every row's DisplayName. Adding one repo can make an
+// existing name ambiguous, so this runs over the whole set.
+func labelRows(rows []RepoRow) {
+	repos := make([]util.CachedRepo, len(rows))
+	for i, r := range rows {
+		repos[i] = util.CachedRepo{Name: r.Repo.Name, Path: r.Path, Remote: r.Repo.Remote}
+	}
+	names := util.DisplayNames(repos)
+	for i := range rows {
+		rows[i].DisplayName = util.DisplayName(names, rows[i].Path)
+	}
+}
+
+// filterRepo is what the / filter matches the row against.
+func (r RepoRow) filterRepo() util.FilterRepo {
+	return util.FilterRepo{
+		Name:    r.DisplayName,
+		Path:    r.Path,
+		Remote:  r.Repo.Remote,
+		Pinned:  r.Pinned,
+		Hidden:  r.Hidden,
+		Tags:    r.Tags,
+		Missing: r.Missing,
+		Known:   !r.CheckedAt.IsZero() && !r.Failed,
+		Status:  r.Status,
+	}
+}
+
+// applySnapshot copies a status snapshot onto the row.
+func (r *RepoRow) applySnapshot(snap util.StatusSnapshot) {
+	r.Status = snap.Summary
+	r.CheckedAt = snap.CheckedAt
+	r.LastCommit = snap.LastCommit
+	r.DefaultBranch = snap.DefaultBranch
 }
 
 // repoSlice extracts []types.Repo from the current row state.
@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// doubleClickTime is how soon a second click on the same row must follow
+// the first to count as a double-click.
+const doubleClickTime = 400 * time.Millisecond
+
+// wheelRows is how far one wheel step scrolls a table.
+const wheelRows = 3
+
+// mouseClick is a left click on a table row.
+type mouseClick struct {
+	view View
+	row  int
+	at   time.Time
+}
+
+// headerSorts are the dashboard's sortable columns: a click on the header
+// between from and to sorts by the column, a second click goes back to
+// the default order. The columns are those of the header line in
+// viewDashboard.
+var headerSorts = []struct {
+	from, to int
+	sort     string
+}{
+	{8, 29, "name"},        // REPO
+	{45, 58, "behind"},     // SYNC
+	{58, 1 << 30, "dirty"}, // CHANGES
+}
+
+// mouseCmd turns mouse reporting on, if configured. Init calls it, and so
+// does Update after handing the terminal over, which turns it off.
+func (m *AppModel) mouseCmd() tea.Cmd {
+	if m.Settings.Mouse.Enabled {
+		return tea.EnableMouseCellMotion
+	}
+	return nil
+}
+
+// updateMouse handles clicks and the wheel. Overlays and text inputs keep
+// the mouse out, like they keep other keys out.
+func (m AppModel) updateMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.Commit.Active || m.Branches.Active || m.Palette.Active || m.HelpVisible || m.Confirm != nil {
+		return m, nil
+	}
+	switch m.ActiveView {
+	case ViewDashboard:
+		return m.mouseDashboard(msg)
+	case ViewDiscovery:
+		return m.mouseDiscovery(msg)
+	case ViewDiff:
+		d := &m.Diff
+		before := d.Viewport.YOffset
+		var cmd tea.Cmd
+		d.Viewport, cmd = d.Viewport.Update(msg)
+		if d.Viewport.YOffset != before {
+			d.focus = d.Viewport.YOffset
+		}
+		return m, cmd
+	case ViewOutput:
+		var cmd tea.Cmd
+		m.Output.Viewport, cmd = m.Output.Viewport.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m AppModel) mouseDashboard(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.ExecActive || m.Filtering || m.Yanking {
+		return m, nil
+	}
+	n := len(m.filteredRows())
+	table := m.dashboardTable(n)
+	if delta := wheelDelta(msg); delta != 0 {
+		scrollTable(&m.Scroll, &m.Cursor, delta, table.rows, n)
+		return m, m.syncDetail()
+	}
+	if !leftClick(msg) || msg.X >= table.width {
+		return m, nil
+	}
+
+	if msg.Y == table.top {
+		for _, h := range headerSorts {
+			if msg.X >= h.from && msg.X < h.to {
+				if m.Prefs.Sort == h.sort {
+					return m, m.setSort("")
+				}
+				return m, m.setSort(h.sort)
+			}
+		}
+		return m, nil
+	}
+
+	i, ok := table.rowAt(msg.Y)
+	if !ok {
+		return m, nil
+	}
+	m.Cursor = i
+	if m.doubleClick(ViewDashboard, i) {
+		// As enter: teleport, or fold a section.
+		return m, m.Keys.command("teleport").Run(&m)
+	}
+	return m, m.syncDetail()
+}
+
+func (m AppModel) mouseDiscovery(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	d := &m.Discovery
+	if d.EditingOptions || d.Clones != nil || d.Searching || d.Loading || d.Error != nil {
+		return m, nil
+	}
+	visible := d.visible()
+	table := m.discoveryTable(len(visible))
+	if delta := wheelDelta(msg); delta != 0 {
+		scrollTable(&d.Scroll, &d.Cursor, delta, table.rows, len(visible))
+		return m, nil
+	}
+	if !leftClick(msg) {
+		return m, nil
+	}
+	i, ok := table.rowAt(msg.Y)
+	if !ok {
+		return m, nil
+	}
+	d.Cursor = i
+	// The [ ] checkbox, after the cursor column.
+	if msg.X < 6 {
+		d.toggle(visible[i])
+	}
+	return m, nil
+}
+
+// rowAt returns the index of the row shown on screen line y.
+func (l tableLayout) rowAt(y int) (int, bool) {
+	i := y - l.top - 1
+	if i < 0 || i >= l.rows {
+		return 0, false
+	}
+	return l.offset + i, true
+}
+
+// doubleClick records a click on row of view v and reports whether it
+// completes a double-click.
+func (m *AppModel) doubleClick(v View, row int) bool {
+	now := time.Now()
+	last := m.lastClick
+	if last.view == v && last.row == row && now.Sub(last.at) < doubleClickTime {
+		m.lastClick = mouseClick{}
+		return true
+	}
+	m.lastClick = mouseClick{view: v, row: row, at: now}
+	return false
+}
+
+func leftClick(msg tea.MouseMsg) bool {
+	return msg.Action == tea.MouseActionPress && msg.Button == tea.MouseButtonLeft
+}
+
+// wheelDelta is how many rows a wheel event scrolls, or 0 for any other
+// event.
+func wheelDelta(msg tea.MouseMsg) int {
+	if msg.Action != tea.MouseActionPress {
+		return 0
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return -wheelRows
+	case tea.MouseButtonWheelDown:
+		return wheelRows
+	}
+	return 0
+}
+
+// scrollTable scrolls a table of n rows, showing rows of them, by delta,
+// taking the cursor along when it would leave the view.
+func scrollTable(scroll, cursor *int, delta, rows, n int) {
+	if rows <= 0 {
+		return
+	}
+	*scroll = max(min(*scroll+delta, n-rows), 0)
+	*cursor = min(max(*cursor, *scroll), *scroll+rows-1)
+}
@@ -0,0 +1,425 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gee/pkg/ui"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// outputRuns is how many per-repo operations the output view keeps.
+	outputRuns = 200
+	// outputRunBytes caps one operation's kept output; beyond it the
+	// oldest output is dropped.
+	outputRunBytes = 256 << 10
+	// outputListRows is how many operations the output view lists at once.
+	outputListRows = 8
+	// actionLogSize bounds the action log.
+	actionLogSize = 100
+)
+
+// opRun is one repo's part of an operation (a pull, fetch, push, exec,
+// stage, commit or branch switch) as the output view shows it.
+type opRun struct {
+	ID        int
+	Command   string
+	Args      string // the shell command for exec
+	Name      string
+	Path      string
+	Started   time.Time
+	Duration  time.Duration
+	Running   bool
+	Failed    bool
+	Output    []byte // stdout and stderr as written, interleaved
+	Truncated bool   // the start of Output was dropped
+}
+
+// OutputModel holds the output view (O) and the recent operations it
+// lists.
+type OutputModel struct {
+	Runs     ring[*opRun]
+	byID     map[int]*opRun
+	nextID   int
+	Selected int // run ID shown; 0 follows the newest
+	Viewport viewport.Model
+
+	Search    textinput.Model
+	Searching bool
+	matches   []int // viewport lines containing the search
+	shown     int   // run ID and output length last rendered into the viewport
+	shownLen  int
+
+	ch        chan OutputMsg // writers of running operations send here
+	listening bool
+}
+
+func newOutputModel() OutputModel {
+	search := textinput.New()
+	search.Placeholder = "search output..."
+	search.CharLimit = 128
+	return OutputModel{
+		Runs:     newRing[*opRun](outputRuns),
+		byID:     make(map[int]*opRun),
+		Viewport: viewport.New(0, 0),
+		Search:   search,
+		ch:       make(chan OutputMsg, 256),
+	}
+}
+
+// startRun records a new running operation and returns it with a writer
+// that streams its output into the view, plus the command that listens for
+// that output if nothing is listening yet.
+func (o *OutputModel) startRun(command, args string, row RepoRow) (*opRun, *outputWriter, tea.Cmd) {
+	o.nextID++
+	run := &opRun{
+		ID:      o.nextID,
+		Command: command,
+		Args:    args,
+		Name:    row.DisplayName,
+		Path:    row.Path,
+		Started: time.Now(),
+		Running: true,
+	}
+	o.add(run)
+	var listen tea.Cmd
+	if !o.listening {
+		o.listening = true
+		listen = waitForOutput(o.ch)
+	}
+	return run, &outputWriter{run: run.ID, ch: o.ch}, listen
+}
+
+// addFinished records an operation that ran without streaming.
+func (o *OutputModel) addFinished(command, name, path, output string, failed bool, duration time.Duration) {
+	o.nextID++
+	o.add(&opRun{
+		ID:       o.nextID,
+		Command:  command,
+		Name:     name,
+		Path:     path,
+		Started:  time.Now().Add(-duration),
+		Duration: duration,
+		Failed:   failed,
+		Output:   []byte(output),
+	})
+}
+
+func (o *OutputModel) add(run *opRun) {
+	if old, ok := o.Runs.Push(run); ok {
+		delete(o.byID, old.ID)
+		if o.Selected == old.ID {
+			o.Selected = 0
+		}
+	}
+	o.byID[run.ID] = run
+}
+
+// finishRun marks a run done. Output still in flight keeps appending.
+func (o *OutputModel) finishRun(id int, failed bool, duration time.Duration) {
+	if run, ok := o.byID[id]; ok {
+		run.Running = false
+		run.Failed = failed
+		run.Duration = duration
+	}
+}
+
+// appendOutput adds streamed output to its run, dropping the oldest bytes
+// past outputRunBytes.
+func (o *OutputModel) appendOutput(msg OutputMsg) {
+	run, ok := o.byID[msg.Run]
+	if !ok {
+		return
+	}
+	run.Output = append(run.Output, msg.Data...)
+	if over := len(run.Output) - outputRunBytes; over > 0 {
+		run.Output = append(run.Output[:0], run.Output[over:]...)
+		run.Truncated = true
+	}
+}
+
+// newest returns the runs, newest first.
+func (o *OutputModel) newest() []*opRun {
+	runs := make([]*opRun, o.Runs.Len())
+	for i := range runs {
+		runs[i] = o.Runs.At(o.Runs.Len() - 1 - i)
+	}
+	return runs
+}
+
+// selected returns the run the view shows and its index in newest().
+func (o *OutputModel) selected() (*opRun, int) {
+	runs := o.newest()
+	for i, r := range runs {
+		if r.ID == o.Selected {
+			return r, i
+		}
+	}
+	if len(runs) == 0 {
+		return nil, 0
+	}
+	return runs[0], 0
+}
+
+// outputWriter streams a running operation's output to the view. exec
+// writes stdout and stderr from separate goroutines; the channel keeps that
+// safe, and each write is copied since the caller reuses its buffer.
+type outputWriter struct {
+	run int
+	ch  chan<- OutputMsg
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	w.ch <- OutputMsg{Run: w.run, Data: append([]byte(nil), p...)}
+	return len(p), nil
+}
+
+// waitForOutput reads the next chunk of streamed output. The channel is
+// never closed; Update asks for the next chunk after each one.
+func waitForOutput(ch <-chan OutputMsg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+// sizeOutput fits the output viewport below the list of runs.
+func (m *AppModel) sizeOutput() {
+	m.Output.Viewport.Width = max(m.Width-2, 20)
+	m.Output.Viewport.Height = max(m.Height-outputListRows-8, 5)
+}
+
+// syncOutput re-renders the viewport when the selected run or its output
+// changed, following the end of the output while it was scrolled there.
+func (o *OutputModel) syncOutput() {
+	run, _ := o.selected()
+	if run == nil {
+		o.Viewport.SetContent("")
+		o.shown, o.shownLen = 0, 0
+		return
+	}
+	if run.ID == o.shown && len(run.Output) == o.shownLen {
+		return
+	}
+	follow := run.ID != o.shown || o.Viewport.AtBottom()
+	o.shown, o.shownLen = run.ID, len(run.Output)
+
+	query := strings.ToLower(strings.TrimSpace(o.Search.Value()))
+	o.matches = o.matches[:0]
+	lines := outputLines(run.Output)
+	if run.Truncated {
+		lines = append([]string{styleDim.Render(fmt.Sprintf("… earlier output dropped (kept the last %d KiB)", outputRunBytes>>10))}, lines...)
+	}
+	for i, line := range lines {
+		if query != "" && strings.Contains(strings.ToLower(line), query) {
+			o.matches = append(o.matches, i)
+			lines[i] = highlightQuery(line, query)
+		}
+	}
+	o.Viewport.SetContent(strings.Join(lines, "\n"))
+	if follow && query == "" {
+		o.Viewport.GotoBottom()
+	}
+}
+
+// refreshOutput re-renders the output view when it is on screen; otherwise
+// that waits until it is opened.
+func (m *AppModel) refreshOutput() {
+	if m.ActiveView == ViewOutput {
+		m.Output.syncOutput()
+	}
+}
+
+// outputLines splits raw output into display lines. A carriage return
+// starts the line over, as progress meters expect.
+func outputLines(out []byte) []string {
+	text := strings.ReplaceAll(string(out), "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if j := strings.LastIndexByte(line, '\r'); j >= 0 {
+			lines[i] = line[j+1:]
+		}
+	}
+	return lines
+}
+
+// highlightQuery emphasizes every case-insensitive occurrence of query
+// (already lowercased) in line.
+func highlightQuery(line, query string) string {
+	lower := strings.ToLower(line)
+	if len(lower) != len(line) {
+		// Case folding changed byte offsets; leave the line plain.
+		return line
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i])
+		b.WriteString(styleMatch.Render(line[i : i+len(query)]))
+		line, lower = line[i+len(query):], lower[i+len(query):]
+	}
+}
+
+// jumpMatch scrolls to the next (dir > 0) or previous search match.
+func (o *OutputModel) jumpMatch(dir int) {
+	y := o.Viewport.YOffset
+	if dir > 0 {
+		for _, l := range o.matches {
+			if l > y {
+				o.Viewport.SetYOffset(l)
+				return
+			}
+		}
+		return
+	}
+	for i := len(o.matches) - 1; i >= 0; i-- {
+		if o.matches[i] < y {
+			o.Viewport.SetYOffset(o.matches[i])
+			return
+		}
+	}
+}
+
+// selectRun moves the selection by step runs; positive is older.
+func (o *OutputModel) selectRun(step int) {
+	runs := o.newest()
+	if len(runs) == 0 {
+		return
+	}
+	_, i := o.selected()
+	i = min(max(i+step, 0), len(runs)-1)
+	o.Selected = runs[i].ID
+	if i == 0 {
+		o.Selected = 0
+	}
+}
+
+func (m AppModel) updateOutput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	o := &m.Output
+	if o.Searching {
+		switch msg.String() {
+		case "enter":
+			o.Searching = false
+			o.Search.Blur()
+			o.shown = 0
+			o.syncOutput()
+			o.jumpMatch(1)
+		case "esc":
+			o.Searching = false
+			o.Search.Blur()
+			o.Search.SetValue("")
+			o.shown = 0
+			o.syncOutput()
+		default:
+			var cmd tea.Cmd
+			o.Search, cmd = o.Search.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if c := m.Keys.lookup(&m, ViewOutput, msg.String()); c != nil {
+		return m, c.Run(&m)
+	}
+	return m, nil
+}
+
+// copyOutputCmd puts a run's output on the clipboard.
+func copyOutputCmd(run *opRun) tea.Cmd {
+	text := strings.Join(outputLines(run.Output), "\n")
+	label := fmt.Sprintf("%s %s", run.Command, run.Name)
+	return func() tea.Msg {
+		return copyText(text, fmt.Sprintf("copied the output of %s", label))
+	}
+}
+
+func (m AppModel) viewOutput() string {
+	var b strings.Builder
+	o := &m.Output
+	runs := o.newest()
+	sel, selIdx := o.selected()
+
+	running := 0
+	for _, r := range runs {
+		if r.Running {
+			running++
+		}
+	}
+	header := styleHeader.Render(fmt.Sprintf(" Output — %d recent operations", len(runs)))
+	if running > 0 {
+		header += styleDim.Render(fmt.Sprintf("  ⟳ %d running", running))
+	}
+	b.WriteString(header + "\n\n")
+
+	if len(runs) == 0 {
+		b.WriteString(styleDim.Render("  nothing has run yet — pull, fetch, push or exec from the dashboard") + "\n")
+		b.WriteString(m.renderKeyOverlay() + "\n" + styleHelpBar.Render(m.keyHelp(ViewOutput, "output.back", "palette", "help")))
+		return b.String()
+	}
+
+	start := 0
+	if selIdx >= outputListRows {
+		start = selIdx - outputListRows + 1
+	}
+	for i := start; i < len(runs) && i < start+outputListRows; i++ {
+		b.WriteString(renderRunLine(runs[i], i == selIdx) + "\n")
+	}
+	if len(runs) > outputListRows {
+		b.WriteString(styleDim.Render(fmt.Sprintf("  (%d/%d)", selIdx+1, len(runs))) + "\n")
+	}
+
+	title := sel.Command
+	if sel.Args != "" {
+		title += ": " + sel.Args
+	}
+	b.WriteString("\n" + styleTableHead.Render(fmt.Sprintf("  %s — %s", title, sel.Name)) + "\n")
+	b.WriteString(lipgloss.NewStyle().PaddingLeft(2).Render(o.Viewport.View()) + "\n")
+
+	switch {
+	case o.Searching:
+		b.WriteString("  / " + o.Search.View() + "\n")
+	case o.Search.Value() != "":
+		b.WriteString(styleDim.Render(fmt.Sprintf("  search %q: %d matching lines", o.Search.Value(), len(o.matches))) + "\n")
+	default:
+		if n := len(m.ActionLog); n > 0 {
+			b.WriteString(styleDim.Render("  "+m.ActionLog[n-1]) + "\n")
+		}
+	}
+	b.WriteString(m.renderKeyOverlay() + styleHelpBar.Render(m.keyHelp(ViewOutput)))
+	return b.String()
+}
+
+// renderRunLine shows one operation in the output view's list.
+func renderRunLine(run *opRun, selected bool) string {
+	cursor := "  "
+	if selected {
+		cursor = styleCursor.Render("▸ ")
+	}
+	var icon, took string
+	switch {
+	case run.Running:
+		icon = styleAction.Render("⟳")
+		took = styleAction.Render(fmt.Sprintf("%-8s", "running"))
+	case run.Failed:
+		icon = ui.SymbolError()
+		took = fmt.Sprintf("%-8s", fmt.Sprintf("%.1fs", run.Duration.Seconds()))
+	default:
+		icon = ui.SymbolSuccess()
+		took = fmt.Sprintf("%-8s", fmt.Sprintf("%.1fs", run.Duration.Seconds()))
+	}
+	label := run.Command
+	if run.Args != "" {
+		label += ": " + run.Args
+	}
+	return fmt.Sprintf("%s%s %-24s %s %s %s", cursor, icon, truncate(label, 24),
+		ui.StyleRepoName.Render(fmt.Sprintf("%-20s", run.Name)), took, styleDim.Render(ui.Age(run.Started)+" ago"))
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gee/pkg/util"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteRows is how many commands the palette lists at once.
+const paletteRows = 12
+
+// PaletteModel holds the command palette overlay (ctrl+p).
+type PaletteModel struct {
+	Active bool
+	Input  textinput.Model
+	Cursor int // index into matches()
+	view   View
+}
+
+// paletteMatch is a command that matches the palette's query.
+type paletteMatch struct {
+	cmd       *action
+	score     int
+	positions []int // runes of the title
+}
+
+func newPaletteInput() textinput.Model {
+	input := textinput.New()
+	input.Placeholder = "command..."
+	input.CharLimit = 64
+	return input
+}
+
+// openPalette shows the palette for the active view's commands.
+func (m *AppModel) openPalette() tea.Cmd {
+	p := &m.Palette
+	p.Active = true
+	p.view = m.ActiveView
+	p.Cursor = 0
+	p.Input.SetValue("")
+	return p.Input.Focus()
+}
+
+func (m *AppModel) closePalette() {
+	m.Palette.Active = false
+	m.Palette.Input.Blur()
+}
+
+// paletteMatches returns the view's commands matching the query by title
+// or name, best first; with no query, in help bar order. The palette
+// itself isn't listed.
+func (m *AppModel) paletteMatches() []paletteMatch {
+	query := strings.TrimSpace(m.Palette.Input.Value())
+	var out []paletteMatch
+	for _, c := range m.commandsFor(m.Palette.view) {
+		if c.ID == "palette" {
+			continue
+		}
+		score, positions, ok := util.FuzzyMatch(query, c.Title)
+		if !ok {
+			if score, _, ok = util.FuzzyMatch(query, c.ID); !ok {
+				continue
+			}
+		}
+		out = append(out, paletteMatch{cmd: c, score: score, positions: positions})
+	}
+	if query != "" {
+		sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	}
+	return out
+}
+
+func (m AppModel) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.Palette
+	matches := m.paletteMatches()
+	switch msg.String() {
+	case "esc":
+		m.closePalette()
+		return m, nil
+	case "up", "ctrl+p", "ctrl+k":
+		if p.Cursor > 0 {
+			p.Cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n", "ctrl+j":
+		if p.Cursor < len(matches)-1 {
+			p.Cursor++
+		}
+		return m, nil
+	case "enter":
+		m.closePalette()
+		if p.Cursor >= len(matches) || m.ActiveView != p.view {
+			return m, nil
+		}
+		cmd := matches[p.Cursor].cmd.Run(&m)
+		if m.ActiveView == ViewDashboard {
+			cmd = tea.Batch(cmd, m.syncDetail())
+		}
+		return m, cmd
+	}
+	var cmd tea.Cmd
+	p.Input, cmd = p.Input.Update(msg)
+	p.Cursor = 0
+	return m, cmd
+}
+
+// renderPalette draws the command palette overlay.
+func (m AppModel) renderPalette() string {
+	p := &m.Palette
+	lines := []string{styleHeader.Render("Commands"), p.Input.View()}
+	matches := m.paletteMatches()
+	if len(matches) == 0 {
+		lines = append(lines, styleDim.Render("no matching commands"))
+	}
+
+	width := 0
+	for _, pm := range matches {
+		width = max(width, len([]rune(pm.cmd.Title)))
+	}
+	width = min(width, 64)
+
+	start := 0
+	if p.Cursor >= paletteRows {
+		start = p.Cursor - paletteRows + 1
+	}
+	for i := start; i < len(matches) && i < start+paletteRows; i++ {
+		pm := matches[i]
+		cursor := "  "
+		if i == p.Cursor {
+			cursor = styleCursor.Render("▸ ")
+		}
+		title := truncate(pm.cmd.Title, width)
+		line := cursor + highlightMatches(title, pm.positions)
+		if pad := width - len([]rune(title)); pad > 0 {
+			line += strings.Repeat(" ", pad)
+		}
+		labels := make([]string, 0, len(m.Keys.Keys(pm.cmd.ID)))
+		for _, k := range m.Keys.Keys(pm.cmd.ID) {
+			labels = append(labels, keyLabel(k))
+		}
+		lines = append(lines, line+"  "+styleDim.Render(strings.Join(labels, " ")))
+	}
+	if len(matches) > paletteRows {
+		lines = append(lines, styleDim.Render(fmt.Sprintf("(%d/%d)", p.Cursor+1, len(matches))))
+	}
+	lines = append(lines, styleDim.Render("type to search  ↑/↓:select  enter:run  esc:cancel"))
+	return renderOverlay(lines)
+}
@@ -0,0 +1,33 @@
+package tui
+
+// ring is a fixed-capacity buffer that drops its oldest item when full.
+type ring[T any] struct {
+	items []T
+	start int // index of the oldest item once the buffer has wrapped
+}
+
+func newRing[T any](capacity int) ring[T] {
+	return ring[T]{items: make([]T, 0, capacity)}
+}
+
+// Push appends v, returning the item it evicted, if any.
+func (r *ring[T]) Push(v T) (evicted T, ok bool) {
+	if len(r.items) < cap(r.items) {
+		r.items = append(r.items, v)
+		return evicted, false
+	}
+	evicted = r.items[r.start]
+	r.items[r.start] = v
+	r.start = (r.start + 1) % len(r.items)
+	return evicted, true
+}
+
+// Len returns the number of items held.
+func (r *ring[T]) Len() int {
+	return len(r.items)
+}
+
+// At returns the i-th item, oldest first.
+func (r *ring[T]) At(i int) T {
+	return r.items[(r.start+i)%len(r.items)]
+}
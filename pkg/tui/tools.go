@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gee/pkg/util"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toolKeys are the default bindings of the built-in tools; tools from
+// config.toml are bound under [keys] as tool.<name>.
+var toolKeys = map[string][]string{
+	"editor":  {"E"},
+	"shell":   {"!"},
+	"lazygit": {"L"},
+	"tig":     {"T"},
+}
+
+var toolTitles = map[string]string{
+	"editor": "Open the selected repo in $EDITOR",
+	"shell":  "Open a shell in the selected repo",
+}
+
+// toolActions makes a dashboard command, tool.<name>, for each tool. A
+// tool whose program isn't installed stays out of the help and the
+// palette.
+func toolActions(tools []util.Tool) []*action {
+	actions := make([]*action, len(tools))
+	for i, tool := range tools {
+		title := toolTitles[tool.Name]
+		if title == "" {
+			title = "Open the selected repo in " + tool.Name
+		}
+		installed := toolInstalled(tool.Command)
+		actions[i] = &action{
+			ID: "tool." + tool.Name, Views: onDashboard, Keys: toolKeys[tool.Name], Help: "tools", Title: title,
+			Enabled: func(m *AppModel) bool { return installed },
+			Run: func(m *AppModel) tea.Cmd {
+				if row, ok := m.selectedRow(); ok && !row.Missing {
+					return openToolCmd(tool, row)
+				}
+				return nil
+			},
+		}
+	}
+	return actions
+}
+
+// toolInstalled reports whether the program a command line starts with is
+// on the PATH. Lines starting with shell syntax get the benefit of the
+// doubt.
+func toolInstalled(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) > 1 && fields[0] == "exec" {
+		fields = fields[1:]
+	}
+	if len(fields) == 0 {
+		return false
+	}
+	if strings.ContainsAny(fields[0], "$\"'=") {
+		return true
+	}
+	_, err := exec.LookPath(fields[0])
+	return err == nil
+}
+
+// withTools returns commands with the tool commands after the dashboard's
+// own.
+func withTools(tools []*action) []*action {
+	at := 0
+	for i, c := range commands {
+		if c.in(ViewDashboard) && !c.in(ViewDiscovery) {
+			at = i + 1
+		}
+	}
+	out := make([]*action, 0, len(commands)+len(tools))
+	out = append(out, commands[:at]...)
+	out = append(out, tools...)
+	return append(out, commands[at:]...)
+}
+
+// openToolCmd suspends the TUI and runs tool in the repo's directory, with
+// $GEE_REPO set to its path. The dashboard comes back as it was when the
+// tool exits.
+func openToolCmd(tool util.Tool, row RepoRow) tea.Cmd {
+	c := exec.Command("sh", "-c", tool.Command)
+	c.Dir = row.Path
+	c.Env = append(os.Environ(), "GEE_REPO="+row.Path)
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return ToolDoneMsg{Tool: tool.Name, Name: row.DisplayName, Path: row.Path, Err: err}
+	})
+}
+
+// toolResult is the action log entry for a tool that failed, or "".
+func toolResult(msg ToolDoneMsg) string {
+	var exitErr *exec.ExitError
+	switch {
+	case msg.Err == nil:
+		return ""
+	case errors.As(msg.Err, &exitErr) && exitErr.ExitCode() == 127:
+		return fmt.Sprintf("%s %s: command not found (set it under [tools] in config.toml)", msg.Tool, msg.Name)
+	case errors.As(msg.Err, &exitErr):
+		return fmt.Sprintf("%s %s: exited with status %d", msg.Tool, msg.Name, exitErr.ExitCode())
+	}
+	return fmt.Sprintf("%s %s: %s", msg.Tool, msg.Name, msg.Err)
+}
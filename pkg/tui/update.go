@@ -2,39 +2,39 @@ package tui
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
-	"gee/pkg/types"
+	"gee/pkg/util"
 
-	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-// filteredRow pairs a RepoRow with its original index in m.Rows.
+// filteredRow pairs a RepoRow with its original index in m.Rows. When
+// grouping, section headers are entries too: group is set and row is empty.
 type filteredRow struct {
 	origIndex int
 	row       RepoRow
+	positions []int // runes of the name matched by the filter
+	group     *rowGroup
 }
 
-// filteredRows returns the subset of Rows matching the current filter.
+// filteredRows returns the Rows matching the current filter, sorted and
+// grouped as the dashboard shows them. A filter that doesn't parse matches
+// everything; the filter bar shows why. Hidden repos are left out unless
+// ShowHidden is on.
 func (m *AppModel) filteredRows() []filteredRow {
-	if m.Filter == "" {
-		rows := make([]filteredRow, len(m.Rows))
-		for i, r := range m.Rows {
-			rows[i] = filteredRow{origIndex: i, row: r}
-		}
-		return rows
-	}
-	lowerFilter := strings.ToLower(m.Filter)
+	filter, _ := util.ParseFilter(m.Filter)
 	var rows []filteredRow
 	for i, r := range m.Rows {
-		if strings.Contains(strings.ToLower(r.Repo.Name), lowerFilter) {
-			rows = append(rows, filteredRow{origIndex: i, row: r})
+		if r.Hidden && !m.ShowHidden {
+			continue
+		}
+		if positions, ok := filter.Match(r.filterRepo()); ok {
+			rows = append(rows, filteredRow{origIndex: i, row: r, positions: positions})
 		}
 	}
-	return rows
+	return m.arrange(rows)
 }
 
 // startRefresh begins a new status refresh cycle, returning the tea.Cmd to
@@ -45,11 +45,20 @@ func (m *AppModel) startRefresh() tea.Cmd {
 		m.Rows[i].Loading = true
 	}
 	repos := m.repoSlice()
-	cmd, ch := refreshStatusCmd(repos, m.RepoUtils)
+	cmd, ch := refreshStatusCmd(repos, m.RepoUtils, m.Status)
 	m.StatusCh = ch
 	return cmd
 }
 
+// refreshRepo refreshes the status of the repo at path, if it has a row.
+func (m *AppModel) refreshRepo(path string) tea.Cmd {
+	i := m.rowIndexByPath(path)
+	if i < 0 {
+		return nil
+	}
+	return refreshSingleRepoStatusCmd(m.Rows[i].Repo, m.RepoUtils, m.Status)
+}
+
 // reloadCache re-reads cache from disk and rebuilds the row list.
 func (m *AppModel) reloadCache() {
 	if _, err := m.Cache.Load(); err != nil {
@@ -61,36 +70,217 @@ func (m *AppModel) reloadCache() {
 	// Preserve status for repos that still exist.
 	oldByPath := make(map[string]RepoRow, len(m.Rows))
 	for _, r := range m.Rows {
-		fullPath := m.RepoUtils.FullPathWithRepo(r.Repo.Path, r.Repo.Name)
-		oldByPath[fullPath] = r
+		oldByPath[r.Path] = r
 	}
 	rows := make([]RepoRow, len(cached))
 	for i, c := range cached {
 		if old, ok := oldByPath[c.Path]; ok {
 			old.Pinned = c.Pinned
+			old.Missing = c.Missing
+			old.Hidden = c.Hidden
+			old.Tags = c.Tags
 			rows[i] = old
 		} else {
-			rows[i] = RepoRow{
-				Repo: types.Repo{
-					Name:   c.Name,
-					Path:   filepath.Dir(c.Path),
-					Remote: c.Remote,
-				},
-				Pinned:  c.Pinned,
-				Loading: true,
+			rows[i] = newRepoRow(c)
+			if snap, ok := m.Status.Get(c.Path); ok {
+				rows[i].applySnapshot(snap)
 			}
 		}
 	}
+	labelRows(rows)
 	m.Rows = rows
+
+	if m.Watcher != nil {
+		paths := make([]string, len(cached))
+		for i, c := range cached {
+			paths[i] = c.Path
+		}
+		m.Watcher.Sync(paths)
+	}
+}
+
+// rowsMatchCache reports whether the rows already reflect the cache on disk
+// (same repos, same pin and hidden state), so our own Save doesn't trigger a
+// rebuild.
+func (m *AppModel) rowsMatchCache() bool {
+	onDisk := util.NewRepoCache()
+	if _, err := onDisk.Load(); err != nil {
+		return false
+	}
+	cached := onDisk.All()
+	if len(cached) != len(m.Rows) {
+		return false
+	}
+	byPath := make(map[string]util.CachedRepo, len(cached))
+	for _, c := range cached {
+		byPath[c.Path] = c
+	}
+	for _, r := range m.Rows {
+		c, ok := byPath[r.Path]
+		if !ok || c.Pinned != r.Pinned || c.Hidden != r.Hidden {
+			return false
+		}
+	}
+	return true
+}
+
+// rowIndexByPath returns the index in m.Rows of the repo at fullPath, or -1.
+func (m *AppModel) rowIndexByPath(fullPath string) int {
+	for i, r := range m.Rows {
+		if r.Path == fullPath {
+			return i
+		}
+	}
+	return -1
+}
+
+// syncMissing copies the cache's Missing flags onto the rows in place
+// (without reordering) and returns how many rows are missing.
+func (m *AppModel) syncMissing() int {
+	missingByPath := make(map[string]bool)
+	for _, c := range m.Cache.All() {
+		missingByPath[c.Path] = c.Missing
+	}
+	count := 0
+	for i, r := range m.Rows {
+		m.Rows[i].Missing = missingByPath[r.Path]
+		if m.Rows[i].Missing {
+			count++
+		}
+	}
+	return count
+}
+
+// startOp opens a history batch expecting n per-repo results and returns
+// its id for the result messages to carry.
+func (m *AppModel) startOp(command, args string, n int) int {
+	m.nextOp++
+	m.Ops[m.nextOp] = &opBatch{
+		Entry: util.HistoryEntry{
+			Command:   command,
+			Args:      args,
+			Source:    "tui",
+			StartedAt: time.Now(),
+		},
+		Pending: n,
+	}
+	return m.nextOp
+}
+
+// finishOp records one repo's result against its batch. When the last
+// result arrives it returns a command that appends the batch to the history
+// log; otherwise nil.
+func (m *AppModel) finishOp(op int, result util.HistoryResult) tea.Cmd {
+	batch, ok := m.Ops[op]
+	if !ok {
+		return nil
+	}
+	batch.Entry.Results = append(batch.Entry.Results, result)
+	batch.Pending--
+	if batch.Pending > 0 {
+		return nil
+	}
+	delete(m.Ops, op)
+	batch.Entry.Duration = time.Since(batch.Entry.StartedAt)
+	return appendHistoryCmd(batch.Entry)
+}
+
+// runOnTargets starts a pull, fetch, push or exec (of userCmd) batch over
+// the marked repos, or the selected one.
+func (m *AppModel) runOnTargets(command, userCmd string) tea.Cmd {
+	return m.runOn(command, userCmd, m.targetRows())
+}
+
+// runOn starts a pull, fetch, push or exec batch over rows, each streaming
+// its output into the output view.
+func (m *AppModel) runOn(command, userCmd string, rows []RepoRow) tea.Cmd {
+	if len(rows) == 0 {
+		return nil
+	}
+	action := map[string]string{"pull": "pulling...", "fetch": "fetching...", "push": "pushing...", "exec": "exec..."}[command]
+	op := m.startOp(command, userCmd, len(rows))
+	var cmds []tea.Cmd
+	for _, r := range rows {
+		if i := m.rowIndexByPath(r.Path); i >= 0 {
+			m.Rows[i].Action = action
+		}
+		run, out, listen := m.Output.startRun(command, userCmd, r)
+		cmds = append(cmds, listen)
+		if command == "exec" {
+			cmds = append(cmds, execRepoCmd(op, run.ID, r.Repo, userCmd, m.RepoUtils, out))
+		} else {
+			cmds = append(cmds, remoteRepoCmd(op, run.ID, command, r.Repo, m.RepoUtils, out))
+		}
+	}
+	return tea.Batch(cmds...)
 }
 
-// Update is the main bubbletea update function.
+// Update is the main bubbletea update function. The action log keeps only
+// its newest actionLogSize entries; the output view holds the full results.
+// Status results and scans can reorder a sorted or grouped table, so for
+// anything but a key press or click the cursor stays on the repo it was on. The
+// tables scroll only as far as the cursor needs.
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	isInput := false
+	switch msg.(type) {
+	case tea.KeyMsg, tea.MouseMsg:
+		isInput = true
+	}
+	selected, hadRow := m.selectedRow()
+	next, cmd := m.update(msg)
+	am, ok := next.(AppModel)
+	if !ok {
+		return next, cmd
+	}
+	if len(am.ActionLog) > actionLogSize {
+		am.ActionLog = append([]string(nil), am.ActionLog[len(am.ActionLog)-actionLogSize:]...)
+	}
+	if !isInput && hadRow && am.ActiveView == ViewDashboard {
+		if row, ok := am.selectedRow(); !ok || row.Path != selected.Path {
+			if !am.cursorToRow(selected.Path) {
+				am.clampCursor()
+			}
+		}
+	}
+	switch am.ActiveView {
+	case ViewDashboard:
+		am.Scroll = am.dashboardTable(len(am.filteredRows())).offset
+	case ViewDiscovery:
+		am.Discovery.Scroll = am.discoveryTable(len(am.Discovery.visible())).offset
+	}
+	return am, cmd
+}
+
+func (m AppModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
 		m.Width = msg.Width
 		m.Height = msg.Height
+		// Wide terminals have room for the detail pane from the start.
+		if !m.Detail.sized {
+			m.Detail.sized = true
+			m.Detail.Visible = msg.Width >= detailSplitWidth
+		}
+		m.sizeDiff()
+		m.sizeOutput()
+		return m, m.syncDetail()
+
+	case DiffLoadedMsg:
+		if msg.Path == m.Diff.Path {
+			m.Diff.Loading = false
+			m.Diff.Err = msg.Err
+			m.Diff.setFiles(msg.Files)
+		}
+		return m, nil
+
+	case RepoDetailMsg:
+		// Drop results for a repo the cursor has already left.
+		if msg.Path == m.Detail.Path {
+			m.Detail.Detail = msg.Detail
+			m.Detail.Err = msg.Err
+			m.Detail.Loading = false
+		}
 		return m, nil
 
 	// Bootstrap: store the status channel from Init.
@@ -107,39 +297,44 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	// --- Status refresh stream ---
 	case StatusResultMsg:
-		if msg.Index >= 0 && msg.Index < len(m.Rows) {
-			m.Rows[msg.Index].Loading = false
-			m.Rows[msg.Index].Failed = msg.Failed
+		// Rows may have been reordered by reloadCache since the refresh
+		// started, so match by path.
+		if i := m.rowIndexByPath(msg.Path); i >= 0 {
+			row := &m.Rows[i]
+			row.Loading = false
+			row.Failed = msg.Failed
 			if !msg.Failed {
-				m.Rows[msg.Index].Status = msg.Summary
+				row.applySnapshot(msg.Snapshot)
+				m.Status.Set(row.Path, msg.Snapshot)
 			}
 		}
+		// The repo changed, so its detail and open diff are stale too.
+		detailCmd := m.reloadDetail(msg.Path)
+		if m.ActiveView == ViewDiff && msg.Path == m.Diff.Path && !m.Diff.Loading {
+			m.Diff.Loading = true
+			detailCmd = tea.Batch(detailCmd, loadDiffCmd(msg.Path))
+		}
 		// Keep draining the channel.
 		if m.StatusCh != nil {
-			return m, waitForStatusResult(m.StatusCh)
+			return m, tea.Batch(waitForStatusResult(m.StatusCh), detailCmd)
 		}
-		return m, nil
+		return m, detailCmd
 
 	case StatusRefreshDoneMsg:
 		m.Refreshing = false
 		m.StatusCh = nil
-		return m, nil
+		return m, saveStatusCmd(m.Status)
 
 	// --- Scanner stream ---
 	case RepoDiscoveredMsg:
-		newRow := RepoRow{
-			Repo: types.Repo{
-				Name:   msg.Name,
-				Path:   filepath.Dir(msg.Path),
-				Remote: msg.Remote,
-			},
-			Pinned:  false,
-			Loading: true,
-		}
+		newRow := newRepoRow(util.CachedRepo{Name: msg.Name, Path: msg.Path, Remote: msg.Remote})
 		m.Rows = append(m.Rows, newRow)
-		newIndex := len(m.Rows) - 1
+		labelRows(m.Rows)
 
-		statusCmd := refreshSingleRepoStatusCmd(m.Rows[newIndex].Repo, newIndex, m.RepoUtils)
+		if m.Watcher != nil {
+			m.Watcher.Add(msg.Path)
+		}
+		statusCmd := refreshSingleRepoStatusCmd(newRow.Repo, m.RepoUtils, m.Status)
 		var nextScanCmd tea.Cmd
 		if m.ScanCh != nil {
 			nextScanCmd = waitForDiscoveredRepo(m.ScanCh)
@@ -149,40 +344,98 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ScanDoneMsg:
 		m.Scanning = false
 		m.ScanCh = nil
-		m.ActionLog = append(m.ActionLog, fmt.Sprintf("scan complete: %d repos", len(m.Rows)))
+		missing := m.syncMissing()
+		entry := fmt.Sprintf("scan complete: %d repos", len(m.Rows))
+		if missing > 0 {
+			entry += fmt.Sprintf(", %d missing", missing)
+		}
+		m.ActionLog = append(m.ActionLog, entry)
 		return m, nil
 
 	// --- Periodic refresh ---
 	case TickMsg:
+		var saveCmd tea.Cmd
+		if m.Status.Dirty() {
+			// Persist results from watcher-driven single refreshes.
+			saveCmd = saveStatusCmd(m.Status)
+		}
 		if !m.Refreshing {
-			return m, tea.Batch(m.startRefresh(), tickCmd())
+			return m, tea.Batch(m.startRefresh(), tickCmd(m.refreshInterval()), saveCmd)
 		}
-		return m, tickCmd()
+		return m, tea.Batch(tickCmd(m.refreshInterval()), saveCmd)
 
-	// --- Pull result ---
+	// --- Filesystem watcher ---
+	case initWatcherMsg:
+		if msg.w == nil {
+			return m, nil
+		}
+		// Repos the scanner found meanwhile aren't watched yet.
+		m.Watcher = msg.w
+		paths := make([]string, len(m.Rows))
+		for i, r := range m.Rows {
+			paths[i] = r.Path
+		}
+		m.Watcher.Sync(paths)
+		return m, waitForWatchEvent(m.Watcher.Events())
+
+	case RepoChangedMsg:
+		var cmds []tea.Cmd
+		if msg.CacheChanged && !m.rowsMatchCache() {
+			m.reloadCache()
+			for _, r := range m.Rows {
+				if r.Loading {
+					cmds = append(cmds, refreshSingleRepoStatusCmd(r.Repo, m.RepoUtils, m.Status))
+				}
+			}
+		}
+		for _, path := range msg.Paths {
+			cmds = append(cmds, m.refreshRepo(path))
+		}
+		cmds = append(cmds, waitForWatchEvent(m.Watcher.Events()))
+		return m, tea.Batch(cmds...)
+
+	case WatchClosedMsg:
+		m.Watcher = nil
+		return m, nil
+
+	// --- Pull / fetch / push result ---
 	case PullResultMsg:
-		if msg.Index >= 0 && msg.Index < len(m.Rows) {
-			m.Rows[msg.Index].Action = ""
+		name := msg.Name
+		if i := m.rowIndexByPath(msg.Path); i >= 0 {
+			m.Rows[i].Action = ""
+			name = m.Rows[i].DisplayName
 		}
 		if msg.Failed {
 			stderr := strings.TrimSpace(msg.Stderr)
 			if stderr == "" {
 				stderr = "unknown error"
 			}
-			m.ActionLog = append(m.ActionLog, fmt.Sprintf("pull %s: FAILED - %s", msg.Name, stderr))
+			// The first line says why; the rest is in gee history.
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s %s: FAILED - %s", msg.Command, name, truncate(stderr, 120)))
 		} else {
+			// fetch and push report on stderr; its last line is the summary.
 			out := strings.TrimSpace(msg.Stdout)
+			if out == "" {
+				if lines := strings.Split(strings.TrimSpace(msg.Stderr), "\n"); lines[len(lines)-1] != "" {
+					out = strings.TrimSpace(lines[len(lines)-1])
+				}
+			}
 			if out == "" {
 				out = "up to date"
 			}
-			m.ActionLog = append(m.ActionLog, fmt.Sprintf("pull %s: %s", msg.Name, out))
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s %s: %s", msg.Command, name, out))
 		}
-		return m, m.startRefresh()
+		m.Output.finishRun(msg.Run, msg.Failed, msg.Duration)
+		m.refreshOutput()
+		historyCmd := m.finishOp(msg.Op, util.NewHistoryResult(name, msg.Path, msg.Failed, msg.Duration, msg.Stdout, msg.Stderr))
+		return m, tea.Batch(m.refreshRepo(msg.Path), historyCmd)
 
 	// --- Exec result ---
 	case ExecResultMsg:
-		if msg.Index >= 0 && msg.Index < len(m.Rows) {
-			m.Rows[msg.Index].Action = ""
+		name := msg.Name
+		if i := m.rowIndexByPath(msg.Path); i >= 0 {
+			m.Rows[i].Action = ""
+			name = m.Rows[i].DisplayName
 		}
 		out := strings.TrimSpace(msg.Stdout)
 		if msg.Failed {
@@ -190,29 +443,156 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if out == "" {
 				out = "failed"
 			}
-			m.ActionLog = append(m.ActionLog, fmt.Sprintf("exec %s: FAILED - %s", msg.Name, out))
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("exec %s: FAILED - %s", name, out))
 		} else {
 			if out == "" {
 				out = "done"
 			}
-			m.ActionLog = append(m.ActionLog, fmt.Sprintf("exec %s: %s", msg.Name, truncate(out, 80)))
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("exec %s: %s", name, truncate(out, 80)))
 		}
-		return m, m.startRefresh()
+		m.Output.finishRun(msg.Run, msg.Failed, msg.Duration)
+		m.refreshOutput()
+		historyCmd := m.finishOp(msg.Op, util.NewHistoryResult(name, msg.Path, msg.Failed, msg.Duration, msg.Stdout, msg.Stderr))
+		return m, tea.Batch(m.refreshRepo(msg.Path), historyCmd)
+
+	case OutputMsg:
+		m.Output.appendOutput(msg)
+		m.refreshOutput()
+		return m, waitForOutput(m.Output.ch)
+
+	case GitActionMsg:
+		if i := m.rowIndexByPath(msg.Path); i >= 0 {
+			m.Rows[i].Action = ""
+		}
+		if msg.Err != nil {
+			m.Output.addFinished(msg.Verb, msg.Name, msg.Path, msg.Err.Error(), true, msg.Duration)
+		} else {
+			m.Output.addFinished(msg.Verb, msg.Name, msg.Path, msg.Output, false, msg.Duration)
+		}
+		m.refreshOutput()
+		if msg.Err != nil {
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s %s: FAILED - %s", msg.Verb, msg.Name, msg.Err))
+			return m, nil
+		}
+		m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s %s: %s", msg.Verb, msg.Name, truncate(msg.Output, 80)))
+		if msg.Verb == "commit" || msg.Verb == "amend" {
+			m.Commit.draft = ""
+		}
+		// Refresh through the status pipeline, which also reloads the
+		// detail pane and an open diff.
+		return m, m.refreshRepo(msg.Path)
+
+	case BranchesLoadedMsg:
+		if m.Branches.Active {
+			m.Branches.setBranches(msg)
+		}
+		return m, nil
+
+	case CommitMessageMsg:
+		if m.Commit.Active && strings.TrimSpace(m.Commit.Input.Value()) == "" {
+			m.Commit.Input.SetValue(msg.Message)
+		}
+		return m, nil
+
+	case ActionDoneMsg:
+		m.ActionLog = append(m.ActionLog, msg.Text)
+		return m, nil
+
+	case ClipboardMsg:
+		return m, writeTerminalCmd(msg.Seq, msg.Done)
+
+	case ClipboardDoneMsg:
+		m.ActionLog = append(m.ActionLog, msg.Text)
+		// Handing the terminal over turned mouse reporting off.
+		return m, m.mouseCmd()
+
+	// The tool may have changed anything; look at the repo again now
+	// rather than waiting for the watcher.
+	case ToolDoneMsg:
+		if text := toolResult(msg); text != "" {
+			m.ActionLog = append(m.ActionLog, text)
+		}
+		// Handing the terminal back turned mouse reporting off.
+		if m.rowIndexByPath(msg.Path) < 0 {
+			return m, m.mouseCmd()
+		}
+		return m, tea.Batch(m.refreshRepo(msg.Path), m.reloadDetail(msg.Path), m.mouseCmd())
+
+	case HistoryRecordedMsg:
+		if msg.Err != nil {
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("history: %s", msg.Err))
+		} else if msg.Failures > 0 {
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("%s: %d failed, re-run with gee history rerun %d --failed", msg.Label, msg.Failures, msg.ID))
+		}
+		return m, nil
 
 	// --- Discovery ---
 	case DiscoveryResultMsg:
+		if src, ok := m.Discovery.current(); !ok || src != msg.Source {
+			// The user switched sources while this was loading.
+			return m, nil
+		}
 		m.Discovery.Loading = false
 		m.Discovery.Error = msg.Error
-		m.Discovery.RemoteRepos = msg.Repos
+		m.Discovery.Repos = msg.Repos
+		m.Discovery.FetchedAt = msg.FetchedAt
+		m.Discovery.FromCache = msg.Cached
+		m.Discovery.Truncated = msg.Truncated
+		if n := len(m.Discovery.visible()); m.Discovery.Cursor >= n {
+			m.Discovery.Cursor = max(n-1, 0)
+		}
+		return m, nil
+
+	case DiscoveryOwnersMsg:
+		if msg.Error != nil {
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("list orgs on %s: %s", msg.Provider.Host(), msg.Error))
+			return m, nil
+		}
+		if msg.Truncated {
+			m.ActionLog = append(m.ActionLog, fmt.Sprintf("list orgs on %s: stopped at the page limit, some may be missing", msg.Provider.Host()))
+		}
+		m.Discovery.addOwners(msg.Provider, msg.Owners)
+		return m, nil
+
+	case CloneProgressMsg:
+		if msg.Index >= 0 && msg.Index < len(m.Discovery.Clones) {
+			job := &m.Discovery.Clones[msg.Index]
+			job.State = msg.State
+			if msg.Phase != "" {
+				job.Phase, job.Percent = msg.Phase, msg.Percent
+			}
+			if msg.Path != "" {
+				job.Path = msg.Path
+			}
+			job.Reason = msg.Reason
+		}
+		if m.Discovery.cloneCh != nil {
+			return m, waitForCloneProgress(m.Discovery.cloneCh)
+		}
 		return m, nil
 
 	case CloneBatchDoneMsg:
+		succeeded, failed := m.Discovery.finishClone()
 		m.ActionLog = append(m.ActionLog,
-			fmt.Sprintf("clone: %d succeeded, %d failed", msg.Succeeded, msg.Failed))
+			fmt.Sprintf("clone: %d succeeded, %d failed", succeeded, failed))
+		for _, job := range m.Discovery.Clones {
+			if job.State == cloneFailed {
+				m.ActionLog = append(m.ActionLog, fmt.Sprintf("  %s: %s", job.Target.Repo.FullName, job.Reason))
+			}
+		}
 		m.reloadCache()
-		m.ActiveView = ViewDashboard
+		m.Discovery.syncLocal(m.Cache)
+		// Stay on the results when something failed so the reasons can be
+		// read; otherwise go back to the dashboard.
+		if failed == 0 {
+			m.Discovery.Clones = nil
+			m.ActiveView = ViewDashboard
+		}
 		return m, m.startRefresh()
 
+	case tea.MouseMsg:
+		return m.updateMouse(msg)
+
 	// --- Keyboard input ---
 	case tea.KeyMsg:
 		// Global keys
@@ -221,11 +601,39 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		}
 
+		if m.Commit.Active {
+			return m.updateCommit(msg)
+		}
+		if m.Branches.Active {
+			return m.updateBranches(msg)
+		}
+		if m.Confirm != nil {
+			return m.updateConfirm(msg)
+		}
+		if m.Palette.Active {
+			return m.updatePalette(msg)
+		}
+		if m.HelpVisible {
+			// Any key closes the help overlay.
+			m.HelpVisible = false
+			return m, nil
+		}
+
 		switch m.ActiveView {
 		case ViewDashboard:
-			return m.updateDashboard(msg)
+			next, cmd := m.updateDashboard(msg)
+			// Whatever the key did, keep the detail pane on the selected repo.
+			if am, ok := next.(AppModel); ok {
+				detailCmd := am.syncDetail()
+				return am, tea.Batch(cmd, detailCmd)
+			}
+			return next, cmd
 		case ViewDiscovery:
 			return m.updateDiscovery(msg)
+		case ViewDiff:
+			return m.updateDiff(msg)
+		case ViewOutput:
+			return m.updateOutput(msg)
 		}
 	}
 
@@ -243,13 +651,7 @@ func (m AppModel) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if userCmd == "" {
 				return m, nil
 			}
-			filtered := m.filteredRows()
-			if m.Cursor >= 0 && m.Cursor < len(filtered) {
-				r := filtered[m.Cursor]
-				m.Rows[r.origIndex].Action = "exec..."
-				return m, execRepoCmd(r.row.Repo, r.origIndex, userCmd, m.RepoUtils)
-			}
-			return m, nil
+			return m, m.runOnTargets("exec", userCmd)
 		case "esc":
 			m.ExecInput.Reset()
 			m.ExecActive = false
@@ -269,6 +671,7 @@ func (m AppModel) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.FilterInput.Blur()
 			m.Filter = m.FilterInput.Value()
 			m.Cursor = 0
+			m.RangeAnchor = -1
 			return m, nil
 		case "esc":
 			m.Filtering = false
@@ -276,152 +679,80 @@ func (m AppModel) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.FilterInput.SetValue("")
 			m.Filter = ""
 			m.Cursor = 0
+			m.RangeAnchor = -1
 			return m, nil
 		default:
 			var cmd tea.Cmd
 			m.FilterInput, cmd = m.FilterInput.Update(msg)
 			m.Filter = m.FilterInput.Value()
+			m.clampCursor()
 			return m, cmd
 		}
 	}
 
-	// --- Normal dashboard navigation ---
-	filtered := m.filteredRows()
-	maxIdx := len(filtered) - 1
-	if maxIdx < 0 {
-		maxIdx = 0
-	}
-
-	switch msg.String() {
-	case "j", "down":
-		if m.Cursor < maxIdx {
-			m.Cursor++
-		}
-	case "k", "up":
-		if m.Cursor > 0 {
-			m.Cursor--
-		}
-	case "g":
-		m.Cursor = 0
-	case "G":
-		m.Cursor = maxIdx
-
-	case "p":
-		if len(filtered) > 0 && m.Cursor <= maxIdx {
-			r := filtered[m.Cursor]
-			m.Rows[r.origIndex].Action = "pulling..."
-			return m, pullRepoCmd(r.row.Repo, r.origIndex, m.RepoUtils)
-		}
-
-	case "P":
-		var cmds []tea.Cmd
-		for _, r := range filtered {
-			m.Rows[r.origIndex].Action = "pulling..."
-			cmds = append(cmds, pullRepoCmd(r.row.Repo, r.origIndex, m.RepoUtils))
-		}
-		if len(cmds) > 0 {
-			return m, tea.Batch(cmds...)
+	// --- Copy prefix: y, then what to copy; any other key cancels ---
+	if m.Yanking {
+		m.Yanking = false
+		if c := m.Keys.lookup(&m, ViewCopy, msg.String()); c != nil {
+			return m, c.Run(&m)
 		}
+		return m, nil
+	}
 
-	case "e":
-		m.ExecActive = true
-		m.ExecInput.Focus()
-		return m, textinput.Blink
+	if c := m.Keys.lookup(&m, ViewDashboard, msg.String()); c != nil {
+		return m, c.Run(&m)
+	}
+	return m, nil
+}
 
-	case "enter":
-		if len(filtered) > 0 && m.Cursor <= maxIdx {
-			r := filtered[m.Cursor]
-			fullPath := m.RepoUtils.FullPathWithRepo(r.row.Repo.Path, r.row.Repo.Name)
-			m.SelectedPath = fullPath
-			return m, tea.Quit
+func (m AppModel) updateDiscovery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.Discovery.EditingOptions {
+		if c := m.Keys.lookup(&m, ViewCloneOptions, msg.String()); c != nil {
+			return m, c.Run(&m)
 		}
+		return m, nil
+	}
 
-	case "a":
-		// Toggle pin on selected repo.
-		if len(filtered) > 0 && m.Cursor <= maxIdx {
-			r := filtered[m.Cursor]
-			fullPath := m.RepoUtils.FullPathWithRepo(r.row.Repo.Path, r.row.Repo.Name)
-			if m.Rows[r.origIndex].Pinned {
-				m.Cache.Unpin(fullPath)
-				m.Rows[r.origIndex].Pinned = false
-				m.ActionLog = append(m.ActionLog, fmt.Sprintf("unpinned %s", r.row.Repo.Name))
+	// The clone progress / results screen.
+	if m.Discovery.Clones != nil {
+		switch msg.String() {
+		case "esc", "enter":
+			if m.Discovery.Cloning {
+				// Clones continue in the background.
+				m.ActiveView = ViewDashboard
 			} else {
-				m.Cache.Pin(fullPath)
-				m.Rows[r.origIndex].Pinned = true
-				m.ActionLog = append(m.ActionLog, fmt.Sprintf("pinned %s", r.row.Repo.Name))
-			}
-			m.Cache.Save()
-		}
-
-	case "r":
-		if !m.Refreshing {
-			return m, m.startRefresh()
-		}
-
-	case "/":
-		m.Filtering = true
-		m.FilterInput.Focus()
-		return m, textinput.Blink
-
-	case "d":
-		if m.Discovery.Provider != "" {
-			m.ActiveView = ViewDiscovery
-			if len(m.Discovery.RemoteRepos) == 0 && !m.Discovery.Loading {
-				m.Discovery.Loading = true
-				return m, discoverRemoteReposCmd(m.Discovery.Provider)
+				m.Discovery.Clones = nil
 			}
+		case "d":
+			m.ActiveView = ViewDashboard
+		case "q", "ctrl+c":
+			return m, tea.Quit
 		}
-
-	case "q":
-		return m, tea.Quit
+		return m, nil
 	}
 
-	return m, nil
-}
-
-func (m AppModel) updateDiscovery(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	maxIdx := len(m.Discovery.RemoteRepos) - 1
-	if maxIdx < 0 {
-		maxIdx = 0
-	}
-
-	switch msg.String() {
-	case "esc", "d":
-		m.ActiveView = ViewDashboard
-	case "j", "down":
-		if m.Discovery.Cursor < maxIdx {
-			m.Discovery.Cursor++
-		}
-	case "k", "up":
-		if m.Discovery.Cursor > 0 {
-			m.Discovery.Cursor--
-		}
-	case "g":
-		m.Discovery.Cursor = 0
-	case "G":
-		m.Discovery.Cursor = maxIdx
-	case " ":
-		m.Discovery.Selected[m.Discovery.Cursor] = !m.Discovery.Selected[m.Discovery.Cursor]
-		if m.Discovery.Cursor < maxIdx {
-			m.Discovery.Cursor++
-		}
-	case "enter":
-		var toClone []RemoteRepo
-		for i, sel := range m.Discovery.Selected {
-			if sel && i < len(m.Discovery.RemoteRepos) {
-				toClone = append(toClone, m.Discovery.RemoteRepos[i])
-			}
-		}
-		if len(toClone) == 0 {
-			return m, nil
+	if m.Discovery.Searching {
+		switch msg.String() {
+		case "enter":
+			m.Discovery.Searching = false
+			m.Discovery.Search.Blur()
+		case "esc":
+			m.Discovery.Searching = false
+			m.Discovery.Search.Blur()
+			m.Discovery.Search.SetValue("")
+			m.Discovery.Cursor = 0
+		default:
+			var cmd tea.Cmd
+			m.Discovery.Search, cmd = m.Discovery.Search.Update(msg)
+			m.Discovery.Cursor = 0
+			return m, cmd
 		}
-		m.Discovery.Loading = true
-		cloneDir, _ := os.UserHomeDir()
-		return m, cloneBatchCmd(toClone, m.Cache, cloneDir, m.RepoUtils)
-	case "q":
-		return m, tea.Quit
+		return m, nil
 	}
 
+	if c := m.Keys.lookup(&m, ViewDiscovery, msg.String()); c != nil {
+		return m, c.Run(&m)
+	}
 	return m, nil
 }
 
@@ -2,34 +2,73 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"gee/pkg/ui"
+	"gee/pkg/util"
 
 	"charm.land/lipgloss/v2"
 )
 
+// Styles, set from the theme by setStyles.
 var (
-	styleHeader    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15"))
-	styleDim       = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-	styleTableHead = lipgloss.NewStyle().Foreground(lipgloss.Color("245")).Bold(true)
-	styleCursor    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
-	styleAction    = lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Italic(true)
-	stylePrivate   = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
-	styleSelected  = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Bold(true)
-	styleStale     = lipgloss.NewStyle().Foreground(lipgloss.Color("208")).Bold(true)
-	stylePinned    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
-	styleHelpBar   = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245")).
-			Border(lipgloss.NormalBorder(), true, false, false, false).
-			BorderForeground(lipgloss.Color("238"))
+	styleHeader    lipgloss.Style
+	styleDim       lipgloss.Style
+	styleTableHead lipgloss.Style
+	styleCursor    lipgloss.Style
+	styleAction    lipgloss.Style
+	stylePrivate   lipgloss.Style
+	styleSelected  lipgloss.Style
+	styleStale     lipgloss.Style
+	stylePinned    lipgloss.Style
+	styleMatch     lipgloss.Style
+	styleHelpBar   lipgloss.Style
+	styleBox       lipgloss.Style // overlays and the detail pane
 )
 
+func init() {
+	setStyles(ui.CurrentTheme())
+}
+
+// setStyles rebuilds the TUI's styles from t. Without color, matches are
+// underlined and diff file headers reversed so they still stand out.
+func setStyles(t ui.Theme) {
+	styleHeader = t.Style(lipgloss.NewStyle().Bold(true).Foreground(t.Text))
+	styleDim = lipgloss.NewStyle().Foreground(t.Muted)
+	styleTableHead = t.Style(lipgloss.NewStyle().Foreground(t.Muted).Bold(true))
+	styleCursor = t.Style(lipgloss.NewStyle().Bold(true).Foreground(t.Accent))
+	styleAction = t.Style(lipgloss.NewStyle().Foreground(t.Warning).Italic(true))
+	stylePrivate = lipgloss.NewStyle().Foreground(t.Warning)
+	styleSelected = t.Style(lipgloss.NewStyle().Foreground(t.Success).Bold(true))
+	styleStale = t.Style(lipgloss.NewStyle().Foreground(t.Stale).Bold(true))
+	stylePinned = lipgloss.NewStyle().Foreground(t.Accent)
+	styleMatch = t.Style(lipgloss.NewStyle().Foreground(t.Warning).Bold(true).Underline(t.NoColor))
+	styleHelpBar = lipgloss.NewStyle().
+		Foreground(t.Muted).
+		Border(lipgloss.NormalBorder(), true, false, false, false).
+		BorderForeground(t.Border)
+	styleBox = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Padding(0, 1)
+
+	styleDiffAdd = lipgloss.NewStyle().Foreground(t.Success)
+	styleDiffDel = lipgloss.NewStyle().Foreground(t.Error)
+	styleDiffHunk = lipgloss.NewStyle().Foreground(t.Accent)
+	styleDiffFile = t.Style(lipgloss.NewStyle().Bold(true).Foreground(t.Text).Background(t.Surface).Reverse(t.NoColor))
+}
+
 // View renders the current screen.
 func (m AppModel) View() string {
 	switch m.ActiveView {
 	case ViewDiscovery:
 		return m.viewDiscovery()
+	case ViewDiff:
+		return m.viewDiff()
+	case ViewOutput:
+		return m.viewOutput()
 	default:
 		return m.viewDashboard()
 	}
@@ -39,83 +78,120 @@ func (m AppModel) viewDashboard() string {
 	var b strings.Builder
 
 	// --- Header ---
-	pinnedCount := 0
+	pinnedCount, hiddenCount := 0, 0
 	for _, r := range m.Rows {
 		if r.Pinned {
 			pinnedCount++
 		}
+		if r.Hidden {
+			hiddenCount++
+		}
 	}
-	title := fmt.Sprintf(" gee — %d repos", len(m.Rows))
+	title := fmt.Sprintf(" gee — %d repos", len(m.Rows)-hiddenCount)
 	if pinnedCount > 0 {
 		title += fmt.Sprintf(" (%d pinned)", pinnedCount)
 	}
 	header := styleHeader.Render(title)
+	switch {
+	case m.ShowHidden:
+		header += styleAction.Render(fmt.Sprintf("  showing %d hidden", hiddenCount))
+	case hiddenCount > 0:
+		header += styleDim.Render(fmt.Sprintf("  %d hidden", hiddenCount))
+	}
+	var arranged []string
+	if m.Prefs.Sort != "" {
+		arranged = append(arranged, "sorted by "+m.rowSort().Label)
+	}
+	if m.Prefs.Group != "" {
+		arranged = append(arranged, "grouped by "+m.rowGrouping().Label)
+	}
+	if len(arranged) > 0 {
+		header += styleDim.Render("  " + strings.Join(arranged, ", "))
+	}
 	if m.Scanning {
 		header += styleDim.Render("  ⟳ scanning...")
 	} else if m.Refreshing {
 		header += styleDim.Render("  ⟳ refreshing...")
 	}
+	if len(m.Marked) > 0 {
+		header += styleSelected.Render(fmt.Sprintf("  %d marked", len(m.Marked)))
+	}
+	if m.RangeAnchor >= 0 {
+		header += styleAction.Render("  -- RANGE -- (V to mark, esc to cancel)")
+	}
+	if m.Discovery.Cloning {
+		header += styleDim.Render(fmt.Sprintf("  ⟳ cloning %d repos (d to watch)", len(m.Discovery.Clones)))
+	}
 	b.WriteString(header + "\n\n")
 
 	// --- Filter bar ---
+	_, filterErr := util.ParseFilter(m.Filter)
 	if m.Filtering {
-		b.WriteString("  / " + m.FilterInput.View() + "\n\n")
+		b.WriteString("  / " + m.FilterInput.View() + "\n")
 	} else if m.Filter != "" {
-		b.WriteString(styleDim.Render(fmt.Sprintf("  filter: %s  (/ to edit, esc to clear)", m.Filter)) + "\n\n")
+		b.WriteString(styleDim.Render(fmt.Sprintf("  filter: %s  (/ to edit, esc to clear)", m.Filter)) + "\n")
 	}
+	switch {
+	case filterErr != nil:
+		b.WriteString(ui.StyleWarning.Render("  "+filterErr.Error()) + "\n")
+	case m.Filtering:
+		b.WriteString(styleDim.Render("  name  branch:feat/*  path:~/work  remote:gitlab  tag:x  is:dirty|clean|behind|ahead|stale|conflict|pinned|hidden  -negate") + "\n")
+	case m.Filter != "":
+		b.WriteString("\n")
+	}
+
+	// The table goes into its own builder so the detail pane can sit beside
+	// or below it.
+	var t strings.Builder
+	split := m.Detail.Visible && m.Width >= detailSplitWidth
 
 	// --- Table header ---
 	headerLine := fmt.Sprintf("  %-2s %-2s %-20s %-15s %-12s %s", "", "", "REPO", "BRANCH", "SYNC", "CHANGES")
-	b.WriteString(styleTableHead.Render(headerLine) + "\n")
+	t.WriteString(styleTableHead.Render(headerLine) + "\n")
 
 	// --- Repo rows ---
 	filtered := m.filteredRows()
-
-	// Scrolling: compute visible window based on terminal height.
-	// Reserve lines for: header(2) + filter(2 max) + table header(1) + log(4 max) + help(2) + exec(2 max) = ~13 overhead
-	overhead := 13
-	if m.Filter != "" || m.Filtering {
-		overhead += 2
-	}
-	visibleRows := m.Height - overhead
-	if visibleRows < 5 {
-		visibleRows = 5
-	}
-	if visibleRows > len(filtered) {
-		visibleRows = len(filtered)
-	}
-
-	scrollOffset := 0
-	if m.Cursor >= visibleRows {
-		scrollOffset = m.Cursor - visibleRows + 1
-	}
-	endIdx := scrollOffset + visibleRows
-	if endIdx > len(filtered) {
-		endIdx = len(filtered)
-	}
+	table := m.dashboardTable(len(filtered))
+	visibleRows, scrollOffset := table.rows, table.offset
+	endIdx := min(scrollOffset+visibleRows, len(filtered))
 
 	for i := scrollOffset; i < endIdx; i++ {
 		fr := filtered[i]
-		row := fr.row
 		selected := i == m.Cursor
+		if fr.group != nil {
+			t.WriteString(renderGroupHeader(fr.group, selected) + "\n")
+			continue
+		}
 
-		line := renderDashboardRow(row, selected)
-		b.WriteString(line + "\n")
+		line := renderDashboardRow(fr.row, fr.positions, selected, m.Marked[fr.row.Path] || m.inRange(i))
+		t.WriteString(line + "\n")
 	}
 
 	if len(filtered) == 0 {
 		if m.Filter != "" {
-			b.WriteString(styleDim.Render("  no repos match filter") + "\n")
+			t.WriteString(styleDim.Render("  no repos match filter") + "\n")
 		} else if m.Scanning {
-			b.WriteString(styleDim.Render("  no repos found — scanning...") + "\n")
+			t.WriteString(styleDim.Render("  no repos found — scanning...") + "\n")
 		} else {
-			b.WriteString(styleDim.Render("  no repos found — run gee add in a git repo to pin it") + "\n")
+			t.WriteString(styleDim.Render("  no repos found — run gee add in a git repo to pin it") + "\n")
 		}
 	}
 
 	// Scroll indicator
 	if len(filtered) > visibleRows {
-		b.WriteString(styleDim.Render(fmt.Sprintf("  (%d/%d)", m.Cursor+1, len(filtered))) + "\n")
+		t.WriteString(styleDim.Render(fmt.Sprintf("  (%d/%d)", m.Cursor+1, len(filtered))) + "\n")
+	}
+
+	switch {
+	case split:
+		paneWidth := m.Width - table.width - 1
+		tableText := lipgloss.NewStyle().MaxWidth(table.width).Render(strings.TrimRight(t.String(), "\n"))
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tableText, " ", m.renderDetail(paneWidth, table.detailHeight)) + "\n")
+	case m.Detail.Visible:
+		b.WriteString(t.String())
+		b.WriteString(m.renderDetail(max(m.Width, 40), table.detailHeight) + "\n")
+	default:
+		b.WriteString(t.String())
 	}
 
 	// --- Action log (last 3 entries) ---
@@ -132,22 +208,98 @@ func (m AppModel) viewDashboard() string {
 
 	// --- Exec input ---
 	if m.ExecActive {
-		b.WriteString("\n  exec> " + m.ExecInput.View() + "\n")
+		prompt := "exec"
+		if n := len(m.targetRows()); len(m.Marked) > 0 {
+			prompt = fmt.Sprintf("exec in %d marked", n)
+		}
+		b.WriteString("\n  " + prompt + "> " + m.ExecInput.View() + "\n")
+	}
+
+	// --- Commit overlay ---
+	if m.Commit.Active {
+		b.WriteString("\n" + m.renderCommit() + "\n")
 	}
 
+	// --- Branch picker ---
+	if m.Branches.Active {
+		b.WriteString("\n" + m.renderBranches() + "\n")
+	}
+
+	// --- Unpin / forget / hide confirmation ---
+	if m.Confirm != nil {
+		b.WriteString("\n" + m.renderConfirm() + "\n")
+	}
+
+	// --- Command palette / key help ---
+	b.WriteString(m.renderKeyOverlay())
+
 	// --- Help bar ---
 	b.WriteString("\n" + m.renderHelpBar())
 
 	return b.String()
 }
 
-func renderDashboardRow(row RepoRow, selected bool) string {
+// tableLayout is where a view's table sits on screen, for drawing it and
+// for finding what a mouse click hit.
+type tableLayout struct {
+	top    int // screen line of the column headers; rows follow
+	offset int // index of the first row shown
+	rows   int // how many rows are shown
+	width  int // columns the table may use
+
+	detailHeight int // the dashboard's detail pane
+}
+
+// dashboardTable lays out the dashboard's table of n rows (section headers
+// included) for the current size, filter bar and detail pane.
+func (m *AppModel) dashboardTable(n int) tableLayout {
+	// Reserve lines for: header(2) + filter(2 max) + table header(1) + log(4 max) + help(2) + exec(2 max) = ~13 overhead
+	l := tableLayout{top: 2, width: m.Width}
+	overhead := 13
+	if m.Filter != "" || m.Filtering {
+		overhead += 2
+		l.top += 2
+	}
+	l.rows = max(m.Height-overhead, 5)
+	// Below the table, the detail pane gets everything but a few rows.
+	l.detailHeight = l.rows + 1
+	switch {
+	case m.Detail.Visible && m.Width >= detailSplitWidth:
+		l.width = m.Width - m.Width*2/5 - 1
+	case m.Detail.Visible:
+		l.detailHeight = max(l.rows-6, 8)
+		l.rows = min(l.rows, 5)
+	}
+	l.rows = min(l.rows, n)
+	l.offset = scrollWindow(m.Scroll, m.Cursor, l.rows, n)
+	return l
+}
+
+// scrollWindow returns the first of rows lines shown out of n: offset, the
+// last one, moved just enough to keep the cursor in view.
+func scrollWindow(offset, cursor, rows, n int) int {
+	offset = min(offset, n-rows)
+	if cursor < offset {
+		offset = cursor
+	}
+	if cursor >= offset+rows {
+		offset = cursor - rows + 1
+	}
+	return max(offset, 0)
+}
+
+func renderDashboardRow(row RepoRow, positions []int, selected, marked bool) string {
 	var parts []string
 
-	// Cursor indicator
-	cursor := "  "
+	// Cursor and mark indicators
+	cursor := " "
 	if selected {
-		cursor = styleCursor.Render("▸ ")
+		cursor = styleCursor.Render("▸")
+	}
+	if marked {
+		cursor += styleSelected.Render("●")
+	} else {
+		cursor += " "
 	}
 
 	// Pin icon
@@ -156,6 +308,9 @@ func renderDashboardRow(row RepoRow, selected bool) string {
 		pin = stylePinned.Render("* ")
 	}
 
+	// Rows seeded from a snapshot keep showing it while refreshing.
+	hasData := !row.CheckedAt.IsZero()
+
 	// Status icon
 	var icon string
 	switch {
@@ -168,7 +323,7 @@ func renderDashboardRow(row RepoRow, selected bool) string {
 	}
 
 	// Repo name
-	name := ui.StyleRepoName.Render(fmt.Sprintf("%-20s", row.Repo.Name))
+	name := highlightStyled(fmt.Sprintf("%-20s", row.DisplayName), positions, ui.StyleRepoName)
 
 	// Action indicator (inline)
 	if row.Action != "" {
@@ -176,7 +331,15 @@ func renderDashboardRow(row RepoRow, selected bool) string {
 		return strings.Join(parts, "")
 	}
 
-	if row.Loading || row.Failed {
+	if row.Hidden {
+		return cursor + pin + styleDim.Render("-") + "  " + name + "  " + styleDim.Render("hidden")
+	}
+
+	if row.Missing {
+		return cursor + pin + ui.SymbolWarning() + "  " + name + "  " + styleDim.Render("missing")
+	}
+
+	if (row.Loading && !hasData) || row.Failed {
 		status := "loading..."
 		if row.Failed {
 			status = "failed"
@@ -196,6 +359,8 @@ func renderDashboardRow(row RepoRow, selected bool) string {
 			branchDisplay = fmt.Sprintf("%s|%s", s.Branch, s.State)
 		}
 		branchDisplay = ui.StyleWarning.Render(fmt.Sprintf("%-15s", branchDisplay))
+	} else if s.Bare {
+		branchDisplay = ui.StyleCommand.Render(fmt.Sprintf("%-15s", s.Branch+"|BARE"))
 	} else {
 		branchDisplay = ui.StyleCommand.Render(fmt.Sprintf("%-15s", branchDisplay))
 	}
@@ -244,67 +409,123 @@ func renderDashboardRow(row RepoRow, selected bool) string {
 
 func (m AppModel) viewDiscovery() string {
 	var b strings.Builder
+	d := &m.Discovery
 
-	b.WriteString(styleHeader.Render(fmt.Sprintf(" Discovery (%s)", m.Discovery.Provider)) + "\n\n")
+	title := " Discovery"
+	if src, ok := d.current(); ok {
+		title = fmt.Sprintf(" Discovery — %s", src.Label())
+		if len(d.Sources) > 1 {
+			title += fmt.Sprintf("  (%d/%d)", d.Source+1, len(d.Sources))
+		}
+	}
+	b.WriteString(styleHeader.Render(title) + "\n\n")
 
-	if m.Discovery.Loading {
-		b.WriteString(styleDim.Render("  Loading remote repos...") + "\n")
-		b.WriteString("\n" + styleHelpBar.Render("  esc:back  q:quit"))
+	if d.Clones != nil {
+		m.writeClones(&b)
 		return b.String()
 	}
 
-	if m.Discovery.Error != nil {
-		b.WriteString(ui.StyleError.Render(fmt.Sprintf("  Error: %s", m.Discovery.Error)) + "\n")
-		b.WriteString("\n" + styleHelpBar.Render("  esc:back  q:quit"))
-		return b.String()
+	helpBar := m.keyHelp(ViewDiscovery)
+	if d.Searching {
+		helpBar = "  type to search  enter:keep  esc:clear"
+	} else if d.EditingOptions {
+		helpBar = "  clone options" + m.keyHelp(ViewCloneOptions)
 	}
 
-	if len(m.Discovery.RemoteRepos) == 0 {
-		b.WriteString(styleDim.Render("  No remote repos found.") + "\n")
-		b.WriteString("\n" + styleHelpBar.Render("  esc:back  q:quit"))
+	if d.Loading {
+		b.WriteString(styleDim.Render("  Loading remote repos...") + "\n")
+		b.WriteString(m.renderKeyOverlay() + "\n" + styleHelpBar.Render(m.keyHelp(ViewDiscovery, "discovery.next-owner", "discovery.prev-owner", "discovery.back", "palette", "help", "quit")))
 		return b.String()
 	}
 
-	// Count selections
-	selCount := 0
-	for _, sel := range m.Discovery.Selected {
-		if sel {
-			selCount++
+	if d.Error != nil {
+		b.WriteString(ui.StyleError.Render(fmt.Sprintf("  Error: %s", d.Error)) + "\n")
+		if src, ok := d.current(); ok {
+			b.WriteString(styleDim.Render(fmt.Sprintf("  %s credentials: %s", src.Provider.Name(), src.Provider.AuthSource())) + "\n")
 		}
+		b.WriteString(m.renderKeyOverlay() + "\n" + styleHelpBar.Render(m.keyHelp(ViewDiscovery, "discovery.next-owner", "discovery.prev-owner", "discovery.reload", "discovery.back", "palette", "help", "quit")))
+		return b.String()
 	}
 
-	// Table header
-	headerLine := fmt.Sprintf("  %-2s %-3s %-40s %s", "", "SEL", "REPOSITORY", "DESCRIPTION")
-	b.WriteString(styleTableHead.Render(headerLine) + "\n")
+	visible := d.visible()
 
-	// Scrolling
-	overhead := 10
-	visibleRows := m.Height - overhead
-	if visibleRows < 5 {
-		visibleRows = 5
+	// Listing summary: what's hidden and how fresh it is.
+	summary := []string{fmt.Sprintf("%d repos", len(d.Repos))}
+	if d.Truncated {
+		summary[0] = fmt.Sprintf("first %d repos (page limit reached)", len(d.Repos))
 	}
-	if visibleRows > len(m.Discovery.RemoteRepos) {
-		visibleRows = len(m.Discovery.RemoteRepos)
+	archived, forks, cloned := 0, 0, 0
+	for _, r := range d.Repos {
+		if r.Archived {
+			archived++
+		}
+		if r.Fork {
+			forks++
+		}
+		if d.localPath(r) != "" {
+			cloned++
+		}
+	}
+	if cloned > 0 {
+		summary = append(summary, fmt.Sprintf("%d already cloned", cloned))
+	}
+	if len(visible) != len(d.Repos) {
+		summary = append(summary, fmt.Sprintf("%d shown", len(visible)))
+	}
+	if d.Language != "" {
+		summary = append(summary, "language: "+d.Language)
+	}
+	if d.Visibility != "" {
+		summary = append(summary, d.Visibility+" only")
+	}
+	if archived > 0 && !d.ShowArchived {
+		summary = append(summary, fmt.Sprintf("%d archived hidden", archived))
+	}
+	if forks > 0 && !d.ShowForks {
+		summary = append(summary, fmt.Sprintf("%d forks hidden", forks))
+	}
+	if age := d.age(); age != "" && d.FromCache {
+		summary = append(summary, fmt.Sprintf("cached %s ago, R to reload", age))
+	}
+	b.WriteString(styleDim.Render("  "+strings.Join(summary, " · ")) + "\n")
+	options := "  clone: " + d.Options.Summary()
+	if d.EditingOptions {
+		b.WriteString(styleAction.Render(options) + "\n\n")
+	} else {
+		b.WriteString(styleDim.Render(options) + "\n\n")
 	}
 
-	scrollOffset := 0
-	if m.Discovery.Cursor >= visibleRows {
-		scrollOffset = m.Discovery.Cursor - visibleRows + 1
+	if d.Searching || d.Search.Value() != "" {
+		b.WriteString("  / " + d.Search.View() + "\n\n")
 	}
-	endIdx := scrollOffset + visibleRows
-	if endIdx > len(m.Discovery.RemoteRepos) {
-		endIdx = len(m.Discovery.RemoteRepos)
+
+	if len(visible) == 0 {
+		if len(d.Repos) > 0 {
+			b.WriteString(styleDim.Render("  No repos match the search and filters.") + "\n")
+		} else {
+			b.WriteString(styleDim.Render("  No remote repos found.") + "\n")
+		}
+		b.WriteString(m.renderKeyOverlay() + "\n" + styleHelpBar.Render(helpBar))
+		return b.String()
 	}
 
+	// Table header
+	headerLine := fmt.Sprintf("  %-2s %-3s %-40s %-12s %s", "", "SEL", "REPOSITORY", "LANGUAGE", "DESCRIPTION")
+	b.WriteString(styleTableHead.Render(headerLine) + "\n")
+
+	table := m.discoveryTable(len(visible))
+	visibleRows, scrollOffset := table.rows, table.offset
+	endIdx := min(scrollOffset+visibleRows, len(visible))
+
 	for i := scrollOffset; i < endIdx; i++ {
-		repo := m.Discovery.RemoteRepos[i]
+		repo := visible[i]
 		cursor := "  "
-		if i == m.Discovery.Cursor {
+		if i == d.Cursor {
 			cursor = styleCursor.Render("▸ ")
 		}
 
 		sel := styleDim.Render("[ ]")
-		if m.Discovery.Selected[i] {
+		if src, ok := d.current(); ok && d.isSelected(src, repo) {
 			sel = styleSelected.Render("[✓]")
 		}
 
@@ -312,36 +533,139 @@ func (m AppModel) viewDiscovery() string {
 		if repo.Private {
 			nameStr += " " + stylePrivate.Render("(private)")
 		}
+		if repo.Archived {
+			nameStr += " " + styleDim.Render("(archived)")
+		}
+		if repo.Fork {
+			nameStr += " " + styleDim.Render("(fork)")
+		}
 		name := fmt.Sprintf("%-40s", nameStr)
 
-		desc := repo.Description
-		if len(desc) > 40 {
-			desc = desc[:37] + "..."
+		lang := fmt.Sprintf("%-12s", truncate(repo.Language, 12))
+
+		// Repos we already have show where instead of their description.
+		var desc string
+		if path := d.localPath(repo); path != "" {
+			desc = ui.StyleSuccess.Render("✓ cloned") + " " + styleDim.Render(tildePath(path))
+		} else {
+			desc = repo.Description
+			if len(desc) > 40 {
+				desc = desc[:37] + "..."
+			}
+			desc = styleDim.Render(desc)
 		}
 
-		b.WriteString(fmt.Sprintf("%s%s  %s  %s\n", cursor, sel, name, styleDim.Render(desc)))
+		b.WriteString(fmt.Sprintf("%s%s  %s %s %s\n", cursor, sel, name, styleDim.Render(lang), desc))
 	}
 
 	// Scroll indicator
-	if len(m.Discovery.RemoteRepos) > visibleRows {
-		b.WriteString(styleDim.Render(fmt.Sprintf("  (%d/%d)", m.Discovery.Cursor+1, len(m.Discovery.RemoteRepos))) + "\n")
+	if len(visible) > visibleRows {
+		b.WriteString(styleDim.Render(fmt.Sprintf("  (%d/%d)", d.Cursor+1, len(visible))) + "\n")
 	}
 
-	if selCount > 0 {
-		b.WriteString("\n" + ui.StyleSuccess.Render(fmt.Sprintf("  %d selected", selCount)) + "\n")
+	if len(d.Selected) > 0 {
+		b.WriteString("\n" + ui.StyleSuccess.Render(fmt.Sprintf("  %d selected", len(d.Selected))) + "\n")
 	}
 
-	// Help bar
-	b.WriteString("\n" + styleHelpBar.Render("  j/k:nav  space:select  enter:clone selected  esc:back  q:quit"))
+	// Command palette / key help, then the help bar
+	b.WriteString(m.renderKeyOverlay())
+	b.WriteString("\n" + styleHelpBar.Render(helpBar))
 
 	return b.String()
 }
 
+// discoveryTable lays out Discovery's listing of n repos.
+func (m *AppModel) discoveryTable(n int) tableLayout {
+	d := &m.Discovery
+	// Title(2), summary(1) and clone options(2), then the search bar.
+	l := tableLayout{top: 5, width: m.Width}
+	if d.Searching || d.Search.Value() != "" {
+		l.top += 2
+	}
+	l.rows = min(max(m.Height-12, 5), n)
+	l.offset = scrollWindow(d.Scroll, d.Cursor, l.rows, n)
+	return l
+}
+
+// writeClones renders the progress of the current clone batch, or the
+// results of the last one with the reason for each failure.
+func (m AppModel) writeClones(b *strings.Builder) {
+	d := &m.Discovery
+	done, failed := 0, 0
+	for _, job := range d.Clones {
+		switch job.State {
+		case cloneDone, cloneExists:
+			done++
+		case cloneFailed:
+			failed++
+		}
+	}
+	summary := fmt.Sprintf("  Cloning %d repos (%s): %d done, %d failed", len(d.Clones), d.Options.Summary(), done, failed)
+	if !d.Cloning {
+		summary = fmt.Sprintf("  Cloned %d of %d repos, %d failed", done, len(d.Clones), failed)
+	}
+	b.WriteString(styleDim.Render(summary) + "\n\n")
+
+	for _, job := range d.Clones {
+		var state string
+		switch job.State {
+		case cloneQueued:
+			state = styleDim.Render(fmt.Sprintf("%-24s", "queued"))
+		case cloneRunning:
+			progress := "starting"
+			if job.Phase != "" {
+				progress = fmt.Sprintf("%s %d%%", strings.ToLower(job.Phase), job.Percent)
+			}
+			state = styleAction.Render(fmt.Sprintf("%-24s", truncate(progress, 24)))
+		case cloneDone:
+			state = ui.StyleSuccess.Render(fmt.Sprintf("%-24s", "done"))
+		case cloneExists:
+			state = ui.StyleSuccess.Render(fmt.Sprintf("%-24s", "already cloned"))
+		case cloneFailed:
+			state = ui.StyleError.Render(fmt.Sprintf("%-24s", "failed"))
+		}
+		b.WriteString(fmt.Sprintf("  %s  %s\n", state, job.Target.Repo.FullName))
+		if job.State == cloneFailed {
+			b.WriteString(ui.StyleError.Render("      "+job.Reason) + "\n")
+		}
+	}
+
+	help := "  esc:back to listing  q:quit"
+	if d.Cloning {
+		help = "  esc:continue in background  q:quit"
+	} else if failed > 0 {
+		help = "  esc:back to listing (failed repos stay selected; enter retries)  q:quit"
+	}
+	b.WriteString("\n" + styleHelpBar.Render(help))
+}
+
+// tildePath shortens a path under the home directory to ~/...
+func tildePath(path string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return path
+	}
+	if rel, err := filepath.Rel(home, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.Join("~", rel)
+	}
+	return path
+}
+
 func (m AppModel) renderHelpBar() string {
-	keys := []string{"j/k:nav", "a:pin", "p:pull", "P:pull all", "e:exec", "↵:cd", "r:refresh", "/:filter"}
-	if m.Discovery.Provider != "" {
-		keys = append(keys, "d:discover")
+	if m.Yanking {
+		return styleHelpBar.Render("  copy" + m.keyHelp(ViewCopy))
+	}
+	return styleHelpBar.Render(m.keyHelp(ViewDashboard))
+}
+
+// renderKeyOverlay draws the command palette or the ? help over the
+// current view, when either is open.
+func (m AppModel) renderKeyOverlay() string {
+	switch {
+	case m.Palette.Active:
+		return "\n" + m.renderPalette() + "\n"
+	case m.HelpVisible:
+		return "\n" + m.renderHelp() + "\n"
 	}
-	keys = append(keys, "q:quit")
-	return styleHelpBar.Render("  " + strings.Join(keys, "  "))
+	return ""
 }
@@ -1,6 +1,9 @@
 package types
 
-import "bytes"
+import (
+	"bytes"
+	"io"
+)
 
 type Repo struct {
 	// name of repo
@@ -28,6 +31,9 @@ type GeeContext struct {
 type RunConfig struct {
 	StdOut *bytes.Buffer
 	StdErr *bytes.Buffer
+	// Output, if set, also receives stdout and stderr as they are written,
+	// e.g. to stream them into the TUI.
+	Output io.Writer
 }
 
 type CommandOnFinish struct {
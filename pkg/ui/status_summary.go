@@ -6,19 +6,23 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"gee/pkg/gitdir"
 )
 
 type StatusSummary struct {
-	Branch    string
-	State     string // "", "REBASE", "MERGE", "CHERRY-PICK"
-	Progress  string // e.g. "3/5" for rebase, empty otherwise
-	Ahead     int
-	Behind    int
-	Staged    int
-	Modified  int
-	Untracked int
-	Conflicts int
-	Stale     bool // true if dirty with newest top-level file mtime > 7 days
+	Branch    string `json:"branch"`
+	Oid       string `json:"oid,omitempty"`      // HEAD commit, "(initial)" on an unborn branch
+	State     string `json:"state,omitempty"`    // "", "REBASE", "MERGE", "CHERRY-PICK"
+	Progress  string `json:"progress,omitempty"` // e.g. "3/5" for rebase, empty otherwise
+	Ahead     int    `json:"ahead,omitempty"`
+	Behind    int    `json:"behind,omitempty"`
+	Staged    int    `json:"staged,omitempty"`
+	Modified  int    `json:"modified,omitempty"`
+	Untracked int    `json:"untracked,omitempty"`
+	Conflicts int    `json:"conflicts,omitempty"`
+	Stale     bool   `json:"stale,omitempty"` // true if dirty with newest top-level file mtime > 7 days
+	Bare      bool   `json:"bare,omitempty"`  // a bare repo: no working tree, only Branch is known
 }
 
 // ParsePorcelainV2 parses `git status --porcelain=v2 --branch` output.
@@ -26,6 +30,8 @@ func ParsePorcelainV2(output string) StatusSummary {
 	s := StatusSummary{}
 	for _, line := range strings.Split(output, "\n") {
 		switch {
+		case strings.HasPrefix(line, "# branch.oid "):
+			s.Oid = strings.TrimPrefix(line, "# branch.oid ")
 		case strings.HasPrefix(line, "# branch.head "):
 			s.Branch = strings.TrimPrefix(line, "# branch.head ")
 		case strings.HasPrefix(line, "# branch.ab "):
@@ -55,9 +61,14 @@ func ParsePorcelainV2(output string) StatusSummary {
 }
 
 // DetectGitState checks .git/ sentinel files to identify rebase, merge,
-// or cherry-pick in progress. repoPath is the repo's working directory.
+// or cherry-pick in progress. repoPath is the repo's working directory;
+// for worktrees and submodules the sentinels live in the resolved gitdir.
 func DetectGitState(repoPath string) (state string, progress string) {
-	gitDir := filepath.Join(repoPath, ".git")
+	info, ok := gitdir.Resolve(repoPath)
+	if !ok {
+		return "", ""
+	}
+	gitDir := info.GitDir
 
 	// Interactive rebase: .git/rebase-merge/
 	if info, err := os.Stat(filepath.Join(gitDir, "rebase-merge")); err == nil && info.IsDir() {
@@ -100,6 +111,15 @@ func DetectGitState(repoPath string) (state string, progress string) {
 	return "", ""
 }
 
+// BareSummary builds a StatusSummary for a bare repo, which has no working
+// tree for `git status` to inspect. Only the HEAD branch is reported.
+func BareSummary(repoPath string) StatusSummary {
+	return StatusSummary{
+		Branch: gitdir.HeadBranch(repoPath),
+		Bare:   true,
+	}
+}
+
 // CheckStaleness returns true if the repo has uncommitted changes and the
 // most recent top-level file's mtime is older than 7 days. This is a fast
 // heuristic — only top-level entries are checked, not a deep walk.
@@ -130,10 +150,27 @@ func CheckStaleness(repoPath string, s StatusSummary) bool {
 	return time.Since(newest) > 7*24*time.Hour
 }
 
+// Age formats the time since t compactly: "42s", "5m", "3h", "12d".
+func Age(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
 type RepoStatusResult struct {
-	Name    string
-	Summary StatusSummary
-	Failed  bool
+	Name      string
+	Summary   StatusSummary
+	Failed    bool
+	Unknown   bool      // no status known (e.g. --cached with no snapshot yet)
+	CheckedAt time.Time // set when Summary is a persisted snapshot; shown as its age
 }
 
 // RenderStatusTable prints a compact one-line-per-repo status dashboard
@@ -148,6 +185,11 @@ func RenderStatusTable(results []RepoStatusResult, startTime time.Time) {
 			fmt.Printf("%s %s  %s\n", SymbolError(), StyleRepoName.Render(r.Name), StyleError.Render("failed"))
 			continue
 		}
+		if r.Unknown {
+			failed++
+			fmt.Printf("%s %s  %s\n", SymbolWarning(), StyleRepoName.Render(r.Name), StyleSummaryLine.Render("no cached status"))
+			continue
+		}
 
 		successful++
 		s := r.Summary
@@ -162,6 +204,8 @@ func RenderStatusTable(results []RepoStatusResult, startTime time.Time) {
 				branchDisplay = fmt.Sprintf("%s|%s", s.Branch, s.State)
 			}
 			parts = append(parts, StyleWarning.Render(branchDisplay))
+		} else if s.Bare {
+			parts = append(parts, StyleCommand.Render(s.Branch+"|BARE"))
 		} else {
 			parts = append(parts, StyleCommand.Render(branchDisplay))
 		}
@@ -202,6 +246,10 @@ func RenderStatusTable(results []RepoStatusResult, startTime time.Time) {
 			parts = append(parts, strings.Join(changes, " "))
 		}
 
+		if !r.CheckedAt.IsZero() {
+			parts = append(parts, StyleSummaryLine.Render(fmt.Sprintf("(%s ago)", Age(r.CheckedAt))))
+		}
+
 		fmt.Printf("%s  %s\n", SymbolSuccess(), strings.Join(parts, "  "))
 	}
 
@@ -2,43 +2,78 @@ package ui
 
 import (
 	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
 )
 
+// Styles, set from the theme by ApplyTheme.
 var (
 	// Header styles
-	StyleRepoName    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15"))
-	StyleCommand     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
-	StyleSummaryLine = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	StyleRepoName    lipgloss.Style
+	StyleCommand     lipgloss.Style
+	StyleSummaryLine lipgloss.Style
+	StyleMessage     lipgloss.Style // log messages
+	StyleAccent      lipgloss.Style
 
 	// Status styles
-	StyleSuccess = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
-	StyleError   = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
-	StyleWarning = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	StyleSuccess lipgloss.Style
+	StyleError   lipgloss.Style
+	StyleWarning lipgloss.Style
 
 	// Box for expanded repo output
+	StyleRepoBox      lipgloss.Style
+	StyleRepoBoxError lipgloss.Style
+
+	// Content inside boxes
+	StyleStdout lipgloss.Style
+	StyleStderr lipgloss.Style
+
+	// Footer
+	StyleFooter lipgloss.Style
+)
+
+// Until the settings are read, output uses the dark theme's colors as they
+// are.
+func init() {
+	ApplyTheme(newTheme("dark", darkPalette, colorprofile.TrueColor))
+}
+
+// ApplyTheme makes t the current theme and rebuilds the styles from it.
+func ApplyTheme(t Theme) {
+	current = t
+
+	// Only styles with text attributes need t.Style; colors are already
+	// gone when it matters.
+	StyleRepoName = t.Style(lipgloss.NewStyle().Bold(true).Foreground(t.Text))
+	StyleCommand = t.Style(lipgloss.NewStyle().Bold(true).Foreground(t.Muted))
+	StyleSummaryLine = lipgloss.NewStyle().Foreground(t.Muted)
+	StyleMessage = t.Style(lipgloss.NewStyle().Bold(true).Foreground(t.Text))
+	StyleAccent = lipgloss.NewStyle().Foreground(t.Accent)
+
+	StyleSuccess = lipgloss.NewStyle().Foreground(t.Success)
+	StyleError = lipgloss.NewStyle().Foreground(t.Error)
+	StyleWarning = lipgloss.NewStyle().Foreground(t.Warning)
+
 	StyleRepoBox = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(lipgloss.Color("238")).
-			PaddingLeft(1).
-			PaddingRight(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		PaddingLeft(1).
+		PaddingRight(1)
 
 	StyleRepoBoxError = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(lipgloss.Color("1")).
-				PaddingLeft(1).
-				PaddingRight(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Error).
+		PaddingLeft(1).
+		PaddingRight(1)
 
-	// Content inside boxes
-	StyleStdout = lipgloss.NewStyle().Foreground(lipgloss.Color("15"))
-	StyleStderr = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	StyleStdout = lipgloss.NewStyle().Foreground(t.Text)
+	StyleStderr = lipgloss.NewStyle().Foreground(t.Error)
 
-	// Footer
 	StyleFooter = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("245")).
-			Border(lipgloss.NormalBorder(), true, false, false, false).
-			BorderForeground(lipgloss.Color("238")).
-			MarginTop(1)
-)
+		Foreground(t.Muted).
+		Border(lipgloss.NormalBorder(), true, false, false, false).
+		BorderForeground(t.Border).
+		MarginTop(1)
+}
 
 func SymbolSuccess() string {
 	return StyleSuccess.Render("✓")
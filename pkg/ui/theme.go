@@ -0,0 +1,241 @@
+package ui
+
+import (
+	"fmt"
+	"image/color"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"charm.land/lipgloss/v2"
+	"github.com/charmbracelet/colorprofile"
+	"github.com/mattn/go-isatty"
+)
+
+// Palette is a theme's colors, each an ANSI color number ("245"), a hex
+// color ("#5fafd7"), or "default" for the terminal's own foreground (no
+// background, for Surface). A palette in config.toml only sets the colors
+// it changes; the rest come from its Base.
+type Palette struct {
+	Base    string `toml:"base"`    // theme the palette starts from; default dark or light by background
+	Text    string `toml:"text"`    // repo names, headers, command output
+	Muted   string `toml:"muted"`   // secondary text, help bars
+	Border  string `toml:"border"`  // boxes and rules
+	Surface string `toml:"surface"` // background of the diff viewer's file headers
+	Accent  string `toml:"accent"`  // cursor, pins, diff hunks
+	Success string `toml:"success"` // ✓, ahead, staged, added lines
+	Error   string `toml:"error"`   // ✗, behind, conflicts, removed lines
+	Warning string `toml:"warning"` // !, modified, running actions, matches
+	Stale   string `toml:"stale"`   // the STALE badge
+}
+
+// over fills the colors p leaves unset from base.
+func (p Palette) over(base Palette) Palette {
+	pick := func(c, fallback string) string {
+		if c == "" {
+			return fallback
+		}
+		return c
+	}
+	return Palette{
+		Text:    pick(p.Text, base.Text),
+		Muted:   pick(p.Muted, base.Muted),
+		Border:  pick(p.Border, base.Border),
+		Surface: pick(p.Surface, base.Surface),
+		Accent:  pick(p.Accent, base.Accent),
+		Success: pick(p.Success, base.Success),
+		Error:   pick(p.Error, base.Error),
+		Warning: pick(p.Warning, base.Warning),
+		Stale:   pick(p.Stale, base.Stale),
+	}
+}
+
+var colorPattern = regexp.MustCompile(`^(default|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
+// invalid returns the palette's colors that aren't a color.
+func (p Palette) invalid() []string {
+	var bad []string
+	for _, c := range []string{p.Text, p.Muted, p.Border, p.Surface, p.Accent, p.Success, p.Error, p.Warning, p.Stale} {
+		if n, err := strconv.Atoi(c); c != "" && (!colorPattern.MatchString(c) || err == nil && n > 255) {
+			bad = append(bad, strconv.Quote(c))
+		}
+	}
+	return bad
+}
+
+// builtinTheme is a theme that ships with gee, with a variant per
+// background. Themes meant for one background use the same for both.
+type builtinTheme struct {
+	dark, light Palette
+}
+
+var (
+	darkPalette = Palette{
+		Text: "15", Muted: "245", Border: "238", Surface: "236",
+		Accent: "6", Success: "2", Error: "1", Warning: "3", Stale: "208",
+	}
+	lightPalette = Palette{
+		Text: "235", Muted: "242", Border: "250", Surface: "254",
+		Accent: "31", Success: "28", Error: "160", Warning: "130", Stale: "166",
+	}
+)
+
+// builtinThemes are the themes theme.name accepts besides "auto" and the
+// user's palettes. The colorblind theme keeps success and error apart by
+// blue and orange instead of green and red (from the Okabe-Ito palette).
+var builtinThemes = map[string]builtinTheme{
+	"dark":  {dark: darkPalette, light: darkPalette},
+	"light": {dark: lightPalette, light: lightPalette},
+	"high-contrast": {
+		dark: Palette{
+			Text: "default", Muted: "default", Border: "default", Surface: "default",
+			Accent: "14", Success: "10", Error: "9", Warning: "11", Stale: "214",
+		},
+		light: Palette{
+			Text: "default", Muted: "default", Border: "default", Surface: "default",
+			Accent: "25", Success: "22", Error: "124", Warning: "94", Stale: "130",
+		},
+	},
+	"colorblind": {
+		dark:  Palette{Accent: "#56B4E9", Success: "#0090E0", Error: "#E69F00", Warning: "#F0E442", Stale: "#CC79A7"}.over(darkPalette),
+		light: Palette{Accent: "#0072B2", Success: "#0072B2", Error: "#D55E00", Warning: "#8A6D00", Stale: "#AA4499"}.over(lightPalette),
+	},
+}
+
+// ThemeNames lists the built-in themes, for help and error messages.
+var ThemeNames = []string{"auto", "dark", "light", "high-contrast", "colorblind"}
+
+// ThemeSettings is the [theme] table of config.toml.
+type ThemeSettings struct {
+	Name     string             `toml:"name"`     // one of ThemeNames or a palette; default "auto"
+	Palettes map[string]Palette `toml:"palettes"` // [theme.palettes.<name>] tables
+}
+
+// Theme is a palette resolved for the terminal it's shown on.
+type Theme struct {
+	Name    string
+	NoColor bool // the terminal (or the user) wants no color; only bold and such remain
+	Plain   bool // not a terminal at all: no styling either
+
+	Text, Muted, Border, Surface, Accent, Success, Error, Warning, Stale color.Color
+}
+
+// newTheme turns a palette into colors the terminal's profile supports.
+func newTheme(name string, p Palette, profile colorprofile.Profile) Theme {
+	noColor := profile <= colorprofile.ASCII
+	c := func(s string) color.Color {
+		if noColor || s == "" || s == "default" {
+			return lipgloss.NoColor{}
+		}
+		return profile.Convert(lipgloss.Color(s))
+	}
+	return Theme{
+		Name: name, NoColor: noColor, Plain: profile == colorprofile.NoTTY,
+		Text: c(p.Text), Muted: c(p.Muted), Border: c(p.Border), Surface: c(p.Surface),
+		Accent: c(p.Accent), Success: c(p.Success), Error: c(p.Error), Warning: c(p.Warning), Stale: c(p.Stale),
+	}
+}
+
+// Style finishes a style built from t: without a terminal it drops bold,
+// italics and the like too, so piped output is plain text.
+func (t Theme) Style(s lipgloss.Style) lipgloss.Style {
+	if !t.Plain {
+		return s
+	}
+	return s.UnsetBold().UnsetItalic().UnsetUnderline().UnsetReverse().UnsetFaint()
+}
+
+// ResolveTheme picks the configured theme for output written to out.
+// Colors are reduced to what out supports: none when $NO_COLOR is set or
+// TERM is dumb, or with noColor (--no-color), and no styling at all when
+// out isn't a terminal. An
+// unknown theme or a bad color is reported, and the built-in auto theme
+// used.
+func ResolveTheme(s ThemeSettings, noColor bool, out *os.File) (Theme, error) {
+	profile := colorprofile.Detect(out, os.Environ())
+	if noColor {
+		profile = colorprofile.ASCII
+	}
+	isDark := func() bool {
+		// Without color the background doesn't matter; don't ask.
+		return profile <= colorprofile.ASCII || hasDarkBackground(out)
+	}
+
+	name := s.Name
+	if name == "" {
+		name = "auto"
+	}
+	p, err := s.palette(name, isDark, map[string]bool{})
+	if err == nil {
+		if bad := p.invalid(); len(bad) > 0 {
+			err = fmt.Errorf("theme %s: not a color: %s (want an ANSI number or #rrggbb)", name, strings.Join(bad, ", "))
+		}
+	}
+	if err != nil {
+		p, _ = ThemeSettings{}.palette("auto", isDark, nil)
+		name = "auto"
+	}
+	return newTheme(name, p, profile), err
+}
+
+// palette resolves a theme name to its colors. Palettes in config.toml
+// shadow built-ins of the same name, and may build on each other.
+func (s ThemeSettings) palette(name string, isDark func() bool, seen map[string]bool) (Palette, error) {
+	if name == "" || name == "auto" {
+		name = "light"
+		if isDark() {
+			name = "dark"
+		}
+	}
+	if p, ok := s.Palettes[name]; ok && !seen[name] {
+		seen[name] = true
+		base, err := s.palette(p.Base, isDark, seen)
+		if err != nil {
+			return Palette{}, err
+		}
+		return p.over(base), nil
+	}
+	b, ok := builtinThemes[name]
+	if !ok {
+		return Palette{}, fmt.Errorf("unknown theme %q (want %s, or a [theme.palettes] name)", name, strings.Join(ThemeNames, ", "))
+	}
+	if b.dark == b.light || isDark() {
+		return b.dark, nil
+	}
+	return b.light, nil
+}
+
+// darkBackground caches hasDarkBackground; the terminal doesn't change
+// while gee runs.
+var darkBackground *bool
+
+// hasDarkBackground reports whether the terminal behind out has a dark
+// background: from $COLORFGBG when set, else by asking the terminal. When
+// it can't tell, it assumes dark.
+func hasDarkBackground(out *os.File) bool {
+	if darkBackground != nil {
+		return *darkBackground
+	}
+	dark := true
+	if fgbg := os.Getenv("COLORFGBG"); fgbg != "" {
+		// "15;0" or "15;default;0": the last field is the background's
+		// ANSI number, where 7 and 9-15 are light.
+		fields := strings.Split(fgbg, ";")
+		if bg, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			dark = bg < 7 || bg == 8
+		}
+	} else if isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(out.Fd()) {
+		dark = lipgloss.HasDarkBackground(os.Stdin, out)
+	}
+	darkBackground = &dark
+	return dark
+}
+
+// current is the theme in effect, set by ApplyTheme.
+var current Theme
+
+// CurrentTheme returns the theme set by ApplyTheme.
+func CurrentTheme() Theme {
+	return current
+}
@@ -0,0 +1,84 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BranchRef is a local or remote-tracking branch. Remote branches are named
+// without their remote, so origin/main and main share Name.
+type BranchRef struct {
+	Name   string
+	Remote string // "" for a local branch
+	When   time.Time
+}
+
+// BranchRefs lists the repo's local and remote-tracking branches, most
+// recently committed first. Remote HEAD pointers are left out.
+func BranchRefs(ctx context.Context, repoPath string) ([]BranchRef, error) {
+	out, err := gitOutput(ctx, repoPath, "for-each-ref", "--sort=-committerdate",
+		"--format=%(refname)%1f%(symref)%1f%(committerdate:unix)",
+		"refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	var refs []BranchRef
+	for _, line := range splitLines(out) {
+		f := strings.Split(line, "\x1f")
+		if len(f) < 3 || f[1] != "" {
+			continue
+		}
+		ref := BranchRef{When: unixTime(f[2])}
+		if name, ok := strings.CutPrefix(f[0], "refs/heads/"); ok {
+			ref.Name = name
+		} else {
+			rest := strings.TrimPrefix(f[0], "refs/remotes/")
+			ref.Remote, ref.Name, _ = strings.Cut(rest, "/")
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// SwitchBranch checks out branch, creating a tracking branch when only a
+// remote has it. With stash, uncommitted changes (untracked files included)
+// are stashed first and restored on the new branch; if the switch fails they
+// are restored where they were. Returns a short description of what was done.
+func SwitchBranch(ctx context.Context, repoPath, branch string, stash bool) (string, error) {
+	stashed := false
+	if stash {
+		out, err := runGit(ctx, repoPath, nil, "stash", "push", "--include-untracked", "-m", "gee: switching to "+branch)
+		if err != nil {
+			return "", err
+		}
+		stashed = !strings.Contains(out, "No local changes to save")
+	}
+
+	if _, err := runGit(ctx, repoPath, nil, "switch", branch); err != nil {
+		if stashed {
+			if popErr := popStash(ctx, repoPath); popErr != nil {
+				return "", fmt.Errorf("%w; restoring the stash also failed, changes are in stash@{0}", err)
+			}
+		}
+		return "", err
+	}
+	if !stashed {
+		return "on " + branch, nil
+	}
+	if err := popStash(ctx, repoPath); err != nil {
+		return "", fmt.Errorf("switched to %s, but restoring changes failed (kept in stash@{0}): %w", branch, err)
+	}
+	return "on " + branch + ", changes restored", nil
+}
+
+// popStash restores the newest stash, staged changes back in the index when
+// they still apply there.
+func popStash(ctx context.Context, repoPath string) error {
+	if _, err := runGit(ctx, repoPath, nil, "stash", "pop", "--index"); err == nil {
+		return nil
+	}
+	_, err := runGit(ctx, repoPath, nil, "stash", "pop")
+	return err
+}
@@ -1,7 +1,7 @@
 package util
 
 import (
-	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"sort"
@@ -9,26 +9,39 @@ import (
 	"sync"
 	"time"
 
+	"gee/pkg/gitdir"
 	"gee/pkg/types"
 )
 
 // CachedRepo is one entry in the JSON cache file.
 type CachedRepo struct {
-	Name         string    `json:"name"`
-	Path         string    `json:"path"`          // Full absolute path to the repo root (contains .git)
-	Remote       string    `json:"remote"`         // origin URL, may be ""
-	Pinned       bool      `json:"pinned"`         // true = user-curated, false = auto-discovered
-	DiscoveredAt time.Time `json:"discovered_at"`
+	Name         string      `json:"name"`
+	Path         string      `json:"path"`              // Full absolute path to the repo root (contains .git, or is the bare repo)
+	Remote       string      `json:"remote"`            // origin URL, may be ""
+	Pinned       bool        `json:"pinned"`            // true = user-curated, false = auto-discovered
+	Kind         gitdir.Kind `json:"kind,omitempty"`    // normal, worktree, submodule or bare; "" for entries written by older versions
+	Missing      bool        `json:"missing,omitempty"` // path no longer holds a repo as of the last scan
+	Tags         []string    `json:"tags,omitempty"`    // user labels, e.g. "work"; sorted, no duplicates
+	Hidden       bool        `json:"hidden,omitempty"`  // hidden by the user: kept so scans don't bring it back, but not listed
+	DiscoveredAt time.Time   `json:"discovered_at"`
 }
 
 // RepoCache manages reading/writing ~/.config/gee/cache.json.
-// Safe for concurrent use.
+// Safe for concurrent use within a process; across processes, Load and Save
+// take an advisory lock on cache.json.lock and Save merges with whatever is
+// on disk instead of overwriting it (see cache_file.go).
 type RepoCache struct {
-	path  string
-	mu    sync.Mutex
-	repos map[string]CachedRepo // keyed by absolute path for dedup
+	path    string
+	mu      sync.Mutex
+	repos   map[string]CachedRepo // keyed by absolute path for dedup
+	pending []cacheOp             // mutations since the last Save, replayed onto the file
 }
 
+// cacheOp is one mutation of the repo map. Mutations are recorded rather
+// than snapshotting state so Save can replay them onto a file another
+// process may have changed in the meantime.
+type cacheOp func(repos map[string]CachedRepo)
+
 // NewRepoCache creates a RepoCache using the default cache path.
 func NewRepoCache() *RepoCache {
 	return &RepoCache{
@@ -46,119 +59,245 @@ func DefaultCachePath() string {
 	return filepath.Join(home, ".config", "gee", "cache.json")
 }
 
-// Load reads the cache from disk. Creates the directory and file if missing.
+// Load reads the cache from disk. Creates the directory if missing.
+// A corrupt file is moved aside and the newest readable backup restored in
+// its place (with a warning on stderr) rather than failing every command.
+// Unsaved local changes are re-applied on top of what was read.
 // Returns the repos that were loaded.
 func (c *RepoCache) Load() ([]CachedRepo, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	dir := filepath.Dir(c.path)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return nil, err
 	}
 
-	data, err := os.ReadFile(c.path)
+	unlock, err := lockFile(c.path + ".lock")
 	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
 		return nil, err
 	}
+	defer unlock()
 
-	if len(data) == 0 {
-		return nil, nil
+	file, _, err := readCacheFile(c.path)
+	if err != nil {
+		if errors.Is(err, ErrCacheTooNew) {
+			return nil, err
+		}
+		file, err = c.recoverLocked(err)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	var repos []CachedRepo
-	if err := json.Unmarshal(data, &repos); err != nil {
-		return nil, err
-	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	c.repos = make(map[string]CachedRepo, len(repos))
-	for _, r := range repos {
+	c.repos = make(map[string]CachedRepo, len(file.Repos))
+	for _, r := range file.Repos {
 		c.repos[r.Path] = r
 	}
+	for _, op := range c.pending {
+		op(c.repos)
+	}
 
-	return repos, nil
+	return file.Repos, nil
+}
+
+// apply runs op on the in-memory map and records it for the next Save.
+// Caller must hold c.mu.
+func (c *RepoCache) apply(op cacheOp) {
+	op(c.repos)
+	c.pending = append(c.pending, op)
 }
 
 // Add inserts or updates a repo in memory. Returns true if it was genuinely new.
-// If the repo already exists by path, it updates Remote if previously empty
-// but does NOT overwrite Pinned (pinned is sticky).
+// If the repo already exists by path, it updates Remote and Kind if previously
+// empty and clears Missing, but does NOT overwrite Pinned (pinned is sticky)
+// or Hidden.
 func (c *RepoCache) Add(repo CachedRepo) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	existing, exists := c.repos[repo.Path]
-	if exists {
+	_, exists := c.repos[repo.Path]
+	c.apply(func(repos map[string]CachedRepo) {
+		existing, ok := repos[repo.Path]
+		if !ok {
+			repos[repo.Path] = repo
+			return
+		}
 		// Update remote if it was empty and we now have one
 		if existing.Remote == "" && repo.Remote != "" {
 			existing.Remote = repo.Remote
-			c.repos[repo.Path] = existing
 		}
+		if existing.Kind == "" && repo.Kind != "" {
+			existing.Kind = repo.Kind
+		}
+		existing.Missing = false
+		repos[repo.Path] = existing
+	})
+	return !exists
+}
+
+// Pin sets pinned=true for the repo at the given path, restoring it if
+// hidden. Returns false if not found.
+func (c *RepoCache) Pin(path string) bool {
+	return c.setPinned(path, true)
+}
+
+// Unpin sets pinned=false for the repo at the given path. Returns false if not found.
+func (c *RepoCache) Unpin(path string) bool {
+	return c.setPinned(path, false)
+}
+
+func (c *RepoCache) setPinned(path string, pinned bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.repos[path]; !ok {
 		return false
 	}
-
-	c.repos[repo.Path] = repo
+	c.apply(func(repos map[string]CachedRepo) {
+		if r, ok := repos[path]; ok {
+			r.Pinned = pinned
+			r.Hidden = r.Hidden && !pinned
+			repos[path] = r
+		}
+	})
 	return true
 }
 
-// Pin sets pinned=true for the repo at the given path. Returns false if not found.
-func (c *RepoCache) Pin(path string) bool {
+// SetHidden hides or restores the repo at path. A hidden repo stays in the
+// cache, unpinned, so scans don't rediscover it, but Shown leaves it out.
+// Returns false if not found.
+func (c *RepoCache) SetHidden(path string, hidden bool) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	r, ok := c.repos[path]
-	if !ok {
+	if _, ok := c.repos[path]; !ok {
 		return false
 	}
-	r.Pinned = true
-	c.repos[path] = r
+	c.apply(func(repos map[string]CachedRepo) {
+		if r, ok := repos[path]; ok {
+			r.Hidden = hidden
+			r.Pinned = r.Pinned && !hidden
+			repos[path] = r
+		}
+	})
 	return true
 }
 
-// Unpin sets pinned=false for the repo at the given path. Returns false if not found.
-func (c *RepoCache) Unpin(path string) bool {
+// SetTags replaces the tags of the repo at path, dropping blanks and
+// duplicates. Returns false if not found.
+func (c *RepoCache) SetTags(path string, tags []string) bool {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	r, ok := c.repos[path]
-	if !ok {
+	if _, ok := c.repos[path]; !ok {
 		return false
 	}
-	r.Pinned = false
-	c.repos[path] = r
+	tags = NormalizeTags(tags)
+	c.apply(func(repos map[string]CachedRepo) {
+		if r, ok := repos[path]; ok {
+			r.Tags = tags
+			repos[path] = r
+		}
+	})
 	return true
 }
 
+// NormalizeTags lowercases and sorts tags, dropping blanks and duplicates.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	var out []string
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// MarkMissing re-checks every entry on disk and flags those whose path no
+// longer holds a repo. Entries are kept (a pinned repo may live on an
+// unmounted drive) and unflagged as soon as they resolve again.
+// Returns the number of entries currently missing.
+func (c *RepoCache) MarkMissing() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	missingByPath := make(map[string]bool, len(c.repos))
+	count := 0
+	for path := range c.repos {
+		_, ok := gitdir.Resolve(path)
+		missingByPath[path] = !ok
+		if !ok {
+			count++
+		}
+	}
+	c.apply(func(repos map[string]CachedRepo) {
+		for path, missing := range missingByPath {
+			if r, ok := repos[path]; ok {
+				r.Missing = missing
+				repos[path] = r
+			}
+		}
+	})
+	return count
+}
+
 // Remove deletes a repo from the cache entirely.
 func (c *RepoCache) Remove(path string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.repos, path)
+	c.apply(func(repos map[string]CachedRepo) {
+		delete(repos, path)
+	})
 }
 
-// Save writes the full cache to disk atomically (write .tmp, then os.Rename).
+// Save merges local changes into the cache on disk and writes it atomically.
+// Under the cross-process lock it re-reads the file, replays every mutation
+// made since the last Save onto it, rotates the previous contents into
+// cache.json.bak.N, and replaces the file via a unique temp file + rename.
+// Entries added or pinned by another gee process in the meantime survive.
 func (c *RepoCache) Save() error {
-	c.mu.Lock()
-	repos := c.allLocked()
-	c.mu.Unlock()
-
-	data, err := json.MarshalIndent(repos, "", "  ")
-	if err != nil {
+	dir := filepath.Dir(c.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return err
 	}
 
-	dir := filepath.Dir(c.path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	unlock, err := lockFile(c.path + ".lock")
+	if err != nil {
 		return err
 	}
+	defer unlock()
 
-	tmpPath := c.path + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	file, raw, err := readCacheFile(c.path)
+	var merged map[string]CachedRepo
+	switch {
+	case errors.Is(err, ErrCacheTooNew):
 		return err
+	case err != nil:
+		// Unreadable on disk — our in-memory view is the best we have.
+		merged = c.repos
+		raw = nil
+	default:
+		merged = make(map[string]CachedRepo, len(file.Repos))
+		for _, r := range file.Repos {
+			merged[r.Path] = r
+		}
+		for _, op := range c.pending {
+			op(merged)
+		}
 	}
-	return os.Rename(tmpPath, c.path)
+
+	c.repos = merged
+	c.pending = nil
+	return writeCacheFile(c.path, c.allLocked(), raw)
 }
 
 // All returns a snapshot sorted: pinned first (alphabetical), then discovered (alphabetical).
@@ -182,6 +321,17 @@ func (c *RepoCache) allLocked() []CachedRepo {
 	return repos
 }
 
+// Shown returns All without the hidden repos.
+func (c *RepoCache) Shown() []CachedRepo {
+	var repos []CachedRepo
+	for _, r := range c.All() {
+		if !r.Hidden {
+			repos = append(repos, r)
+		}
+	}
+	return repos
+}
+
 // Pinned returns only pinned repos, sorted alphabetically.
 func (c *RepoCache) Pinned() []CachedRepo {
 	c.mu.Lock()
@@ -222,7 +372,8 @@ func (c *RepoCache) FindByPath(dir string) (CachedRepo, bool) {
 
 // LoadReposForCLI returns the repo list that a CLI command should operate on.
 // If cwd is inside a known repo, returns just that repo.
-// If all is true, returns everything. Otherwise returns only pinned.
+// If all is true, returns everything but hidden repos. Otherwise returns
+// only pinned.
 func (c *RepoCache) LoadReposForCLI(cwd string, all bool) []CachedRepo {
 	if !all {
 		if repo, found := c.FindByPath(cwd); found {
@@ -230,7 +381,7 @@ func (c *RepoCache) LoadReposForCLI(cwd string, all bool) []CachedRepo {
 		}
 	}
 	if all {
-		return c.All()
+		return c.Shown()
 	}
 	return c.Pinned()
 }
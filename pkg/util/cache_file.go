@@ -0,0 +1,248 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheSchemaVersion is the version of cache.json this build writes.
+//
+//	1: a bare JSON array of CachedRepo (gee <= 0.x)
+//	2: {"version": 2, "repos": [...]}
+const cacheSchemaVersion = 2
+
+// cacheBackups is how many previous versions are kept as cache.json.bak.N
+// (1 = newest).
+const cacheBackups = 3
+
+// ErrCacheTooNew is returned when cache.json was written by a newer gee.
+// The file is left untouched rather than downgraded.
+var ErrCacheTooNew = errors.New("cache.json was written by a newer version of gee; please upgrade")
+
+// cacheFile is the on-disk envelope.
+type cacheFile struct {
+	Version int          `json:"version"`
+	Repos   []CachedRepo `json:"repos"`
+}
+
+// cacheMigrations[v] upgrades a version-v file to version v+1 in place.
+var cacheMigrations = map[int]func(f *cacheFile) error{
+	// v1 -> v2 only introduced the envelope, which decodeCacheFile already
+	// unwrapped.
+	1: func(f *cacheFile) error { return nil },
+}
+
+// decodeCacheFile parses any known cache version and migrates it forward to
+// cacheSchemaVersion.
+func decodeCacheFile(data []byte) (cacheFile, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return cacheFile{Version: cacheSchemaVersion}, nil
+	}
+
+	var file cacheFile
+	if data[0] == '[' {
+		file.Version = 1
+		if err := json.Unmarshal(data, &file.Repos); err != nil {
+			return cacheFile{}, err
+		}
+	} else if err := json.Unmarshal(data, &file); err != nil {
+		return cacheFile{}, err
+	}
+
+	if file.Version > cacheSchemaVersion {
+		return cacheFile{}, ErrCacheTooNew
+	}
+	for file.Version < cacheSchemaVersion {
+		migrate, ok := cacheMigrations[file.Version]
+		if !ok {
+			return cacheFile{}, fmt.Errorf("no migration from cache version %d", file.Version)
+		}
+		if err := migrate(&file); err != nil {
+			return cacheFile{}, fmt.Errorf("migrate cache from version %d: %w", file.Version, err)
+		}
+		file.Version++
+	}
+	return file, nil
+}
+
+// readCacheFile reads and decodes path. A missing file is an empty cache.
+// raw is the file's bytes as read, for backup rotation.
+func readCacheFile(path string) (file cacheFile, raw []byte, err error) {
+	raw, err = os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cacheFile{Version: cacheSchemaVersion}, nil, nil
+		}
+		return cacheFile{}, nil, err
+	}
+	file, err = decodeCacheFile(raw)
+	return file, raw, err
+}
+
+// writeCacheFile encodes repos at the current schema version and replaces
+// path atomically. If prev (the bytes being replaced) differs from the new
+// contents, it is rotated into the backups first. Caller holds the lock.
+func writeCacheFile(path string, repos []CachedRepo, prev []byte) error {
+	data, err := json.MarshalIndent(cacheFile{Version: cacheSchemaVersion, Repos: repos}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(bytes.TrimSpace(prev), bytes.TrimSpace(data)) {
+		return nil
+	}
+	if len(bytes.TrimSpace(prev)) > 0 {
+		if err := rotateBackups(path, prev); err != nil {
+			VerboseLog("rotate cache backups: %s", err)
+		}
+	}
+	return WriteFileAtomic(path, data)
+}
+
+// WriteFileAtomic writes data to a uniquely named temp file next to path and
+// renames it into place, so concurrent writers never share a temp file.
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func backupPath(path string, n int) string {
+	return fmt.Sprintf("%s.bak.%d", path, n)
+}
+
+// rotateBackups shifts cache.json.bak.N up by one (dropping the oldest) and
+// writes data as cache.json.bak.1.
+func rotateBackups(path string, data []byte) error {
+	os.Remove(backupPath(path, cacheBackups))
+	for n := cacheBackups - 1; n >= 1; n-- {
+		if err := os.Rename(backupPath(path, n), backupPath(path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return WriteFileAtomic(backupPath(path, 1), data)
+}
+
+// recoverLocked handles an unreadable cache.json: the file is moved aside to
+// cache.json.corrupt-<timestamp> and the newest backup that decodes is
+// restored. With no usable backup the cache starts empty. Caller holds the
+// file lock but not c.mu.
+func (c *RepoCache) recoverLocked(cause error) (cacheFile, error) {
+	aside := fmt.Sprintf("%s.corrupt-%s", c.path, time.Now().Format("20060102-150405"))
+	if err := os.Rename(c.path, aside); err != nil {
+		return cacheFile{}, cause
+	}
+
+	for n := 1; n <= cacheBackups; n++ {
+		data, err := os.ReadFile(backupPath(c.path, n))
+		if err != nil {
+			continue
+		}
+		file, err := decodeCacheFile(data)
+		if err != nil {
+			continue
+		}
+		if err := writeCacheFile(c.path, file.Repos, nil); err != nil {
+			return cacheFile{}, err
+		}
+		warnStderr("cache.json was unreadable (%s); moved it to %s and restored backup %d", cause, filepath.Base(aside), n)
+		return file, nil
+	}
+
+	warnStderr("cache.json was unreadable (%s); moved it to %s and started an empty cache", cause, filepath.Base(aside))
+	return cacheFile{Version: cacheSchemaVersion}, nil
+}
+
+// CacheBackup describes one cache.json.bak.N file.
+type CacheBackup struct {
+	N       int
+	Path    string
+	ModTime time.Time
+	Repos   int
+	Err     error // non-nil if the backup can't be decoded
+}
+
+// Backups lists the existing backups, newest first.
+func (c *RepoCache) Backups() []CacheBackup {
+	var backups []CacheBackup
+	for n := 1; n <= cacheBackups; n++ {
+		p := backupPath(c.path, n)
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		b := CacheBackup{N: n, Path: p, ModTime: info.ModTime()}
+		if data, err := os.ReadFile(p); err != nil {
+			b.Err = err
+		} else if file, err := decodeCacheFile(data); err != nil {
+			b.Err = err
+		} else {
+			b.Repos = len(file.Repos)
+		}
+		backups = append(backups, b)
+	}
+	return backups
+}
+
+// RestoreBackup replaces cache.json with cache.json.bak.N. The current file
+// is rotated into the backups first, so a restore can itself be undone.
+// Unsaved in-memory changes are discarded.
+func (c *RepoCache) RestoreBackup(n int) error {
+	if n < 1 || n > cacheBackups {
+		return fmt.Errorf("backup must be between 1 and %d", cacheBackups)
+	}
+
+	unlock, err := lockFile(c.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(backupPath(c.path, n))
+	if err != nil {
+		return err
+	}
+	file, err := decodeCacheFile(data)
+	if err != nil {
+		return fmt.Errorf("backup %d is unreadable: %w", n, err)
+	}
+
+	current, _ := os.ReadFile(c.path)
+	if err := writeCacheFile(c.path, file.Repos, current); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.repos = make(map[string]CachedRepo, len(file.Repos))
+	for _, r := range file.Repos {
+		c.repos[r.Path] = r
+	}
+	c.pending = nil
+	return nil
+}
@@ -0,0 +1,100 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// errAny stands for any error in a test table.
+var errAny = errors.New("any error")
+
+func TestDecodeCacheFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		repos   []string // paths
+		wantErr error    // nil: no error; errAny: any error
+	}{
+		{"empty", "", nil, nil},
+		{"whitespace", " \n\t", nil, nil},
+		{"v1 array", `[{"name": "api", "path": "/r/api", "pinned": true}, {"name": "web", "path": "/r/web"}]`, []string{"/r/api", "/r/web"}, nil},
+		{"v1 empty array", "\n[]\n", nil, nil},
+		{"v2", `{"version": 2, "repos": [{"name": "api", "path": "/r/api", "tags": ["go"]}]}`, []string{"/r/api"}, nil},
+		{"too new", `{"version": 3, "repos": []}`, nil, ErrCacheTooNew},
+		{"no migration", `{"version": 0, "repos": []}`, nil, errAny},
+		{"corrupt", `{"version": 2, "repos": [`, nil, errAny},
+		{"corrupt v1", `[{"path": 1}]`, nil, errAny},
+	}
+	for _, tt := range tests {
+		file, err := decodeCacheFile([]byte(tt.data))
+		switch {
+		case tt.wantErr == nil && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+			continue
+		case tt.wantErr == errAny && err == nil, tt.wantErr != nil && tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+			continue
+		case err != nil:
+			continue
+		}
+		if file.Version != cacheSchemaVersion {
+			t.Errorf("%s: version %d, want %d", tt.name, file.Version, cacheSchemaVersion)
+		}
+		var paths []string
+		for _, r := range file.Repos {
+			paths = append(paths, r.Path)
+		}
+		if strings.Join(paths, " ") != strings.Join(tt.repos, " ") {
+			t.Errorf("%s: repos %v, want %v", tt.name, paths, tt.repos)
+		}
+	}
+}
+
+func TestDecodeCacheFileV1Fields(t *testing.T) {
+	file, err := decodeCacheFile([]byte(`[{"name": "api", "path": "/r/api", "remote": "git@h:a/api.git", "pinned": true}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := file.Repos[0]
+	if r.Name != "api" || r.Remote != "git@h:a/api.git" || !r.Pinned || r.Kind != "" || r.Hidden {
+		t.Errorf("migrated repo = %+v", r)
+	}
+}
+
+func TestWriteCacheFileRotatesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	var prev []byte
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		if err := writeCacheFile(path, []CachedRepo{{Name: name, Path: "/r/" + name}}, prev); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+		var err error
+		if prev, err = os.ReadFile(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Rewriting the same contents leaves the backups alone.
+	if err := writeCacheFile(path, []CachedRepo{{Name: "e", Path: "/r/e"}}, prev); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{path: "/r/e"}
+	for n, name := range []string{"d", "c", "b"} {
+		want[backupPath(path, n+1)] = "/r/" + name
+	}
+	for p, repo := range want {
+		file, _, err := readCacheFile(p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		if len(file.Repos) != 1 || file.Repos[0].Path != repo {
+			t.Errorf("%s holds %+v, want %s", filepath.Base(p), file.Repos, repo)
+		}
+	}
+	if _, err := os.Stat(backupPath(path, cacheBackups+1)); !os.IsNotExist(err) {
+		t.Errorf("backup %d exists: %v", cacheBackups+1, err)
+	}
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CloneOptions are the flags for clones started from discovery. The
+// [clone] table of the settings file sets the defaults; the discovery view
+// can toggle them before cloning.
+type CloneOptions struct {
+	Depth             int    `toml:"depth"`              // shallow clone of this many commits; 0 clones full history
+	Filter            string `toml:"filter"`             // partial clone filter, e.g. "blob:none"
+	SingleBranch      bool   `toml:"single_branch"`      // fetch only the default branch
+	RecurseSubmodules bool   `toml:"recurse_submodules"` // also clone submodules
+	Protocol          string `toml:"protocol"`           // "ssh" or "https"; "" uses each provider's preference
+	Concurrency       int    `toml:"concurrency"`        // clones run at once; default 4
+}
+
+// DefaultCloneConcurrency bounds parallel clones when concurrency is unset.
+const DefaultCloneConcurrency = 4
+
+// Args returns the `git clone` flags for the options.
+func (o CloneOptions) Args() []string {
+	var args []string
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, "--filter="+o.Filter)
+	}
+	if o.SingleBranch {
+		args = append(args, "--single-branch")
+	}
+	if o.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	return args
+}
+
+// Workers returns how many clones may run at once.
+func (o CloneOptions) Workers() int {
+	if o.Concurrency <= 0 {
+		return DefaultCloneConcurrency
+	}
+	return o.Concurrency
+}
+
+// Summary describes the options in a few words, e.g. "depth 1 · blob:none · https".
+func (o CloneOptions) Summary() string {
+	var parts []string
+	if o.Depth > 0 {
+		parts = append(parts, "depth "+strconv.Itoa(o.Depth))
+	}
+	if o.Filter != "" {
+		parts = append(parts, o.Filter)
+	}
+	if o.SingleBranch {
+		parts = append(parts, "single-branch")
+	}
+	if o.RecurseSubmodules {
+		parts = append(parts, "submodules")
+	}
+	if o.Protocol != "" {
+		parts = append(parts, o.Protocol)
+	}
+	if len(parts) == 0 {
+		return "full clone"
+	}
+	return strings.Join(parts, " · ")
+}
+
+// cloneProgressRe matches git's progress lines, e.g.
+// "Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s".
+var cloneProgressRe = regexp.MustCompile(`^(?:remote: )?([A-Z][a-z]+(?: [a-z]+)*):\s+(\d+)%`)
+
+// ParseCloneProgress extracts the phase ("Receiving objects", "Resolving
+// deltas", ...) and percentage from one line of `git clone --progress`.
+func ParseCloneProgress(line string) (phase string, percent int, ok bool) {
+	m := cloneProgressRe.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return "", 0, false
+	}
+	percent, _ = strconv.Atoi(m[2])
+	return m[1], percent, true
+}
+
+// CloneFailureReason picks the line of a failed clone's stderr that says
+// why: the first "fatal:" or "error:" line (later ones tend to be generic,
+// like "Could not read from remote repository"), else the last line that
+// isn't progress output.
+func CloneFailureReason(stderr string) string {
+	lines := strings.FieldsFunc(stderr, func(r rune) bool { return r == '\n' || r == '\r' })
+	last := ""
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+			return line
+		}
+		if _, _, ok := ParseCloneProgress(line); !ok && line != "" {
+			last = line
+		}
+	}
+	if last == "" {
+		return "git clone failed"
+	}
+	return last
+}
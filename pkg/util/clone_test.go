@@ -0,0 +1,47 @@
+package util
+
+import "testing"
+
+func TestParseCloneProgress(t *testing.T) {
+	tests := []struct {
+		line    string
+		phase   string
+		percent int
+		ok      bool
+	}{
+		{"Receiving objects:  45% (450/1000), 1.20 MiB | 2.00 MiB/s", "Receiving objects", 45, true},
+		{"Resolving deltas: 100% (312/312), done.", "Resolving deltas", 100, true},
+		{"remote: Counting objects:   7% (7/100)", "Counting objects", 7, true},
+		{"remote: Compressing objects: 100% (80/80), done.", "Compressing objects", 100, true},
+		{"  Updating files:   0% (0/12)\r", "Updating files", 0, true},
+		{"Cloning into 'api'...", "", 0, false},
+		{"remote: Enumerating objects: 1000, done.", "", 0, false},
+		{"fatal: repository 'x' not found", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		phase, percent, ok := ParseCloneProgress(tt.line)
+		if phase != tt.phase || percent != tt.percent || ok != tt.ok {
+			t.Errorf("ParseCloneProgress(%q) = %q, %d, %v; want %q, %d, %v",
+				tt.line, phase, percent, ok, tt.phase, tt.percent, tt.ok)
+		}
+	}
+}
+
+func TestCloneFailureReason(t *testing.T) {
+	tests := []struct {
+		stderr string
+		want   string
+	}{
+		{"Cloning into 'api'...\nERROR: Repository not found.\nfatal: Could not read from remote repository.\n", "fatal: Could not read from remote repository."},
+		{"Cloning into 'api'...\nerror: RPC failed\nfatal: early EOF\n", "error: RPC failed"},
+		{"Cloning into 'api'...\rReceiving objects:  12% (1/8)\rReceiving objects:  50% (4/8)\r", "Cloning into 'api'..."},
+		{"Receiving objects:  12% (1/8)\n", "git clone failed"},
+		{"", "git clone failed"},
+	}
+	for _, tt := range tests {
+		if got := CloneFailureReason(tt.stderr); got != tt.want {
+			t.Errorf("CloneFailureReason(%q) = %q, want %q", tt.stderr, got, tt.want)
+		}
+	}
+}
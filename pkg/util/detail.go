@@ -0,0 +1,271 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"gee/pkg/gitdir"
+	"gee/pkg/ui"
+)
+
+// RepoDetail is everything the dashboard's detail pane shows for one repo.
+type RepoDetail struct {
+	Path     string
+	Commits  []CommitInfo
+	Files    []FileChange
+	Stashes  []StashInfo
+	Branches []BranchInfo
+	Remotes  []RemoteInfo
+	State    string // in-progress operation from ui.DetectGitState: "REBASE", "MERGE", ...
+	Progress string // e.g. "3/5" during a rebase
+	Bare     bool
+}
+
+// CommitInfo is one line of the recent history.
+type CommitInfo struct {
+	Hash    string // abbreviated
+	Subject string
+	Author  string
+	When    time.Time
+}
+
+// FileChange is one entry of `git status --porcelain=v2`. XY is the
+// two-letter porcelain code: index status then worktree status, "." for
+// unchanged, "??" for untracked.
+type FileChange struct {
+	XY       string
+	Path     string
+	OrigPath string // source of a rename or copy
+}
+
+// Staged reports whether the change is in the index.
+func (f FileChange) Staged() bool {
+	return f.XY != "??" && f.XY[0] != '.'
+}
+
+// Unstaged reports whether the change is only in the working tree.
+func (f FileChange) Unstaged() bool {
+	return f.XY == "??" || f.XY[1] != '.'
+}
+
+// Conflicted reports whether the file has merge conflicts.
+func (f FileChange) Conflicted() bool {
+	switch f.XY {
+	case "DD", "AU", "UD", "UA", "DU", "AA", "UU":
+		return true
+	}
+	return false
+}
+
+// StashInfo is one stash entry.
+type StashInfo struct {
+	Ref     string // stash@{0}
+	Subject string
+}
+
+// BranchInfo is a local branch and how it compares to its upstream.
+type BranchInfo struct {
+	Name     string
+	Upstream string
+	Ahead    int
+	Behind   int
+	Gone     bool // the upstream was deleted
+	Current  bool
+	When     time.Time // committer date of the tip
+}
+
+// RemoteInfo is a configured remote and its fetch URL.
+type RemoteInfo struct {
+	Name string
+	URL  string
+}
+
+// LoadRepoDetail gathers the detail pane's data for the repo at repoPath,
+// with up to commits recent commits. Sections that fail to load (e.g. log
+// on an unborn branch) are left empty; only an unusable repo is an error.
+func LoadRepoDetail(ctx context.Context, repoPath string, commits int) (RepoDetail, error) {
+	d := RepoDetail{Path: repoPath}
+	if _, ok := gitdir.Resolve(repoPath); !ok {
+		return d, NewWarning(fmt.Sprintf("%s is not a git repository", repoPath))
+	}
+	d.Bare = gitdir.IsBare(repoPath)
+	d.State, d.Progress = ui.DetectGitState(repoPath)
+
+	if out, err := gitOutput(ctx, repoPath, "log", "-n", strconv.Itoa(commits), "--format=%h%x1f%s%x1f%an%x1f%ct"); err == nil {
+		d.Commits = parseCommits(out)
+	}
+	if !d.Bare {
+		if out, err := gitOutput(ctx, repoPath, "status", "--porcelain=v2", "-z"); err == nil {
+			d.Files = ParseFileChanges(out)
+		} else {
+			return d, err
+		}
+		if out, err := gitOutput(ctx, repoPath, "stash", "list", "--format=%gd%x1f%s"); err == nil {
+			for _, line := range splitLines(out) {
+				ref, subject, _ := strings.Cut(line, "\x1f")
+				d.Stashes = append(d.Stashes, StashInfo{Ref: ref, Subject: subject})
+			}
+		}
+	}
+	if branches, err := LocalBranches(ctx, repoPath); err == nil {
+		d.Branches = branches
+	}
+	if out, err := gitOutput(ctx, repoPath, "remote", "-v"); err == nil {
+		d.Remotes = parseRemotes(out)
+	}
+	return d, nil
+}
+
+// LocalBranches lists local branches with their upstream tracking state,
+// most recently committed first.
+func LocalBranches(ctx context.Context, repoPath string) ([]BranchInfo, error) {
+	out, err := gitOutput(ctx, repoPath, "for-each-ref", "--sort=-committerdate",
+		"--format=%(refname:short)%1f%(upstream:short)%1f%(upstream:track,nobracket)%1f%(HEAD)%1f%(committerdate:unix)",
+		"refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	var branches []BranchInfo
+	for _, line := range splitLines(out) {
+		f := strings.Split(line, "\x1f")
+		if len(f) < 5 {
+			continue
+		}
+		b := BranchInfo{Name: f[0], Upstream: f[1], Current: f[3] == "*", When: unixTime(f[4])}
+		// track is "ahead 2, behind 1", "gone" or "".
+		for _, part := range strings.Split(f[2], ", ") {
+			switch {
+			case part == "gone":
+				b.Gone = true
+			case strings.HasPrefix(part, "ahead "):
+				b.Ahead, _ = strconv.Atoi(strings.TrimPrefix(part, "ahead "))
+			case strings.HasPrefix(part, "behind "):
+				b.Behind, _ = strconv.Atoi(strings.TrimPrefix(part, "behind "))
+			}
+		}
+		branches = append(branches, b)
+	}
+	return branches, nil
+}
+
+// ParseFileChanges parses NUL-separated `git status --porcelain=v2 -z`
+// output into one FileChange per entry.
+func ParseFileChanges(out string) []FileChange {
+	var files []FileChange
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		e := entries[i]
+		if len(e) < 2 {
+			continue
+		}
+		switch e[0] {
+		case '1':
+			// 1 XY sub mH mI mW hH hI path
+			if f := strings.SplitN(e, " ", 9); len(f) == 9 {
+				files = append(files, FileChange{XY: f[1], Path: f[8]})
+			}
+		case '2':
+			// 2 XY sub mH mI mW hH hI Xscore path, then origPath as the next entry
+			if f := strings.SplitN(e, " ", 10); len(f) == 10 {
+				fc := FileChange{XY: f[1], Path: f[9]}
+				if i+1 < len(entries) {
+					fc.OrigPath = entries[i+1]
+					i++
+				}
+				files = append(files, fc)
+			}
+		case 'u':
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			if f := strings.SplitN(e, " ", 11); len(f) == 11 {
+				files = append(files, FileChange{XY: f[1], Path: f[10]})
+			}
+		case '?':
+			files = append(files, FileChange{XY: "??", Path: e[2:]})
+		}
+	}
+	return files
+}
+
+func parseCommits(out string) []CommitInfo {
+	var commits []CommitInfo
+	for _, line := range splitLines(out) {
+		f := strings.Split(line, "\x1f")
+		if len(f) < 4 {
+			continue
+		}
+		commits = append(commits, CommitInfo{Hash: f[0], Subject: f[1], Author: f[2], When: unixTime(f[3])})
+	}
+	return commits
+}
+
+// parseRemotes reads `git remote -v`, keeping each remote's fetch URL.
+func parseRemotes(out string) []RemoteInfo {
+	var remotes []RemoteInfo
+	for _, line := range splitLines(out) {
+		f := strings.Fields(line)
+		if len(f) >= 3 && f[2] == "(fetch)" {
+			remotes = append(remotes, RemoteInfo{Name: f[0], URL: f[1]})
+		}
+	}
+	return remotes
+}
+
+func unixTime(s string) time.Time {
+	sec, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+func splitLines(out string) []string {
+	var lines []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// gitOutput runs a read-only git command in repoPath and returns stdout.
+// Optional locks are off so reads never rewrite the index, which would wake
+// the filesystem watcher.
+func gitOutput(ctx context.Context, repoPath string, args ...string) (string, error) {
+	return runGit(ctx, repoPath, []string{"GIT_OPTIONAL_LOCKS=0"}, args...)
+}
+
+// runGit runs git in repoPath with env added to the environment. A failure
+// carries git's first fatal: or error: line, else all of its stderr, or its
+// stdout when stderr is empty: some commands, like a commit with nothing
+// staged, explain themselves there.
+func runGit(ctx context.Context, repoPath string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", repoPath}, args...)...)
+	cmd.Env = append(os.Environ(), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		for _, line := range strings.Split(msg, "\n") {
+			if strings.HasPrefix(line, "fatal:") || strings.HasPrefix(line, "error:") {
+				msg = line
+				break
+			}
+		}
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
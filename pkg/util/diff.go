@@ -0,0 +1,148 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxUntrackedLines caps how much of a new, untracked file a diff shows.
+const maxUntrackedLines = 2000
+
+// DiffSection says which changes a DiffFile holds.
+type DiffSection int
+
+const (
+	DiffStaged    DiffSection = iota // index vs HEAD
+	DiffUnstaged                     // worktree vs index
+	DiffUntracked                    // a new file git doesn't know yet
+)
+
+func (s DiffSection) String() string {
+	switch s {
+	case DiffStaged:
+		return "staged"
+	case DiffUnstaged:
+		return "unstaged"
+	default:
+		return "untracked"
+	}
+}
+
+// DiffFile is one file's patch.
+type DiffFile struct {
+	Path     string
+	OrigPath string // for renames
+	Section  DiffSection
+	Header   []string // "diff --git", index, mode and ---/+++ lines
+	Hunks    []DiffHunk
+	Binary   bool
+}
+
+// DiffHunk is one @@ block: its header line and the lines that follow.
+type DiffHunk struct {
+	Header string
+	Lines  []string // each starting with ' ', '+', '-' or '\'
+}
+
+// LoadDiff returns the repo's staged changes, then its unstaged changes,
+// then its untracked files rendered as additions.
+func LoadDiff(ctx context.Context, repoPath string) ([]DiffFile, error) {
+	staged, err := gitOutput(ctx, repoPath, "diff", "--cached", "--no-color", "--no-ext-diff", "-M")
+	if err != nil {
+		return nil, err
+	}
+	unstaged, err := gitOutput(ctx, repoPath, "diff", "--no-color", "--no-ext-diff")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := gitOutput(ctx, repoPath, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+
+	files := ParseDiff(staged, DiffStaged)
+	files = append(files, ParseDiff(unstaged, DiffUnstaged)...)
+	for _, path := range strings.Split(untracked, "\x00") {
+		if path != "" {
+			files = append(files, untrackedDiff(repoPath, path))
+		}
+	}
+	return files, nil
+}
+
+// ParseDiff splits unified `git diff` output into files and hunks.
+func ParseDiff(out string, section DiffSection) []DiffFile {
+	var files []DiffFile
+	var file *DiffFile
+	var hunk *DiffHunk
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			files = append(files, DiffFile{Section: section, Header: []string{line}, Path: diffGitPath(line)})
+			file = &files[len(files)-1]
+			hunk = nil
+		case file == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			file.Hunks = append(file.Hunks, DiffHunk{Header: line})
+			hunk = &file.Hunks[len(file.Hunks)-1]
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			file.Header = append(file.Header, line)
+			switch {
+			case strings.HasPrefix(line, "+++ b/"):
+				file.Path = strings.TrimPrefix(line, "+++ b/")
+			case strings.HasPrefix(line, "rename from "):
+				file.OrigPath = strings.TrimPrefix(line, "rename from ")
+			case strings.HasPrefix(line, "rename to "):
+				file.Path = strings.TrimPrefix(line, "rename to ")
+			case strings.HasPrefix(line, "Binary files "):
+				file.Binary = true
+			}
+		}
+	}
+	return files
+}
+
+// diffGitPath takes the path from a "diff --git a/x b/x" line, which is
+// all there is for binary files and mode changes.
+func diffGitPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return rest
+}
+
+// untrackedDiff renders a new file as a single all-added hunk.
+func untrackedDiff(repoPath, path string) DiffFile {
+	f := DiffFile{Path: path, Section: DiffUntracked, Header: []string{"new file " + path}}
+	data, err := os.ReadFile(filepath.Join(repoPath, path))
+	if err != nil {
+		f.Header = append(f.Header, err.Error())
+		return f
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		f.Binary = true
+		return f
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	hunk := DiffHunk{Header: fmt.Sprintf("@@ -0,0 +1,%d @@", len(lines))}
+	for i, line := range lines {
+		if i == maxUntrackedLines {
+			hunk.Lines = append(hunk.Lines, fmt.Sprintf("\\ %d more lines", len(lines)-i))
+			break
+		}
+		hunk.Lines = append(hunk.Lines, "+"+line)
+	}
+	f.Hunks = []DiffHunk{hunk}
+	return f
+}
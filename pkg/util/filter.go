@@ -0,0 +1,216 @@
+package util
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gee/pkg/ui"
+)
+
+// RepoFilter is a parsed filter expression, shared by the dashboard's /
+// filter and the CLI's --filter. An expression is whitespace-separated
+// terms, all of which must match:
+//
+//	api              fuzzy match on the repo's name
+//	branch:feat/*    current branch (* and ? are wildcards)
+//	path:~/work      repo path: under a directory, or a substring
+//	remote:gitlab    origin URL
+//	tag:payments     one of the repo's tags
+//	is:dirty         dirty, clean, behind, ahead, stale, conflict, pinned, missing, hidden
+//
+// A leading - negates a term: -is:clean, -tag:archive, -legacy. A negated
+// name is a plain substring, since almost everything fuzzy-matches a
+// short word.
+type RepoFilter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	key    string // "" for a name
+	value  string // lowercased
+	negate bool
+	glob   *regexp.Regexp // for values with wildcards
+}
+
+// filterStates are the values is: accepts.
+var filterStates = []string{"dirty", "clean", "behind", "ahead", "stale", "conflict", "pinned", "missing", "hidden"}
+
+// FilterRepo is what a filter looks at in one repo.
+type FilterRepo struct {
+	Name   string // display name
+	Path   string
+	Remote string
+	Pinned bool
+	Hidden bool
+	Tags   []string
+
+	Missing bool
+	Known   bool // Status holds a real status; if not, is: and branch: terms don't match
+	Status  ui.StatusSummary
+}
+
+// ParseFilter parses a filter expression. An empty expression matches
+// every repo.
+func ParseFilter(expr string) (RepoFilter, error) {
+	var f RepoFilter
+	for _, field := range strings.Fields(expr) {
+		t := filterTerm{}
+		if strings.HasPrefix(field, "-") && len(field) > 1 {
+			t.negate = true
+			field = field[1:]
+		}
+		if key, value, ok := strings.Cut(field, ":"); ok {
+			t.key = strings.ToLower(key)
+			field = value
+		}
+		t.value = strings.ToLower(field)
+
+		switch t.key {
+		case "":
+		case "branch", "path", "remote", "tag":
+			if t.value == "" {
+				return RepoFilter{}, fmt.Errorf("%s: needs a value", t.key)
+			}
+			if t.key == "path" {
+				t.value = strings.ToLower(ExpandHome(field))
+			}
+			if strings.ContainsAny(t.value, "*?") {
+				t.glob = globRegexp(t.value)
+			}
+		case "is":
+			if !contains(filterStates, t.value) {
+				return RepoFilter{}, fmt.Errorf("is:%s: want one of %s", t.value, strings.Join(filterStates, ", "))
+			}
+		default:
+			return RepoFilter{}, fmt.Errorf("unknown qualifier %s: (try branch:, path:, remote:, tag: or is:)", t.key)
+		}
+		f.terms = append(f.terms, t)
+	}
+	return f, nil
+}
+
+// globRegexp turns a wildcard pattern into an anchored regexp where *
+// matches anything, slashes included.
+func globRegexp(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	quoted = strings.ReplaceAll(quoted, `\?`, ".")
+	return regexp.MustCompile("^" + quoted + "$")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty reports whether the filter matches everything.
+func (f RepoFilter) Empty() bool {
+	return len(f.terms) == 0
+}
+
+// NeedsStatus reports whether matching looks at git status, which the
+// CLI then has to collect first.
+func (f RepoFilter) NeedsStatus() bool {
+	for _, t := range f.terms {
+		if t.key == "is" && t.value != "pinned" && t.value != "missing" && t.value != "hidden" || t.key == "branch" {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether r passes every term. positions are the rune
+// indices of r.Name matched by name terms, for highlighting.
+func (f RepoFilter) Match(r FilterRepo) (positions []int, ok bool) {
+	for _, t := range f.terms {
+		hit, pos := t.match(r)
+		if hit == t.negate {
+			return nil, false
+		}
+		if !t.negate {
+			positions = append(positions, pos...)
+		}
+	}
+	return positions, true
+}
+
+func (t filterTerm) match(r FilterRepo) (bool, []int) {
+	switch t.key {
+	case "":
+		if t.negate {
+			return strings.Contains(strings.ToLower(r.Name), t.value), nil
+		}
+		_, positions, ok := FuzzyMatch(t.value, r.Name)
+		return ok, positions
+	case "branch":
+		return r.Known && t.matchValue(r.Status.Branch), nil
+	case "path":
+		path := strings.ToLower(r.Path)
+		if t.glob == nil && filepath.IsAbs(t.value) {
+			dir := strings.TrimSuffix(t.value, string(filepath.Separator))
+			return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)), nil
+		}
+		return t.matchValue(path), nil
+	case "remote":
+		return t.matchValue(r.Remote), nil
+	case "tag":
+		for _, tag := range r.Tags {
+			if t.glob != nil && t.glob.MatchString(tag) || t.glob == nil && tag == t.value {
+				return true, nil
+			}
+		}
+		return false, nil
+	case "is":
+		return r.Is(t.value), nil
+	}
+	return false, nil
+}
+
+// matchValue matches s against a wildcard pattern in full, or else looks
+// for the value as a substring.
+func (t filterTerm) matchValue(s string) bool {
+	s = strings.ToLower(s)
+	if t.glob != nil {
+		return t.glob.MatchString(s)
+	}
+	return strings.Contains(s, t.value)
+}
+
+// Is reports whether the repo is in one of filterStates. Status states are
+// false while the status is unknown.
+func (r FilterRepo) Is(state string) bool {
+	s := r.Status
+	switch state {
+	case "pinned":
+		return r.Pinned
+	case "missing":
+		return r.Missing
+	case "hidden":
+		return r.Hidden
+	}
+	if !r.Known || r.Missing {
+		return false
+	}
+	dirty := s.Staged+s.Modified+s.Untracked+s.Conflicts > 0
+	switch state {
+	case "dirty":
+		return dirty
+	case "clean":
+		return !dirty
+	case "behind":
+		return s.Behind > 0
+	case "ahead":
+		return s.Ahead > 0
+	case "stale":
+		return s.Stale
+	case "conflict":
+		return s.Conflicts > 0 || s.State != ""
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+package util
+
+import (
+	"testing"
+
+	"gee/pkg/ui"
+)
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{"", true},
+		{"  api   web ", true},
+		{"-legacy", true},
+		{"-", true}, // a lone dash is a name
+		{"Branch:main IS:Dirty", true},
+		{"tag:pay* -is:clean path:~/work remote:gitlab", true},
+		{"branch:", false},
+		{"tag:", false},
+		{"is:", false},
+		{"is:broken", false},
+		{"owner:acme", false},
+	}
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.expr)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseFilter(%q) err = %v, want ok = %v", tt.expr, err, tt.ok)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	t.Setenv("HOME", "/home/me")
+	api := FilterRepo{
+		Name:   "api",
+		Path:   "/home/me/work/api",
+		Remote: "git@gitlab.com:acme/api.git",
+		Tags:   []string{"payments", "go"},
+		Known:  true,
+		Status: ui.StatusSummary{Branch: "feat/login", Modified: 2, Behind: 1},
+	}
+	unknown := api
+	unknown.Known = false
+	unknown.Status = ui.StatusSummary{}
+
+	tests := []struct {
+		expr string
+		repo FilterRepo
+		want bool
+	}{
+		{"", api, true},
+		{"ai", api, true},
+		{"web", api, false},
+		{"-legacy", api, true},
+		{"-ap", api, false},
+		{"branch:feat/*", api, true},
+		{"branch:FEAT", api, true},
+		{"branch:main", api, false},
+		{"branch:feat/*", unknown, false},
+		{"path:~/work", api, true},
+		{"path:~/wor", api, false}, // a directory, not a prefix
+		{"path:work/a", api, true},
+		{"path:/home/me/*/api", api, true},
+		{"remote:gitlab", api, true},
+		{"remote:github", api, false},
+		{"tag:pay*", api, true},
+		{"tag:pay", api, false},
+		{"-tag:archive", api, true},
+		{"is:dirty is:behind", api, true},
+		{"is:dirty -is:behind", api, false},
+		{"-is:clean", unknown, true},
+		{"is:dirty", unknown, false},
+		{"api is:clean", api, false},
+	}
+	for _, tt := range tests {
+		f, err := ParseFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", tt.expr, err)
+		}
+		if _, got := f.Match(tt.repo); got != tt.want {
+			t.Errorf("%q matches %s (known %v) = %v, want %v", tt.expr, tt.repo.Name, tt.repo.Known, got, tt.want)
+		}
+	}
+}
+
+func TestFilterRepoIs(t *testing.T) {
+	known := func(s ui.StatusSummary) FilterRepo {
+		return FilterRepo{Name: "r", Known: true, Status: s}
+	}
+	tests := []struct {
+		name string
+		repo FilterRepo
+		is   []string // every state the repo is in
+	}{
+		{"clean", known(ui.StatusSummary{Branch: "main"}), []string{"clean"}},
+		{"dirty", known(ui.StatusSummary{Untracked: 1, Stale: true}), []string{"dirty", "stale"}},
+		{"ahead and behind", known(ui.StatusSummary{Ahead: 1, Behind: 2}), []string{"clean", "behind", "ahead"}},
+		{"conflicts", known(ui.StatusSummary{Conflicts: 1}), []string{"dirty", "conflict"}},
+		{"rebasing", known(ui.StatusSummary{State: "REBASE", Progress: "1/3"}), []string{"clean", "conflict"}},
+		{"bare", known(ui.StatusSummary{Branch: "main", Bare: true}), []string{"clean"}},
+		{"unknown", FilterRepo{Name: "r", Pinned: true}, []string{"pinned"}},
+		{"missing", FilterRepo{Name: "r", Known: true, Missing: true, Hidden: true, Status: ui.StatusSummary{Modified: 1}}, []string{"missing", "hidden"}},
+	}
+	for _, tt := range tests {
+		want := make(map[string]bool, len(tt.is))
+		for _, s := range tt.is {
+			want[s] = true
+		}
+		for _, state := range filterStates {
+			if got := tt.repo.Is(state); got != want[state] {
+				t.Errorf("%s: Is(%q) = %v, want %v", tt.name, state, got, want[state])
+			}
+		}
+	}
+}
+
+func TestFilterNeedsStatus(t *testing.T) {
+	tests := map[string]bool{
+		"":                       false,
+		"api tag:go path:~/work": false,
+		"is:pinned -is:hidden":   false,
+		"is:missing":             false,
+		"is:dirty":               true,
+		"-is:conflict":           true,
+		"branch:main":            true,
+	}
+	for expr, want := range tests {
+		f, err := ParseFilter(expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", expr, err)
+		}
+		if got := f.NeedsStatus(); got != want {
+			t.Errorf("ParseFilter(%q).NeedsStatus() = %v, want %v", expr, got, want)
+		}
+	}
+}
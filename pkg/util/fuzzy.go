@@ -0,0 +1,60 @@
+package util
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FuzzyMatch reports whether pattern's characters appear in s in order,
+// ignoring case. The score rewards contiguous runs and matches at the start
+// of a word, so "fx" ranks "feat/x" above "fix-xml"; higher is better.
+// positions are the rune indices of s that matched, for highlighting.
+func FuzzyMatch(pattern, s string) (score int, positions []int, ok bool) {
+	if pattern == "" {
+		return 0, nil, true
+	}
+	p := []rune(strings.ToLower(pattern))
+	r := []rune(strings.ToLower(s))
+
+	// A plain substring beats any scattered match.
+	if i := strings.Index(string(r), string(p)); i >= 0 {
+		start := len([]rune(string(r)[:i]))
+		for j := range p {
+			positions = append(positions, start+j)
+		}
+		score = 10 * len(p)
+		if start == 0 || isWordBoundary(r[start-1]) {
+			score += 5
+		}
+		return score, positions, true
+	}
+
+	pi := 0
+	prev := -2
+	for i, c := range r {
+		if pi == len(p) {
+			break
+		}
+		if c != p[pi] {
+			continue
+		}
+		score++
+		if i == prev+1 {
+			score += 3
+		}
+		if i == 0 || isWordBoundary(r[i-1]) {
+			score += 2
+		}
+		positions = append(positions, i)
+		prev = i
+		pi++
+	}
+	if pi < len(p) {
+		return 0, nil, false
+	}
+	return score, positions, true
+}
+
+func isWordBoundary(c rune) bool {
+	return c == '/' || c == '-' || c == '_' || c == '.' || unicode.IsSpace(c)
+}
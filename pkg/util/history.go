@@ -0,0 +1,240 @@
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// historyTailLines is how much of each repo's stdout/stderr is kept.
+const historyTailLines = 20
+
+// historyMaxBytes is how large history.jsonl grows before it is rotated to
+// history.jsonl.1, replacing the previous rotation. The history is at most
+// twice this.
+const historyMaxBytes = 4 << 20
+
+// HistoryEntry records one batch operation (a pull, fetch, push or exec over
+// one or more repos) in ~/.config/gee/history.jsonl.
+type HistoryEntry struct {
+	ID        int             `json:"id"`
+	Command   string          `json:"command"`        // "pull", "fetch", "push", "exec"
+	Args      string          `json:"args,omitempty"` // the shell command for exec
+	Source    string          `json:"source"`         // "cli" or "tui"
+	StartedAt time.Time       `json:"started_at"`
+	Duration  time.Duration   `json:"duration"`
+	Results   []HistoryResult `json:"results"`
+}
+
+// HistoryResult is one repo's outcome within a HistoryEntry.
+type HistoryResult struct {
+	Name       string        `json:"name"`
+	Path       string        `json:"path"`
+	Failed     bool          `json:"failed"`
+	Duration   time.Duration `json:"duration"`
+	StdoutTail string        `json:"stdout_tail,omitempty"`
+	StderrTail string        `json:"stderr_tail,omitempty"`
+}
+
+// Failures returns the results that failed.
+func (e HistoryEntry) Failures() []HistoryResult {
+	var failed []HistoryResult
+	for _, r := range e.Results {
+		if r.Failed {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// Label is a short human description, e.g. "pull" or "exec: git fetch".
+func (e HistoryEntry) Label() string {
+	if e.Args != "" {
+		return e.Command + ": " + e.Args
+	}
+	return e.Command
+}
+
+// DefaultHistoryPath returns ~/.config/gee/history.jsonl.
+func DefaultHistoryPath() string {
+	return filepath.Join(filepath.Dir(DefaultCachePath()), "history.jsonl")
+}
+
+// NewHistoryResult builds a result, keeping only the tail of the output.
+func NewHistoryResult(name, path string, failed bool, duration time.Duration, stdout, stderr string) HistoryResult {
+	return HistoryResult{
+		Name:       name,
+		Path:       path,
+		Failed:     failed,
+		Duration:   duration,
+		StdoutTail: tailLines(stdout, historyTailLines),
+		StderrTail: tailLines(stderr, historyTailLines),
+	}
+}
+
+// AppendHistory assigns entry the next ID and appends it to the history
+// file under the cross-process lock, rotating the file once it is over
+// historyMaxBytes. Returns the assigned ID.
+func AppendHistory(entry HistoryEntry) (int, error) {
+	path := DefaultHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	last, err := lastHistoryID(path)
+	if err != nil {
+		return 0, err
+	}
+	if last == 0 {
+		// Just rotated: carry on from the previous file.
+		if last, err = lastHistoryID(path + ".1"); err != nil {
+			return 0, err
+		}
+	}
+	entry.ID = last + 1
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return 0, err
+	}
+	if info, err := f.Stat(); err == nil && info.Size() > historyMaxBytes {
+		if err := os.Rename(path, path+".1"); err != nil {
+			VerboseLog("rotate history: %s", err)
+		}
+	}
+	return entry.ID, nil
+}
+
+// LoadHistory returns every recorded entry, oldest first, including those
+// in the last rotation.
+func LoadHistory() ([]HistoryEntry, error) {
+	path := DefaultHistoryPath()
+	old, err := readHistory(path + ".1")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readHistory(path)
+	if err != nil {
+		return nil, err
+	}
+	return append(old, entries...), nil
+}
+
+// FindHistory returns the entry with the given ID.
+func FindHistory(id int) (HistoryEntry, error) {
+	entries, err := LoadHistory()
+	if err != nil {
+		return HistoryEntry{}, err
+	}
+	for _, e := range entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return HistoryEntry{}, NewWarning(fmt.Sprintf("no history entry %d", id))
+}
+
+// readHistory parses the JSONL file. Lines that don't parse (e.g. a write
+// cut short by a crash) are skipped rather than failing the whole history.
+func readHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// lastHistoryID returns the ID of the last entry in the file at path, or 0
+// if there is none, reading only as much of the end of the file as it
+// takes. A last line that doesn't parse (a write cut short by a crash)
+// falls back to reading the whole file.
+func lastHistoryID(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	size := info.Size()
+	for chunk := int64(64 * 1024); ; chunk *= 4 {
+		start := max(size-chunk, 0)
+		buf := make([]byte, size-start)
+		if _, err := f.ReadAt(buf, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		buf = bytes.TrimSpace(buf)
+		i := bytes.LastIndexByte(buf, '\n')
+		if i < 0 && start > 0 {
+			continue // the last line started before this chunk
+		}
+		if len(buf) == 0 {
+			return 0, nil
+		}
+		var e HistoryEntry
+		if err := json.Unmarshal(buf[i+1:], &e); err == nil {
+			return e.ID, nil
+		}
+		break
+	}
+
+	entries, err := readHistory(path)
+	if err != nil || len(entries) == 0 {
+		return 0, err
+	}
+	return entries[len(entries)-1].ID, nil
+}
+
+// tailLines returns the last n lines of s.
+func tailLines(s string, n int) string {
+	s = strings.TrimRight(s, "\n")
+	lines := strings.Split(s, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
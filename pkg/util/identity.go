@@ -0,0 +1,156 @@
+package util
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gee/pkg/remote"
+)
+
+// A repo's identity is its Path: the absolute path of the repo root, which
+// is also its key in cache.json. Names are only for display, since two
+// clones named "api" under different orgs are distinct repos.
+
+// DisplayNames returns a display name for every repo, keyed by Path. A name
+// that is unique among repos is used as is. Colliding repos are shown as
+// owner/name from their remote when that tells them apart, otherwise as the
+// shortest path suffix that does (e.g. "work/api" vs "oss/api").
+func DisplayNames(repos []CachedRepo) map[string]string {
+	byName := make(map[string][]CachedRepo, len(repos))
+	for _, r := range repos {
+		byName[r.Name] = append(byName[r.Name], r)
+	}
+
+	names := make(map[string]string, len(repos))
+	for name, group := range byName {
+		if len(group) == 1 {
+			names[group[0].Path] = name
+			continue
+		}
+
+		slugCount := make(map[string]int, len(group))
+		for _, r := range group {
+			if slug := RemoteSlug(r.Remote); slug != "" {
+				slugCount[slug]++
+			}
+		}
+		for _, r := range group {
+			if slug := RemoteSlug(r.Remote); slug != "" && slugCount[slug] == 1 {
+				names[r.Path] = slug
+				continue
+			}
+			names[r.Path] = uniquePathSuffix(r.Path, group)
+		}
+	}
+	return names
+}
+
+// DisplayNames returns display names for every cached repo, keyed by Path.
+func (c *RepoCache) DisplayNames() map[string]string {
+	return DisplayNames(c.All())
+}
+
+// DisplayName looks up path in names, falling back to the directory name
+// for repos that aren't in the cache.
+func DisplayName(names map[string]string, path string) string {
+	if name, ok := names[path]; ok {
+		return name
+	}
+	return filepath.Base(path)
+}
+
+// uniquePathSuffix returns the fewest trailing path components of path that
+// no other repo in group ends with.
+func uniquePathSuffix(path string, group []CachedRepo) string {
+	parts := strings.Split(filepath.ToSlash(path), "/")
+	for n := 2; n <= len(parts); n++ {
+		suffix := strings.Join(parts[len(parts)-n:], "/")
+		unique := true
+		for _, other := range group {
+			if other.Path != path && hasPathSuffix(other.Path, suffix) {
+				unique = false
+				break
+			}
+		}
+		if unique {
+			return suffix
+		}
+	}
+	return path
+}
+
+// hasPathSuffix reports whether path ends with suffix on a component
+// boundary, so "oss/api" matches "/src/oss/api" but not "/src/boss/api".
+func hasPathSuffix(path, suffix string) bool {
+	path = filepath.ToSlash(path)
+	return path == suffix || strings.HasSuffix(path, "/"+suffix)
+}
+
+// RemoteSlug returns "owner/name" for a remote URL such as
+// git@github.com:owner/name.git, or "" if it has no owner component.
+func RemoteSlug(remoteURL string) string {
+	if u, ok := remote.Parse(remoteURL); ok {
+		return u.Slug()
+	}
+	return ""
+}
+
+// RemoteKey normalizes a remote URL with remote.URL.Key, returning "" for
+// remotes without an owner/name.
+func RemoteKey(remoteURL string) string {
+	if u, ok := remote.Parse(remoteURL); ok {
+		return u.Key()
+	}
+	return ""
+}
+
+// PathsByRemote maps the normalized remote of every cached repo to its path,
+// so a remote listing can tell which repos are already cloned. When several
+// clones share a remote, the first by path wins.
+func (c *RepoCache) PathsByRemote() map[string]string {
+	all := c.All()
+	sort.Slice(all, func(i, j int) bool { return all[i].Path < all[j].Path })
+	paths := make(map[string]string, len(all))
+	for _, r := range all {
+		if key := RemoteKey(r.Remote); key != "" {
+			if _, ok := paths[key]; !ok {
+				paths[key] = r.Path
+			}
+		}
+	}
+	return paths
+}
+
+// Resolve finds the repos a user-supplied reference could mean. query may be
+// a path (absolute, ~-relative or relative to the working directory), a
+// display name, a plain repo name, an owner/name remote slug or a trailing
+// path suffix. A path match is exact; otherwise every candidate is returned,
+// sorted by path, and the caller decides how to handle more than one.
+func (c *RepoCache) Resolve(query string) []CachedRepo {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	if abs, err := filepath.Abs(ExpandHome(query)); err == nil {
+		c.mu.Lock()
+		r, ok := c.repos[abs]
+		c.mu.Unlock()
+		if ok {
+			return []CachedRepo{r}
+		}
+	}
+
+	all := c.All()
+	names := DisplayNames(all)
+	var matches []CachedRepo
+	for _, r := range all {
+		if names[r.Path] == query || r.Name == query || RemoteSlug(r.Remote) == query ||
+			(strings.Contains(query, "/") && hasPathSuffix(r.Path, query)) {
+			matches = append(matches, r)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Path < matches[j].Path })
+	return matches
+}
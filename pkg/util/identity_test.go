@@ -0,0 +1,82 @@
+package util
+
+import "testing"
+
+func TestDisplayNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		repos []CachedRepo
+		want  map[string]string // path -> display name
+	}{
+		{
+			name: "unique names",
+			repos: []CachedRepo{
+				{Name: "api", Path: "/src/api"},
+				{Name: "web", Path: "/src/web", Remote: "git@github.com:acme/web.git"},
+			},
+			want: map[string]string{"/src/api": "api", "/src/web": "web"},
+		},
+		{
+			name: "remotes tell them apart",
+			repos: []CachedRepo{
+				{Name: "api", Path: "/work/api", Remote: "git@github.com:acme/api.git"},
+				{Name: "api", Path: "/oss/api", Remote: "https://github.com/me/api"},
+			},
+			want: map[string]string{"/work/api": "acme/api", "/oss/api": "me/api"},
+		},
+		{
+			name: "same remote falls back to paths",
+			repos: []CachedRepo{
+				{Name: "api", Path: "/src/work/api", Remote: "git@github.com:acme/api.git"},
+				{Name: "api", Path: "/src/oss/api", Remote: "https://github.com/acme/api"},
+			},
+			want: map[string]string{"/src/work/api": "work/api", "/src/oss/api": "oss/api"},
+		},
+		{
+			name: "one without a remote",
+			repos: []CachedRepo{
+				{Name: "api", Path: "/src/work/api", Remote: "git@github.com:acme/api.git"},
+				{Name: "api", Path: "/src/tmp/api"},
+			},
+			want: map[string]string{"/src/work/api": "acme/api", "/src/tmp/api": "tmp/api"},
+		},
+		{
+			name: "suffixes on component boundaries",
+			repos: []CachedRepo{
+				{Name: "api", Path: "/src/oss/api"},
+				{Name: "api", Path: "/src/boss/api"},
+			},
+			want: map[string]string{"/src/oss/api": "oss/api", "/src/boss/api": "boss/api"},
+		},
+		{
+			name: "longer suffix needed",
+			repos: []CachedRepo{
+				{Name: "api", Path: "/a/x/api"},
+				{Name: "api", Path: "/b/x/api"},
+				{Name: "api", Path: "/b/y/api"},
+			},
+			want: map[string]string{"/a/x/api": "a/x/api", "/b/x/api": "b/x/api", "/b/y/api": "y/api"},
+		},
+	}
+	for _, tt := range tests {
+		got := DisplayNames(tt.repos)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d names, want %d: %v", tt.name, len(got), len(tt.want), got)
+		}
+		for path, want := range tt.want {
+			if got[path] != want {
+				t.Errorf("%s: %s is shown as %q, want %q", tt.name, path, got[path], want)
+			}
+		}
+	}
+}
+
+func TestDisplayNameFallback(t *testing.T) {
+	names := map[string]string{"/src/api": "acme/api"}
+	if got := DisplayName(names, "/src/api"); got != "acme/api" {
+		t.Errorf("DisplayName = %q, want acme/api", got)
+	}
+	if got := DisplayName(names, "/elsewhere/web"); got != "web" {
+		t.Errorf("DisplayName for an unknown path = %q, want web", got)
+	}
+}
@@ -3,6 +3,9 @@ package util
 import (
 	"charm.land/lipgloss/v2"
 	"fmt"
+	"os"
+
+	"gee/pkg/ui"
 )
 
 var Verbose bool
@@ -42,23 +45,20 @@ func NewInfo(message string) error {
 	}
 }
 
-var (
-	styleSymbolGreen = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2"))
-	styleSymbolRed   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("1"))
-	styleSymbolYellow = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("3"))
-	styleSymbolCyan  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
-	styleMessage     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("15"))
-)
+// logSymbol renders a log line's leading symbol, bold in the style's color.
+func logSymbol(style lipgloss.Style, symbol string) string {
+	return ui.CurrentTheme().Style(style.Bold(true)).Render(symbol)
+}
 
-// Info logs an info message with a green check mark and white message
+// Info logs an info message with a check mark in the theme's success color
 func Info(format string, args ...interface{}) {
-	fmt.Printf("%s %s\n", styleSymbolGreen.Render("✓"), styleMessage.Render(fmt.Sprintf(format, args...)))
+	fmt.Printf("%s %s\n", logSymbol(ui.StyleSuccess, "✓"), ui.StyleMessage.Render(fmt.Sprintf(format, args...)))
 }
 
 // VerboseLog logs a verbose message if verbose logging is enabled
 func VerboseLog(format string, args ...interface{}) {
 	if Verbose {
-		fmt.Printf("%s %s\n", styleSymbolCyan.Render("✓"), styleMessage.Render(fmt.Sprintf(format, args...)))
+		fmt.Printf("%s %s\n", logSymbol(ui.StyleAccent, "✓"), ui.StyleMessage.Render(fmt.Sprintf(format, args...)))
 	}
 }
 
@@ -67,15 +67,21 @@ func CheckIfError(err error) {
 	if err == nil {
 		return
 	}
-	fmt.Printf("%s %s\n", styleSymbolRed.Render("✗"), styleMessage.Render(fmt.Sprintf("error: %s", err)))
+	fmt.Printf("%s %s\n", logSymbol(ui.StyleError, "✗"), ui.StyleMessage.Render(fmt.Sprintf("error: %s", err)))
 }
 
-// Warning logs a warning message with a yellow exclamation mark and white message
+// Warning logs a warning message with an exclamation mark in the warning color
 func Warning(format string, args ...interface{}) {
-	fmt.Printf("%s %s\n", styleSymbolYellow.Render("!"), styleMessage.Render(fmt.Sprintf(format, args...)))
+	fmt.Printf("%s %s\n", logSymbol(ui.StyleWarning, "!"), ui.StyleMessage.Render(fmt.Sprintf(format, args...)))
 }
 
-// WarningRed logs a warning message with a red exclamation mark and white message
+// WarningRed logs a warning message with a cross in the error color
 func WarningRed(format string, args ...interface{}) {
-	fmt.Printf("%s %s\n", styleSymbolRed.Render("✗"), styleMessage.Render(fmt.Sprintf(format, args...)))
+	fmt.Printf("%s %s\n", logSymbol(ui.StyleError, "✗"), ui.StyleMessage.Render(fmt.Sprintf(format, args...)))
+}
+
+// warnStderr prints a warning to stderr. Used where stdout is reserved for
+// machine-readable output (e.g. the teleport path printed by the TUI).
+func warnStderr(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s %s\n", logSymbol(ui.StyleWarning, "!"), ui.StyleMessage.Render(fmt.Sprintf(format, args...)))
 }
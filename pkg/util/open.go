@@ -0,0 +1,117 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"gee/pkg/gitdir"
+	"gee/pkg/remote"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// WebPage selects a page of a repo's hosting UI.
+type WebPage int
+
+const (
+	WebHome        WebPage = iota // the repo's front page
+	WebBranch                     // the current branch's tree
+	WebPullRequest                // pull/merge requests from the current branch
+	WebCI                         // CI runs for the current branch
+)
+
+// RepoWebURL returns the URL of page for the repo at repoPath. The origin
+// URL is read from the repo's config, falling back to cachedRemote if that
+// fails, since the cached value may predate a `git remote set-url`.
+func RepoWebURL(repoPath, cachedRemote string, page WebPage) (string, error) {
+	remoteURL := detectRemote(context.Background(), repoPath)
+	if remoteURL == "" {
+		remoteURL = cachedRemote
+	}
+	if remoteURL == "" {
+		return "", NewWarning(fmt.Sprintf("%s has no origin remote", repoPath))
+	}
+	u, ok := remote.Parse(remoteURL)
+	if !ok {
+		return "", NewWarning(fmt.Sprintf("can't derive a web URL from remote %q", remoteURL))
+	}
+	if page == WebHome {
+		return u.WebURL(), nil
+	}
+
+	info, _ := gitdir.Resolve(repoPath)
+	branch := gitdir.HeadBranch(info.GitDir)
+	if branch == "" || branch == "(detached)" {
+		return "", NewWarning(fmt.Sprintf("%s is not on a branch", repoPath))
+	}
+	var target string
+	var err error
+	switch page {
+	case WebBranch:
+		target = u.BranchURL(branch)
+	case WebPullRequest:
+		target, err = u.PullRequestURL(branch)
+	case WebCI:
+		target, err = u.CIURL(branch)
+	}
+	if err != nil {
+		return "", NewWarning(fmt.Sprintf("%s (%s): %s", u.Host, u.Provider, err))
+	}
+	return target, nil
+}
+
+// OpenURL opens target in the user's browser. $BROWSER wins when set.
+func OpenURL(target string) error {
+	var cmd *exec.Cmd
+	switch {
+	case os.Getenv("BROWSER") != "":
+		cmd = exec.Command(os.Getenv("BROWSER"), target)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", target)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	// Detach: we don't wait for the browser, and its output would garble
+	// the dashboard.
+	cmd.Stdout = nil
+	cmd.Stderr = nil
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// CopyToClipboard puts text on the system clipboard, reporting false when
+// there is none to use: over SSH, or without a clipboard tool. The caller
+// should then write ClipboardSequence(text) to the terminal.
+func CopyToClipboard(text string) bool {
+	if isSSH() {
+		return false
+	}
+	return clipboard.WriteAll(text) == nil
+}
+
+// ClipboardSequence returns the OSC52 escape sequence asking the terminal
+// to put text on its local clipboard. Most terminals (and tmux with
+// set-clipboard on) honor it, over SSH too.
+func ClipboardSequence(text string) string {
+	seq := osc52.New(text)
+	if os.Getenv("TMUX") != "" {
+		seq = seq.Tmux()
+	} else if strings.HasPrefix(os.Getenv("TERM"), "screen") {
+		seq = seq.Screen()
+	}
+	return seq.String()
+}
+
+func isSSH() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// Prefs holds dashboard choices made inside the TUI (sort order, grouping),
+// remembered in ~/.config/gee/tui.json between launches. They live apart
+// from config.toml, which is the user's to edit and gee never writes.
+type Prefs struct {
+	Sort      string   `json:"sort,omitempty"`      // "" keeps the cache's order: pinned first, then by name
+	Group     string   `json:"group,omitempty"`     // "" shows one ungrouped table
+	Collapsed []string `json:"collapsed,omitempty"` // folded groups, as "<group mode>:<group key>"
+}
+
+// DefaultPrefsPath returns ~/.config/gee/tui.json.
+func DefaultPrefsPath() string {
+	return filepath.Join(filepath.Dir(DefaultCachePath()), "tui.json")
+}
+
+// LoadPrefs reads the prefs file. A missing or unreadable file yields the
+// defaults: prefs are a convenience, not state worth failing over.
+func LoadPrefs() Prefs {
+	var p Prefs
+	data, err := os.ReadFile(DefaultPrefsPath())
+	if err != nil {
+		return p
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		VerboseLog("ignoring unreadable %s: %s", DefaultPrefsPath(), err)
+		return Prefs{}
+	}
+	return p
+}
+
+// Save writes the prefs file.
+func (p Prefs) Save() error {
+	path := DefaultPrefsPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, data)
+}
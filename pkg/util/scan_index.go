@@ -0,0 +1,131 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gee/pkg/gitdir"
+)
+
+// ScanIndex persists per-directory mtime fingerprints from the previous scan
+// so the next one can reuse them. A directory's mtime only changes when its
+// direct entries are added, removed or renamed, so an unchanged directory is
+// not re-read: its child list and repo detection come from the index, and
+// only a stat is spent on it. A repo's remote is re-detected only when its
+// config file's mtime moved.
+//
+// A nil *ScanIndex is valid and disables incremental scanning.
+type ScanIndex struct {
+	path   string
+	params scanIndexParams
+
+	mu   sync.Mutex
+	prev map[string]dirFingerprint // from the last completed scan (read-only)
+	next map[string]dirFingerprint // built by the scan in progress
+}
+
+// scanIndexParams are the scanner settings an index was built with. If any
+// of them change, the old fingerprints no longer describe the same walk.
+type scanIndexParams struct {
+	Root     string `json:"root"`
+	MaxDepth int    `json:"max_depth"`
+	Nested   bool   `json:"nested"`
+}
+
+type scanIndexFile struct {
+	scanIndexParams
+	Dirs map[string]dirFingerprint `json:"dirs"`
+}
+
+type dirFingerprint struct {
+	ModTime  time.Time        `json:"mtime"`
+	Children []string         `json:"children,omitempty"` // subdirectories worth descending into
+	Repo     *repoFingerprint `json:"repo,omitempty"`
+}
+
+type repoFingerprint struct {
+	Kind          gitdir.Kind `json:"kind"`
+	Remote        string      `json:"remote"`
+	ConfigPath    string      `json:"config_path"`
+	ConfigModTime time.Time   `json:"config_mtime"`
+}
+
+// DefaultScanIndexPath returns ~/.config/gee/scan_index.json, next to the cache.
+func DefaultScanIndexPath() string {
+	return filepath.Join(filepath.Dir(DefaultCachePath()), "scan_index.json")
+}
+
+// LoadScanIndex reads the index at path. An unreadable index, or one built
+// with different scanner settings, is treated as empty so the next scan is a
+// full walk.
+func LoadScanIndex(path string, cfg ScannerConfig) *ScanIndex {
+	idx := &ScanIndex{
+		path: path,
+		params: scanIndexParams{
+			Root:     cfg.Root,
+			MaxDepth: cfg.MaxDepth,
+			Nested:   cfg.Nested,
+		},
+		prev: make(map[string]dirFingerprint),
+		next: make(map[string]dirFingerprint),
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return idx
+	}
+	var file scanIndexFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		VerboseLog("ignoring unreadable scan index: %s", err)
+		return idx
+	}
+	if file.scanIndexParams != idx.params {
+		return idx
+	}
+	if file.Dirs != nil {
+		idx.prev = file.Dirs
+	}
+	return idx
+}
+
+// lookup returns the previous fingerprint for dir.
+func (idx *ScanIndex) lookup(dir string) (dirFingerprint, bool) {
+	if idx == nil {
+		return dirFingerprint{}, false
+	}
+	fp, ok := idx.prev[dir]
+	return fp, ok
+}
+
+// record stores dir's fingerprint for the scan in progress.
+func (idx *ScanIndex) record(dir string, fp dirFingerprint) {
+	if idx == nil {
+		return
+	}
+	idx.mu.Lock()
+	idx.next[dir] = fp
+	idx.mu.Unlock()
+}
+
+// Save writes the fingerprints gathered by the completed scan atomically.
+// Directories that were not visited (deleted, or now filtered out) drop out.
+func (idx *ScanIndex) Save() error {
+	if idx == nil {
+		return nil
+	}
+	idx.mu.Lock()
+	file := scanIndexFile{scanIndexParams: idx.params, Dirs: idx.next}
+	data, err := json.Marshal(file)
+	idx.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return err
+	}
+	return WriteFileAtomic(idx.path, data)
+}
@@ -9,20 +9,27 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"gee/pkg/gitdir"
+
 	"github.com/stcrestrada/gogo/v3"
 )
 
 // ScanResult represents a single discovered git repo.
 type ScanResult struct {
-	Name   string // directory name (basename)
-	Path   string // absolute path to the repo root
-	Remote string // origin URL if detectable, else ""
+	Name   string      // directory name (basename)
+	Path   string      // absolute path to the repo root
+	Remote string      // origin URL if detectable, else ""
+	Kind   gitdir.Kind // normal, worktree, submodule or bare
 }
 
 // ScannerConfig controls the filesystem scan.
 type ScannerConfig struct {
 	Root     string // starting directory (default: ~)
 	MaxDepth int    // maximum recursion depth (default: 5)
+	Nested   bool   // keep descending inside repos to find nested ones
+	// Index, if set, makes the scan incremental: fingerprints from the
+	// previous scan are reused and the new ones saved when the walk completes.
+	Index *ScanIndex
 }
 
 // skipDirs are directory basenames that are never git repos and are expensive to walk.
@@ -62,6 +69,7 @@ func ScanForRepos(ctx context.Context, cfg ScannerConfig) <-chan ScanResult {
 	if cfg.MaxDepth <= 0 {
 		cfg.MaxDepth = 5
 	}
+	cfg.Root = ExpandHome(cfg.Root)
 
 	outCh := make(chan ScanResult, 32)
 
@@ -89,7 +97,7 @@ func ScanForRepos(ctx context.Context, cfg ScannerConfig) <-chan ScanResult {
 				outstanding.Add(-1)
 				tryClose()
 			}()
-			return scanDirectory(ctx, task, pool, &outstanding, &closeOnce, cfg.MaxDepth)
+			return scanDirectory(ctx, task, pool, &outstanding, &closeOnce, cfg)
 		})
 		if err != nil {
 			// Pool already closed (e.g. context cancelled)
@@ -107,21 +115,34 @@ func ScanForRepos(ctx context.Context, cfg ScannerConfig) <-chan ScanResult {
 				outCh <- result.Result
 			}
 		}
+		// Only a complete walk describes the tree; a cancelled one would
+		// drop the subtrees it never reached.
+		if ctx.Err() == nil {
+			if err := cfg.Index.Save(); err != nil {
+				VerboseLog("save scan index: %s", err)
+			}
+		}
 		close(outCh)
 	}()
 
 	return outCh
 }
 
-// scanDirectory processes a single directory. If it contains .git, it's a repo.
-// Otherwise, it submits child directories for scanning.
+// scanDirectory processes a single directory. If it is a repo (a .git
+// directory, a .git gitdir file, or a bare repo) it is returned as a result.
+// Child directories are submitted for scanning unless this is a repo and
+// cfg.Nested is off; bare repos are never descended into.
+//
+// When cfg.Index holds a fingerprint whose mtime matches the directory's,
+// the directory is not re-read: repo detection and the child list are taken
+// from the index.
 func scanDirectory(
 	ctx context.Context,
 	task scanTask,
 	pool *gogo.StreamPool[ScanResult],
 	outstanding *atomic.Int64,
 	closeOnce *sync.Once,
-	maxDepth int,
+	cfg ScannerConfig,
 ) (ScanResult, error) {
 	select {
 	case <-ctx.Done():
@@ -129,27 +150,45 @@ func scanDirectory(
 	default:
 	}
 
+	info, err := os.Stat(task.dir)
+	if err != nil {
+		return ScanResult{}, nil
+	}
+	prev, unchanged := cfg.Index.lookup(task.dir)
+	unchanged = unchanged && prev.ModTime.Equal(info.ModTime())
+
+	fp := dirFingerprint{ModTime: info.ModTime()}
+	defer func() { cfg.Index.record(task.dir, fp) }()
+
 	// Check if this directory is a git repo.
-	gitDir := filepath.Join(task.dir, ".git")
-	if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
-		// Found a repo — detect remote and return. Don't recurse deeper.
-		remote := detectRemote(ctx, task.dir)
-		return ScanResult{
+	if unchanged {
+		fp.Repo = refreshRepoFingerprint(ctx, task.dir, prev.Repo)
+	} else {
+		fp.Repo = detectRepo(ctx, task.dir)
+	}
+
+	var found ScanResult
+	if fp.Repo != nil {
+		found = ScanResult{
 			Name:   filepath.Base(task.dir),
 			Path:   task.dir,
-			Remote: remote,
-		}, nil
+			Remote: fp.Repo.Remote,
+			Kind:   fp.Repo.Kind,
+		}
+		if !cfg.Nested || fp.Repo.Kind == gitdir.KindBare {
+			return found, nil
+		}
 	}
 
-	// Not a repo — scan children if we haven't hit max depth.
-	if task.depth >= maxDepth {
-		return ScanResult{}, nil
+	// Scan children if we haven't hit max depth.
+	if task.depth >= cfg.MaxDepth {
+		return found, nil
 	}
 
-	entries, err := os.ReadDir(task.dir)
-	if err != nil {
-		// Permission denied, etc. — skip silently.
-		return ScanResult{}, nil
+	if unchanged {
+		fp.Children = prev.Children
+	} else {
+		fp.Children = listChildDirs(task.dir)
 	}
 
 	tryClose := func() {
@@ -160,6 +199,34 @@ func scanDirectory(
 		}
 	}
 
+	for _, name := range fp.Children {
+		childPath := filepath.Join(task.dir, name)
+
+		outstanding.Add(1)
+		submitErr := pool.Submit(func(ctx context.Context) (ScanResult, error) {
+			defer func() {
+				outstanding.Add(-1)
+				tryClose()
+			}()
+			return scanDirectory(ctx, scanTask{dir: childPath, depth: task.depth + 1}, pool, outstanding, closeOnce, cfg)
+		})
+		if submitErr != nil {
+			outstanding.Add(-1)
+		}
+	}
+
+	return found, nil
+}
+
+// listChildDirs returns the names of subdirectories worth descending into.
+func listChildDirs(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		// Permission denied, etc. — skip silently.
+		return nil
+	}
+
+	var children []string
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			continue
@@ -181,22 +248,48 @@ func scanDirectory(
 			continue
 		}
 
-		childPath := filepath.Join(task.dir, name)
+		children = append(children, name)
+	}
+	return children
+}
 
-		outstanding.Add(1)
-		submitErr := pool.Submit(func(ctx context.Context) (ScanResult, error) {
-			defer func() {
-				outstanding.Add(-1)
-				tryClose()
-			}()
-			return scanDirectory(ctx, scanTask{dir: childPath, depth: task.depth + 1}, pool, outstanding, closeOnce, maxDepth)
-		})
-		if submitErr != nil {
-			outstanding.Add(-1)
-		}
+// detectRepo resolves dir as a repo and fingerprints its config file.
+// Returns nil if dir is not a repo.
+func detectRepo(ctx context.Context, dir string) *repoFingerprint {
+	info, ok := gitdir.Resolve(dir)
+	if !ok {
+		return nil
 	}
+	repo := &repoFingerprint{
+		Kind:       info.Kind,
+		Remote:     detectRemote(ctx, dir),
+		ConfigPath: filepath.Join(info.CommonDir, "config"),
+	}
+	if st, err := os.Stat(repo.ConfigPath); err == nil {
+		repo.ConfigModTime = st.ModTime()
+	}
+	return repo
+}
 
-	return ScanResult{}, nil
+// refreshRepoFingerprint reuses a previous repo fingerprint for a directory
+// whose mtime is unchanged, re-running detectRemote only if the config file
+// changed since the last scan.
+func refreshRepoFingerprint(ctx context.Context, dir string, prev *repoFingerprint) *repoFingerprint {
+	if prev == nil {
+		return nil
+	}
+	st, err := os.Stat(prev.ConfigPath)
+	if err != nil {
+		// Config gone (e.g. the main repo of a worktree was deleted).
+		return detectRepo(ctx, dir)
+	}
+	if st.ModTime().Equal(prev.ConfigModTime) {
+		return prev
+	}
+	repo := *prev
+	repo.Remote = detectRemote(ctx, dir)
+	repo.ConfigModTime = st.ModTime()
+	return &repo
 }
 
 // detectRemote runs `git config --get remote.origin.url` to get the remote.
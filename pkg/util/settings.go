@@ -0,0 +1,224 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gee/pkg/remote"
+	"gee/pkg/ui"
+
+	"github.com/pelletier/go-toml"
+)
+
+// Settings holds user preferences read from ~/.config/gee/config.toml.
+// Every field is optional; a missing file yields the defaults.
+type Settings struct {
+	Scan      ScanSettings           `toml:"scan"`
+	Discovery DiscoverySettings      `toml:"discovery"`
+	Clone     CloneOptions           `toml:"clone"`
+	Keys      map[string]interface{} `toml:"keys"` // command name -> key or list of keys, see KeyBindings
+	Theme     ui.ThemeSettings       `toml:"theme"`
+	Tools     map[string]string      `toml:"tools"` // tool name -> command line, see ExternalTools
+	Mouse     MouseSettings          `toml:"mouse"`
+}
+
+// ScanSettings controls the background filesystem scanner.
+type ScanSettings struct {
+	Root     string `toml:"root"`      // default: home directory
+	MaxDepth int    `toml:"max_depth"` // default: 5
+	Nested   bool   `toml:"nested"`    // keep descending inside repos to find nested ones
+}
+
+// MouseSettings controls mouse support in the TUI.
+type MouseSettings struct {
+	Enabled bool `toml:"enabled"` // default: true; false leaves the mouse to the terminal, e.g. for selecting text
+}
+
+// DiscoverySettings controls remote discovery (the dashboard's d view).
+type DiscoverySettings struct {
+	GitHubHosts []string `toml:"github_hosts"` // default: ["github.com"]; replaces it, so list github.com too to keep it with GitHub Enterprise hosts
+	GitLabHosts []string `toml:"gitlab_hosts"` // default: ["gitlab.com"]; replaces it, so list gitlab.com too to keep it with self-hosted hosts
+	Protocol    string   `toml:"protocol"`     // "ssh" (default) or "https" clone URLs; Gitea and Bitbucket tables may override it
+	CacheTTL    string   `toml:"cache_ttl"`    // how long listings are reused, e.g. "15m"; "0" disables
+
+	Gitea     []GiteaSettings     `toml:"gitea"`     // one [[discovery.gitea]] table per Gitea/Forgejo instance
+	Bitbucket []BitbucketSettings `toml:"bitbucket"` // a [[discovery.bitbucket]] table enables Bitbucket Cloud
+}
+
+// GiteaSettings configures one Gitea or Forgejo instance.
+type GiteaSettings struct {
+	Host     string `toml:"host"`     // e.g. "codeberg.org"
+	URL      string `toml:"url"`      // web root, default "https://<host>"
+	Token    string `toml:"token"`    // with a single instance, default: $GITEA_TOKEN, then $FORGEJO_TOKEN
+	Protocol string `toml:"protocol"` // "ssh" or "https"; default: discovery.protocol
+}
+
+// BitbucketSettings configures Bitbucket Cloud. Without a table, Bitbucket
+// is still enabled when its environment variables are set.
+type BitbucketSettings struct {
+	Username string `toml:"username"` // default: $BITBUCKET_USERNAME
+	Token    string `toml:"token"`    // app password (with username) or access token; default: $BITBUCKET_APP_PASSWORD (with username), else $BITBUCKET_TOKEN
+	URL      string `toml:"url"`      // API root, default "https://api.bitbucket.org/2.0"
+	Protocol string `toml:"protocol"` // "ssh" or "https"; default: discovery.protocol
+}
+
+// defaultDiscoveryTTL is used when cache_ttl is unset or invalid.
+const defaultDiscoveryTTL = 15 * time.Minute
+
+// TTL parses CacheTTL.
+func (d DiscoverySettings) TTL() time.Duration {
+	if d.CacheTTL == "" {
+		return defaultDiscoveryTTL
+	}
+	ttl, err := time.ParseDuration(d.CacheTTL)
+	if err != nil {
+		VerboseLog("invalid discovery.cache_ttl %q: %s", d.CacheTTL, err)
+		return defaultDiscoveryTTL
+	}
+	return ttl
+}
+
+// DefaultSettingsPath returns ~/.config/gee/config.toml.
+func DefaultSettingsPath() string {
+	return filepath.Join(filepath.Dir(DefaultCachePath()), "config.toml")
+}
+
+// LoadSettings reads the settings file, returning defaults if it doesn't exist.
+func LoadSettings() (Settings, error) {
+	s := Settings{
+		Scan:  ScanSettings{MaxDepth: 5},
+		Mouse: MouseSettings{Enabled: true},
+		Discovery: DiscoverySettings{
+			GitHubHosts: []string{"github.com"},
+			GitLabHosts: []string{"gitlab.com"},
+		},
+	}
+
+	path := DefaultSettingsPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return s, err
+	}
+	if err := tree.Unmarshal(&s); err != nil {
+		return s, err
+	}
+	VerboseLog("loaded settings from %s", path)
+
+	// Let remote URL parsing recognize self-hosted instances by host name.
+	for _, host := range s.Discovery.GitHubHosts {
+		remote.SetHostProvider(host, remote.ProviderGitHub)
+	}
+	for _, host := range s.Discovery.GitLabHosts {
+		remote.SetHostProvider(host, remote.ProviderGitLab)
+	}
+	for _, g := range s.Discovery.Gitea {
+		if g.Host != "" {
+			remote.SetHostProvider(g.Host, remote.ProviderGitea)
+		}
+	}
+	return s, nil
+}
+
+// KeyBindings returns the [keys] overrides as command name -> keys. A
+// value may be one key ("p") or a list (["p", "ctrl+l"]); an empty list
+// unbinds the command. Values of any other type are reported as invalid.
+func (s Settings) KeyBindings() (bindings map[string][]string, invalid []string) {
+	bindings = make(map[string][]string, len(s.Keys))
+	for name, v := range s.Keys {
+		switch v := v.(type) {
+		case string:
+			bindings[name] = []string{v}
+		case []interface{}:
+			keys := []string{}
+			for _, k := range v {
+				key, ok := k.(string)
+				if !ok {
+					invalid = append(invalid, name)
+					keys = nil
+					break
+				}
+				keys = append(keys, key)
+			}
+			if keys != nil {
+				bindings[name] = keys
+			}
+		default:
+			invalid = append(invalid, name)
+		}
+	}
+	sort.Strings(invalid)
+	return bindings, invalid
+}
+
+// Tool is an external program the dashboard opens a repo in, suspending
+// itself until the program exits.
+type Tool struct {
+	Name    string // "editor", "lazygit"...
+	Command string // run with sh -c in the repo's directory
+}
+
+// builtinTools are the tools every dashboard has, as long as their
+// program is installed.
+var builtinTools = []Tool{
+	{Name: "editor", Command: `${VISUAL:-${EDITOR:-vi}} .`},
+	{Name: "shell", Command: `exec "${SHELL:-sh}"`},
+	{Name: "lazygit", Command: "lazygit"},
+	{Name: "tig", Command: "tig"},
+}
+
+// ExternalTools returns the built-in tools in their fixed order (editor,
+// shell, lazygit, tig), then the other [tools] entries sorted by name. An
+// entry named like a built-in replaces its command; one with an empty
+// command removes the tool.
+func (s Settings) ExternalTools() []Tool {
+	var tools []Tool
+	builtin := make(map[string]bool, len(builtinTools))
+	for _, t := range builtinTools {
+		builtin[t.Name] = true
+		if command, ok := s.Tools[t.Name]; ok {
+			t.Command = command
+		}
+		if strings.TrimSpace(t.Command) != "" {
+			tools = append(tools, t)
+		}
+	}
+	names := make([]string, 0, len(s.Tools))
+	for name := range s.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !builtin[name] && strings.TrimSpace(s.Tools[name]) != "" {
+			tools = append(tools, Tool{Name: name, Command: s.Tools[name]})
+		}
+	}
+	return tools
+}
+
+// ScannerConfig converts the scan settings into a ScannerConfig.
+func (s Settings) ScannerConfig() ScannerConfig {
+	return ScannerConfig{
+		Root:     s.Scan.Root,
+		MaxDepth: s.Scan.MaxDepth,
+		Nested:   s.Scan.Nested,
+	}
+}
+
+// ExpandHome replaces a leading "~" with the user's home directory.
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
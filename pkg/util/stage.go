@@ -0,0 +1,63 @@
+package util
+
+import (
+	"context"
+	"strings"
+)
+
+// StagePaths stages the given paths, including deletions and new files, or
+// every change in the repo when paths is empty.
+func StagePaths(ctx context.Context, repoPath string, paths ...string) error {
+	if len(paths) == 0 {
+		_, err := runGit(ctx, repoPath, nil, "add", "-A")
+		return err
+	}
+	_, err := runGit(ctx, repoPath, nil, append([]string{"add", "-A", "--"}, paths...)...)
+	return err
+}
+
+// UnstagePaths moves the given paths, or everything when paths is empty,
+// out of the index, leaving the working tree alone. Before the first commit
+// there is no HEAD to reset to, so the paths are dropped from the index.
+func UnstagePaths(ctx context.Context, repoPath string, paths ...string) error {
+	if _, err := runGit(ctx, repoPath, nil, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		if len(paths) == 0 {
+			paths = []string{"."}
+		}
+		_, err := runGit(ctx, repoPath, nil, append([]string{"rm", "--cached", "-r", "-q", "--"}, paths...)...)
+		return err
+	}
+	_, err := runGit(ctx, repoPath, nil, append([]string{"reset", "-q", "--"}, paths...)...)
+	return err
+}
+
+// Commit commits the index with message and returns git's one-line summary,
+// e.g. "[main 1a2b3c4] Fix typo". With amend it replaces the last commit;
+// an empty message then keeps the old one.
+func Commit(ctx context.Context, repoPath, message string, amend bool) (string, error) {
+	args := []string{"commit"}
+	if amend {
+		args = append(args, "--amend")
+		if strings.TrimSpace(message) == "" {
+			args = append(args, "--no-edit")
+		}
+	}
+	if strings.TrimSpace(message) != "" {
+		args = append(args, "-m", message)
+	}
+	out, err := runGit(ctx, repoPath, nil, args...)
+	if err != nil {
+		return "", err
+	}
+	summary, _, _ := strings.Cut(strings.TrimSpace(out), "\n")
+	return summary, nil
+}
+
+// LastCommitMessage returns the full message of HEAD, for amending.
+func LastCommitMessage(ctx context.Context, repoPath string) (string, error) {
+	out, err := gitOutput(ctx, repoPath, "log", "-1", "--format=%B")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
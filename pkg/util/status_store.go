@@ -0,0 +1,159 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gee/pkg/gitdir"
+	"gee/pkg/ui"
+)
+
+// StatusSnapshot is the last known status of one repo.
+type StatusSnapshot struct {
+	Summary       ui.StatusSummary `json:"summary"`
+	CheckedAt     time.Time        `json:"checked_at"`
+	LastCommit    time.Time        `json:"last_commit,omitempty"`
+	DefaultBranch string           `json:"default_branch,omitempty"`
+}
+
+// StatusStore persists StatusSnapshots in ~/.config/gee/status.json so the
+// dashboard can render real data before `git status` returns and
+// `gee status --cached` can answer without spawning git.
+//
+// Snapshots live beside cache.json rather than in it: they change on every
+// refresh, and writing them into the cache would rotate its backups and wake
+// every other gee process watching it.
+//
+// Safe for concurrent use.
+type StatusStore struct {
+	path string
+
+	mu        sync.Mutex
+	snapshots map[string]StatusSnapshot // keyed by repo path
+	dirty     map[string]bool
+}
+
+// DefaultStatusStorePath returns ~/.config/gee/status.json.
+func DefaultStatusStorePath() string {
+	return filepath.Join(filepath.Dir(DefaultCachePath()), "status.json")
+}
+
+// LoadStatusStore reads the store at the default path. A missing or
+// unreadable file yields an empty store: snapshots are only a cache.
+func LoadStatusStore() *StatusStore {
+	s := &StatusStore{
+		path:      DefaultStatusStorePath(),
+		snapshots: make(map[string]StatusSnapshot),
+		dirty:     make(map[string]bool),
+	}
+	if snapshots, err := readStatusFile(s.path); err == nil {
+		s.snapshots = snapshots
+	}
+	return s
+}
+
+func readStatusFile(path string) (map[string]StatusSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make(map[string]StatusSnapshot)
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// Get returns the snapshot for the repo at path.
+func (s *StatusStore) Get(path string) (StatusSnapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap, ok := s.snapshots[path]
+	return snap, ok
+}
+
+// Set records a fresh snapshot for the repo at path.
+func (s *StatusStore) Set(path string, snap StatusSnapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.snapshots[path] = snap
+	s.dirty[path] = true
+}
+
+// Dirty reports whether there are snapshots not yet saved.
+func (s *StatusStore) Dirty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.dirty) > 0
+}
+
+// Save writes the store. Snapshots written by another process since we
+// loaded are kept unless ours for the same repo is newer.
+func (s *StatusStore) Save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if onDisk, err := readStatusFile(s.path); err == nil {
+		for path, theirs := range onDisk {
+			ours, ok := s.snapshots[path]
+			if !ok || (!s.dirty[path] && theirs.CheckedAt.After(ours.CheckedAt)) {
+				s.snapshots[path] = theirs
+			}
+		}
+	}
+
+	data, err := json.Marshal(s.snapshots)
+	if err != nil {
+		return err
+	}
+	if err := WriteFileAtomic(s.path, data); err != nil {
+		return err
+	}
+	s.dirty = make(map[string]bool)
+	return nil
+}
+
+// Snapshot builds a snapshot for a freshly parsed summary, filling in the
+// last commit time and the default branch. `git log` only runs when HEAD
+// moved since the previous snapshot.
+func (s *StatusStore) Snapshot(repoPath string, summary ui.StatusSummary) StatusSnapshot {
+	snap := StatusSnapshot{Summary: summary, CheckedAt: time.Now()}
+	if info, ok := gitdir.Resolve(repoPath); ok {
+		snap.DefaultBranch = gitdir.DefaultBranch(info.CommonDir)
+	}
+
+	if prev, ok := s.Get(repoPath); ok && prev.Summary.Oid != "" && prev.Summary.Oid == summary.Oid {
+		snap.LastCommit = prev.LastCommit
+	} else if summary.Oid != "(initial)" {
+		snap.LastCommit = lastCommitTime(repoPath)
+	}
+	return snap
+}
+
+// lastCommitTime returns HEAD's committer date, or the zero time.
+func lastCommitTime(repoPath string) time.Time {
+	out, err := exec.Command("git", "-C", repoPath, "log", "-1", "--format=%ct", "HEAD").Output()
+	if err != nil {
+		return time.Time{}
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
@@ -0,0 +1,275 @@
+package util
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gee/pkg/gitdir"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchEvent is one debounced batch of filesystem changes.
+type WatchEvent struct {
+	Repos        []string // repo paths whose git state or working-tree root changed
+	CacheChanged bool     // cache.json was rewritten (possibly by another gee process)
+}
+
+// RepoWatcher watches repos for changes that affect `git status` and turns
+// raw fsnotify events into debounced per-repo notifications.
+//
+// For each repo it watches the gitdir (HEAD, index, FETCH_HEAD, MERGE_HEAD,
+// ...), the common dir (packed-refs), every directory under refs/heads and
+// refs/remotes, including ones created later (refs/heads/feat/ for a new
+// feat/x branch), and the working-tree root. Watches are not recursive, so
+// edits deep inside the working tree are only noticed when git itself
+// touches the index; callers should keep a slow fallback refresh.
+//
+// It also watches the directory holding cache.json, since Save replaces the
+// file by rename and a watch on the file itself would be lost.
+type RepoWatcher struct {
+	fsw       *fsnotify.Watcher
+	cachePath string
+	debounce  time.Duration
+	events    chan WatchEvent
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mu      sync.Mutex
+	dirs    map[string]map[string]bool // watched dir -> repo paths it belongs to
+	repos   map[string][]string        // repo path -> watched dirs
+	refDirs map[string]bool            // watched dirs under refs/heads and refs/remotes
+}
+
+// NewRepoWatcher starts a watcher that reports changes to cachePath and to
+// any repo passed to Add or Sync, coalescing events within debounce. The
+// caller must Close it.
+func NewRepoWatcher(cachePath string, debounce time.Duration) (*RepoWatcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(filepath.Dir(cachePath)); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+
+	w := &RepoWatcher{
+		fsw:       fsw,
+		cachePath: cachePath,
+		debounce:  debounce,
+		events:    make(chan WatchEvent, 8),
+		done:      make(chan struct{}),
+		dirs:      make(map[string]map[string]bool),
+		repos:     make(map[string][]string),
+		refDirs:   make(map[string]bool),
+	}
+	go w.loop()
+	return w, nil
+}
+
+// Events returns the channel of debounced change batches.
+func (w *RepoWatcher) Events() <-chan WatchEvent {
+	return w.events
+}
+
+// Add starts watching the repo at repoPath. Directories that can't be
+// watched (permissions, inotify limits) are skipped silently.
+func (w *RepoWatcher) Add(repoPath string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.addLocked(repoPath)
+}
+
+// Sync makes the watched set exactly repoPaths: new repos are added and
+// repos no longer present are unwatched.
+func (w *RepoWatcher) Sync(repoPaths []string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	keep := make(map[string]bool, len(repoPaths))
+	for _, p := range repoPaths {
+		keep[p] = true
+		w.addLocked(p)
+	}
+	for p := range w.repos {
+		if !keep[p] {
+			w.removeLocked(p)
+		}
+	}
+}
+
+// Close stops the watcher and releases its inotify/kqueue handles.
+func (w *RepoWatcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.fsw.Close()
+	})
+	return err
+}
+
+func (w *RepoWatcher) addLocked(repoPath string) {
+	if _, ok := w.repos[repoPath]; ok {
+		return
+	}
+	info, ok := gitdir.Resolve(repoPath)
+	if !ok {
+		return
+	}
+
+	w.repos[repoPath] = nil
+	w.watchLocked(info.GitDir, repoPath)
+	w.watchLocked(info.CommonDir, repoPath)
+	if info.Kind != gitdir.KindBare {
+		w.watchLocked(repoPath, repoPath)
+	}
+	for _, refs := range []string{"refs/heads", "refs/remotes"} {
+		w.watchRefsLocked(filepath.Join(info.CommonDir, refs), []string{repoPath})
+	}
+}
+
+// watchRefsLocked watches root and every directory below it for repos.
+func (w *RepoWatcher) watchRefsLocked(root string, repos []string) {
+	filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			for _, repo := range repos {
+				if w.watchLocked(p, repo) {
+					w.refDirs[p] = true
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// watchLocked adds dir to the watches of the repo at repoPath, reporting
+// whether it is watched.
+func (w *RepoWatcher) watchLocked(dir, repoPath string) bool {
+	if w.dirs[dir] == nil {
+		if err := w.fsw.Add(dir); err != nil {
+			return false
+		}
+		w.dirs[dir] = make(map[string]bool)
+	}
+	if !w.dirs[dir][repoPath] {
+		w.dirs[dir][repoPath] = true
+		w.repos[repoPath] = append(w.repos[repoPath], dir)
+	}
+	return true
+}
+
+// addRefDir watches a directory created under a watched refs directory,
+// such as refs/heads/feat/ when branch feat/x is created.
+func (w *RepoWatcher) addRefDir(dir string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	parent := filepath.Dir(dir)
+	if !w.refDirs[parent] {
+		return
+	}
+	var repos []string
+	for repo := range w.dirs[parent] {
+		repos = append(repos, repo)
+	}
+	w.watchRefsLocked(dir, repos)
+}
+
+func (w *RepoWatcher) removeLocked(repoPath string) {
+	for _, dir := range w.repos[repoPath] {
+		delete(w.dirs[dir], repoPath)
+		if len(w.dirs[dir]) == 0 {
+			delete(w.dirs, dir)
+			delete(w.refDirs, dir)
+			w.fsw.Remove(dir)
+		}
+	}
+	delete(w.repos, repoPath)
+}
+
+// reposFor maps a changed file to the repos whose watched dir contains it.
+func (w *RepoWatcher) reposFor(name string) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var repos []string
+	for repo := range w.dirs[filepath.Dir(name)] {
+		repos = append(repos, repo)
+	}
+	// An event on a watched dir itself (e.g. a new branch namespace under
+	// refs/heads) belongs to that dir's repos too.
+	for repo := range w.dirs[name] {
+		repos = append(repos, repo)
+	}
+	return repos
+}
+
+// loop collects raw events and flushes each repo once it has been quiet for
+// the debounce interval, so a burst (checkout, rebase, build output) yields
+// a single refresh.
+func (w *RepoWatcher) loop() {
+	ticker := time.NewTicker(w.debounce / 2)
+	defer ticker.Stop()
+
+	pending := make(map[string]time.Time)
+	var cacheTouched time.Time
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			now := time.Now()
+			if ev.Name == w.cachePath {
+				cacheTouched = now
+				continue
+			}
+			if ev.Has(fsnotify.Create) {
+				if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+					w.addRefDir(ev.Name)
+				}
+			}
+			for _, repo := range w.reposFor(ev.Name) {
+				pending[repo] = now
+			}
+
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+			VerboseLog("watcher: %s", err)
+
+		case now := <-ticker.C:
+			var batch WatchEvent
+			for repo, last := range pending {
+				if now.Sub(last) >= w.debounce {
+					batch.Repos = append(batch.Repos, repo)
+					delete(pending, repo)
+				}
+			}
+			if !cacheTouched.IsZero() && now.Sub(cacheTouched) >= w.debounce {
+				batch.CacheChanged = true
+				cacheTouched = time.Time{}
+			}
+			if len(batch.Repos) == 0 && !batch.CacheChanged {
+				continue
+			}
+			select {
+			case w.events <- batch:
+			case <-w.done:
+				return
+			}
+		}
+	}
+}